@@ -0,0 +1,203 @@
+package deepclone
+
+import (
+	"cmp"
+	"reflect"
+	"slices"
+)
+
+// backingKey identifies a slice by its element type and the address of its
+// first element.
+type backingKey struct {
+	elem  reflect.Type
+	start uintptr
+}
+
+// backingArray is a run of overlapping slices found in the source graph.
+// Distinct allocations never overlap, so every slice in the run views the
+// same backing array.
+type backingArray struct {
+	// start is the address of the lowest element any slice in the run views.
+	start uintptr
+	len   int
+	// views holds the slices in the run, lowest start first.
+	views  []reflect.Value
+	cloned reflect.Value
+}
+
+type backingScanKey struct {
+	visitKey
+	len int
+}
+
+// backingArrays maps the start of every slice reachable from a source graph
+// to the run of overlapping slices it belongs to.
+type backingArrays map[backingKey]*backingArray
+
+// scanBackingArrays walks v the way cloning does and groups the slices it
+// reaches into runs that share a backing array, so overlapping sub-slices can
+// later be cloned into one shared array.
+func (c *cloneContext) scanBackingArrays(v reflect.Value) {
+	views := make(map[reflect.Type][]reflect.Value)
+	c.scanBacking(v, make(map[backingScanKey]struct{}), views)
+
+	c.backing = make(backingArrays)
+	for elem, elemViews := range views {
+		c.backing.group(elem, elemViews)
+	}
+}
+
+func (c *cloneContext) scanBacking(v reflect.Value, seen map[backingScanKey]struct{}, views map[reflect.Type][]reflect.Value) {
+	if !v.IsValid() || isPlainType(v.Type()) || c.sharesType(v.Type()) || c.hasCustomClone(v.Type()) {
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return
+		}
+		key := backingScanKey{visitKey: visitKey{kind: visitPointer, addr: v.Pointer(), typ: v.Type()}}
+		if _, ok := seen[key]; ok {
+			return
+		}
+		seen[key] = struct{}{}
+		c.scanBacking(v.Elem(), seen, views)
+	case reflect.Interface:
+		c.scanBacking(v.Elem(), seen, views)
+	case reflect.Struct:
+		for _, field := range structInfo(v.Type()).fields {
+			if field.action == cloneField {
+				c.scanBacking(v.Field(field.index), seen, views)
+			}
+		}
+	case reflect.Array:
+		for i := range v.Len() {
+			c.scanBacking(v.Index(i), seen, views)
+		}
+	case reflect.Map:
+		if v.IsNil() {
+			return
+		}
+		key := backingScanKey{visitKey: visitKey{kind: visitMap, addr: v.Pointer(), typ: v.Type()}}
+		if _, ok := seen[key]; ok {
+			return
+		}
+		seen[key] = struct{}{}
+		iter := v.MapRange()
+		for iter.Next() {
+			c.scanBacking(iter.Key(), seen, views)
+			c.scanBacking(iter.Value(), seen, views)
+		}
+	case reflect.Slice:
+		if v.IsNil() {
+			return
+		}
+		key := backingScanKey{visitKey: visitKey{kind: visitSlice, addr: v.Pointer(), typ: v.Type()}, len: v.Len()}
+		if _, ok := seen[key]; ok {
+			return
+		}
+		seen[key] = struct{}{}
+		if elem := v.Type().Elem(); v.Cap() > 0 && elem.Size() > 0 {
+			views[elem] = append(views[elem], v)
+		}
+		for i := range v.Len() {
+			c.scanBacking(v.Index(i), seen, views)
+		}
+	default:
+	}
+}
+
+// group merges views of elem whose memory overlaps into backing arrays.
+func (a backingArrays) group(elem reflect.Type, views []reflect.Value) {
+	size := elem.Size()
+	slices.SortFunc(views, func(x, y reflect.Value) int {
+		return cmp.Compare(x.Pointer(), y.Pointer())
+	})
+
+	var array *backingArray
+	var end uintptr
+	for _, view := range views {
+		start := view.Pointer()
+		if array == nil || start >= end {
+			array = &backingArray{start: start}
+			end = start
+		}
+		end = max(end, start+uintptr(view.Cap())*size)
+		array.len = int((end - array.start) / size)
+		array.views = append(array.views, view)
+		a[backingKey{elem: elem, start: start}] = array
+	}
+}
+
+// cloneSliceWindow clones v as a window into the clone of its backing array,
+// cloning the array the first time any slice into it is reached. It reports
+// false when v was not recorded by scanBackingArrays.
+func (c *cloneContext) cloneSliceWindow(v reflect.Value, path string) (reflect.Value, bool, error) {
+	elem := v.Type().Elem()
+	array, ok := c.backing[backingKey{elem: elem, start: v.Pointer()}]
+	if !ok || v.Cap() == 0 {
+		return reflect.Value{}, false, nil
+	}
+	offset := int((v.Pointer() - array.start) / elem.Size())
+	if offset+v.Cap() > array.len {
+		// v reaches past every slice the scan found, so it cannot be a window.
+		return reflect.Value{}, false, nil
+	}
+
+	if array.cloned.IsValid() {
+		if c.opts.stats != nil {
+			c.opts.stats.Reused++
+		}
+	} else {
+		cloned, err := c.makeSlice(reflect.SliceOf(elem), array.len, array.len, path)
+		if err != nil {
+			return reflect.Value{}, true, err
+		}
+		c.count(visitSlice)
+		// Publish the array before cloning elements that may point back into it.
+		array.cloned = cloned
+		if err := c.cloneBackingElements(array, offset, path); err != nil {
+			return reflect.Value{}, true, err
+		}
+	}
+
+	window := array.cloned.Slice3(offset, offset+v.Len(), offset+v.Cap())
+	if window.Type() != v.Type() {
+		window = window.Convert(v.Type())
+	}
+	return window, true, nil
+}
+
+// cloneBackingElements clones every element of array that lies within the
+// length of some view; the rest stay zero. Paths index from the start of the
+// slice at offset, so elements before it get negative indexes.
+func (c *cloneContext) cloneBackingElements(array *backingArray, offset int, path string) error {
+	size := array.cloned.Type().Elem().Size()
+	plain := c.copiesPlain(array.cloned.Type().Elem())
+	done := 0
+	for _, view := range array.views {
+		viewStart := int((view.Pointer() - array.start) / size)
+		from := max(done, viewStart)
+		to := viewStart + view.Len()
+		if from >= to {
+			continue
+		}
+		if plain {
+			reflect.Copy(array.cloned.Slice(from, to), view.Slice(from-viewStart, to-viewStart))
+			done = to
+			continue
+		}
+		for i := from; i < to; i++ {
+			elem, err := c.cloneValue(view.Index(i-viewStart), indexPath(path, i-offset))
+			if err != nil {
+				return err
+			}
+			if elem.IsValid() {
+				array.cloned.Index(i).Set(elem)
+			}
+		}
+		done = to
+	}
+	return nil
+}
@@ -1,6 +1,12 @@
 package deepclone
 
-import "testing"
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
 
 // Benchmark data types.
 type benchSimple struct {
@@ -28,12 +34,63 @@ type benchNested struct {
 	Settings map[string]any
 }
 
+type benchBoxed struct {
+	ID      int
+	Payload any
+	Tags    []string
+}
+
+type benchLarge struct {
+	Samples [128]float64
+	Name    string
+	Tags    []string
+}
+
+// benchWide resembles a configuration struct: mostly scalars, a few references.
+type benchWide struct {
+	Name, Host, Region, Zone, Env        string
+	Port, Workers, Retries, Timeout, TTL int
+	MaxConns, MinConns, Backlog, Window  int
+	Rate, Burst, Ratio, Jitter           float64
+	Debug, Verbose, TLS, Compress, Cache bool
+	Weight, Priority, Shard, Replica     uint32
+	Started                              int64
+	Tags                                 []string
+	Labels                               map[string]string
+}
+
+type benchPoint struct {
+	X, Y float64
+}
+
 type benchCircular struct {
 	ID   int
 	Name string
 	Self *benchCircular
 }
 
+type benchDeepSize struct {
+	Width, Height int
+}
+
+type benchDeepAttrs struct {
+	Labels []string
+	Size   benchDeepSize
+}
+
+type benchDeepChild struct {
+	Name   string
+	Attrs  benchDeepAttrs
+	Parent *benchDeepRoot
+}
+
+type benchDeepRoot struct {
+	ID       int
+	Meta     benchDeepAttrs
+	Children []benchDeepChild
+	Index    map[string]*benchDeepChild
+}
+
 // Benchmark fixtures.
 var (
 	benchIntVal    = 42
@@ -73,6 +130,105 @@ var (
 		c.Self = c
 		return c
 	}()
+	benchSetVal = func() map[string]struct{} {
+		m := make(map[string]struct{}, 1000)
+		for i := range 1000 {
+			m[strconv.Itoa(i)] = struct{}{}
+		}
+		return m
+	}()
+	benchFlatSliceVal = func() []benchSimple {
+		s := make([]benchSimple, 1000)
+		for i := range s {
+			s[i] = benchSimple{ID: i, Name: "flat", Age: i % 90}
+		}
+		return s
+	}()
+	benchDeepVal = func() *benchDeepRoot {
+		root := &benchDeepRoot{ID: 1, Meta: benchDeepAttrs{Labels: []string{"root"}}}
+		root.Children = []benchDeepChild{
+			{Name: "a", Attrs: benchDeepAttrs{Labels: []string{"x"}, Size: benchDeepSize{1, 2}}, Parent: root},
+			{Name: "b", Attrs: benchDeepAttrs{Size: benchDeepSize{3, 4}}, Parent: root},
+		}
+		root.Index = map[string]*benchDeepChild{"a": &root.Children[0], "b": &root.Children[1]}
+		return root
+	}()
+	benchPathMapVal = func() map[string][8]benchPoint {
+		m := make(map[string][8]benchPoint, 10_000)
+		for i := range 10_000 {
+			var path [8]benchPoint
+			for j := range path {
+				path[j] = benchPoint{X: float64(i), Y: float64(j)}
+			}
+			m["path-"+strconv.Itoa(i)] = path
+		}
+		return m
+	}()
+	benchPointerSliceVal = func() []*benchSimple {
+		s := make([]*benchSimple, 1000)
+		for i := range s {
+			s[i] = &benchSimple{ID: i, Name: "user", Age: i % 90}
+		}
+		return s
+	}()
+	benchJSONVal = func() map[string]any {
+		var doc strings.Builder
+		doc.WriteString(`{"meta":{"page":1,"total":100},"items":[`)
+		for i := range 100 {
+			if i > 0 {
+				doc.WriteByte(',')
+			}
+			doc.WriteString(`{"id":` + strconv.Itoa(i) + `,"name":"item","active":true,"score":1.5,` +
+				`"tags":["a","b","c"],"owner":{"name":"user","roles":["admin",null]}}`)
+		}
+		doc.WriteString(`]}`)
+		var decoded map[string]any
+		if err := json.Unmarshal([]byte(doc.String()), &decoded); err != nil {
+			panic(err)
+		}
+		return decoded
+	}()
+	// benchConfigVal resembles a service config decoded from YAML, which holds
+	// int and int64 numbers where JSON would hold float64.
+	benchConfigVal = func() map[string]any {
+		services := make([]any, 50)
+		for i := range services {
+			services[i] = map[string]any{
+				"name":     "service-" + strconv.Itoa(i),
+				"port":     8000 + i,
+				"replicas": 3,
+				"enabled":  i%2 == 0,
+				"limits":   map[string]any{"cpu": 0.5, "memory": int64(512 << 20)},
+				"env":      []any{map[string]any{"name": "LOG_LEVEL", "value": "info"}},
+				"ports":    []any{8000 + i, 9000 + i},
+			}
+		}
+		return map[string]any{
+			"version":  2,
+			"services": services,
+			"defaults": map[string]any{"timeout": 30, "retries": uint64(5), "regions": []any{"us", "eu"}},
+		}
+	}()
+	// benchHeaderVal resembles the headers of a typical API request.
+	benchHeaderVal = map[string][]string{
+		"Accept":          {"application/json", "text/plain"},
+		"Accept-Encoding": {"gzip", "deflate", "br"},
+		"Authorization":   {"Bearer token"},
+		"Cache-Control":   {"no-cache"},
+		"Content-Type":    {"application/json"},
+		"Cookie":          {"session=abc", "theme=dark"},
+		"User-Agent":      {"client/1.0"},
+		"X-Request-Id":    {"4f1c2a"},
+	}
+	benchDigestMapVal = func() map[[16]byte]int {
+		m := make(map[[16]byte]int, 10_000)
+		for i := range 10_000 {
+			var digest [16]byte
+			digest[0], digest[1], digest[15] = byte(i), byte(i>>8), 0xff
+			m[digest] = i
+		}
+		return m
+	}()
 	benchLargeSliceVal = func() []int {
 		s := make([]int, 10000)
 		for i := range s {
@@ -146,6 +302,310 @@ func BenchmarkClone(b *testing.B) {
 		}
 	})
 
+	b.Run("set_map_1000", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			_, _ = Clone(benchSetVal)
+		}
+	})
+
+	b.Run("set_map_1000_cloneset", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			_ = CloneSet(benchSetVal)
+		}
+	})
+
+	b.Run("set_map_100k_cloneset", func(b *testing.B) {
+		set := make(map[int]struct{}, 100_000)
+		for i := range 100_000 {
+			set[i] = struct{}{}
+		}
+		b.ReportAllocs()
+		for b.Loop() {
+			_ = CloneSet(set)
+		}
+	})
+
+	b.Run("set_map_1000_reflection", func(b *testing.B) {
+		type stringSet map[string]struct{}
+		set := stringSet(benchSetVal)
+		b.ReportAllocs()
+		for b.Loop() {
+			_, _ = Clone(set)
+		}
+	})
+
+	b.Run("digest_map_10k", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			_, _ = Clone(benchDigestMapVal)
+		}
+	})
+
+	b.Run("digest_map_10k_reflection", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			_, _ = CloneWith(benchDigestMapVal, WithForceReflection())
+		}
+	})
+
+	b.Run("array_map_10k", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			_, _ = Clone(benchPathMapVal)
+		}
+	})
+
+	b.Run("array_map_10k_reflection", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			_, _ = CloneWith(benchPathMapVal, WithForceReflection())
+		}
+	})
+
+	b.Run("struct_map_100k", func(b *testing.B) {
+		m := make(map[int]benchSimple, 100_000)
+		for i := range 100_000 {
+			m[i] = benchSimple{ID: i, Name: "entry", Age: i % 90}
+		}
+		b.ReportAllocs()
+		for b.Loop() {
+			_, _ = Clone(m)
+		}
+	})
+
+	b.Run("decoded_json", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			_, _ = Clone(benchJSONVal)
+		}
+	})
+
+	b.Run("decoded_config", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			_, _ = Clone(benchConfigVal)
+		}
+	})
+
+	b.Run("nested_any_10k", func(b *testing.B) {
+		data := nestedAnySlices(10_000)
+		b.ReportAllocs()
+		for b.Loop() {
+			_, _ = Clone(data)
+		}
+	})
+
+	b.Run("header_map", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			_, _ = Clone(benchHeaderVal)
+		}
+	})
+
+	b.Run("header_map_reflection", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			_, _ = CloneWith(benchHeaderVal, WithForceReflection())
+		}
+	})
+
+	b.Run("wide_struct", func(b *testing.B) {
+		wide := benchWide{
+			Name: "api", Host: "localhost", Region: "eu", Zone: "a", Env: "prod",
+			Port: 8080, Workers: 8, Retries: 3, Timeout: 30, TTL: 60,
+			Rate: 1.5, Debug: true, Weight: 10,
+			Tags:   []string{"edge"},
+			Labels: map[string]string{"team": "core"},
+		}
+		b.ReportAllocs()
+		for b.Loop() {
+			_, _ = Clone(wide)
+		}
+	})
+
+	b.Run("large_struct", func(b *testing.B) {
+		large := benchLarge{Name: "large", Tags: []string{"a"}}
+		b.ReportAllocs()
+		for b.Loop() {
+			_, _ = Clone(large)
+		}
+	})
+
+	b.Run("slice_into_100", func(b *testing.B) {
+		dst := make([]int, 0, len(benchSliceVal))
+		b.ReportAllocs()
+		for b.Loop() {
+			dst, _ = CloneSliceInto(dst, benchSliceVal)
+		}
+	})
+
+	b.Run("map_into_100", func(b *testing.B) {
+		dst := make(map[string]int, len(benchMapVal))
+		b.ReportAllocs()
+		for b.Loop() {
+			dst, _ = CloneMapInto(dst, benchMapVal)
+		}
+	})
+
+	b.Run("flat_slice_1000", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			_ = CloneFlatSlice(benchFlatSliceVal)
+		}
+	})
+
+	b.Run("flat_slice_1000_clone", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			_, _ = Clone(benchFlatSliceVal)
+		}
+	})
+
+	b.Run("flat_slice_1000_into", func(b *testing.B) {
+		dst := make([]benchSimple, 0, len(benchFlatSliceVal))
+		b.ReportAllocs()
+		for b.Loop() {
+			dst, _ = CloneSliceInto(dst, benchFlatSliceVal)
+		}
+	})
+
+	b.Run("anonymous_struct_slice_1000", func(b *testing.B) {
+		rows := make([]struct {
+			ID   int
+			Tags []string
+		}, 1000)
+		for i := range rows {
+			rows[i].ID = i
+			rows[i].Tags = []string{"a", "b"}
+		}
+		b.ReportAllocs()
+		for b.Loop() {
+			_, _ = Clone(rows)
+		}
+	})
+
+	b.Run("named_struct_slice_1000", func(b *testing.B) {
+		type row struct {
+			ID   int
+			Tags []string
+		}
+		rows := make([]row, 1000)
+		for i := range rows {
+			rows[i] = row{ID: i, Tags: []string{"a", "b"}}
+		}
+		b.ReportAllocs()
+		for b.Loop() {
+			_, _ = Clone(rows)
+		}
+	})
+
+	b.Run("pointer_slice_1000", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			_, _ = Clone(benchPointerSliceVal)
+		}
+	})
+
+	b.Run("bytes_4k", func(b *testing.B) {
+		data := make([]byte, 4<<10)
+		b.ReportAllocs()
+		b.SetBytes(int64(len(data)))
+		for b.Loop() {
+			_, _ = Clone(data)
+		}
+	})
+
+	b.Run("named_bytes_4k", func(b *testing.B) {
+		type blob []byte
+		data := make(blob, 4<<10)
+		b.ReportAllocs()
+		b.SetBytes(int64(len(data)))
+		for b.Loop() {
+			_, _ = Clone(data)
+		}
+	})
+
+	b.Run("bitset_1024", func(b *testing.B) {
+		data := make(bitset, 1024)
+		for i := range data {
+			data[i] = uint64(i) * 0x9e3779b97f4a7c15
+		}
+		b.ReportAllocs()
+		b.SetBytes(int64(len(data) * 8))
+		for b.Loop() {
+			_, _ = Clone(data)
+		}
+	})
+
+	b.Run("bitset_1024_field", func(b *testing.B) {
+		type filter struct {
+			Name string
+			Bits bitset
+		}
+		data := &filter{Name: "bloom", Bits: make(bitset, 1024)}
+		b.ReportAllocs()
+		for b.Loop() {
+			_, _ = Clone(data)
+		}
+	})
+
+	b.Run("guarded_store_100", func(b *testing.B) {
+		data := &guardedStore{Data: benchMapVal}
+		b.ReportAllocs()
+		for b.Loop() {
+			_, _ = Clone(data)
+		}
+	})
+
+	b.Run("nil_pointer_struct", func(b *testing.B) {
+		data := newSparseNode(16)
+		b.ReportAllocs()
+		for b.Loop() {
+			_, _ = Clone(&data)
+		}
+	})
+
+	b.Run("layout_pointer", func(b *testing.B) {
+		RegisterLayout(reflect.TypeFor[layoutPoint](), copyLayoutPoint)
+		b.Cleanup(func() { RegisterLayout(reflect.TypeFor[layoutPoint](), nil) })
+		data := &layoutPoint{X: 1, Y: 2, Tags: []string{"a", "b"}}
+		b.ReportAllocs()
+		for b.Loop() {
+			_, _ = Clone(data)
+		}
+	})
+
+	b.Run("layout_slice_100", func(b *testing.B) {
+		RegisterLayout(reflect.TypeFor[layoutPoint](), copyLayoutPoint)
+		b.Cleanup(func() { RegisterLayout(reflect.TypeFor[layoutPoint](), nil) })
+		data := make([]layoutPoint, 100)
+		for i := range data {
+			data[i] = layoutPoint{X: i, Tags: []string{"a"}}
+		}
+		b.ReportAllocs()
+		for b.Loop() {
+			_, _ = Clone(data)
+		}
+	})
+
+	b.Run("array_3_float64", func(b *testing.B) {
+		vector := [3]float64{1, 2, 3}
+		b.ReportAllocs()
+		for b.Loop() {
+			_, _ = Clone(vector)
+		}
+	})
+
+	b.Run("array_256_byte", func(b *testing.B) {
+		var table [256]byte
+		b.ReportAllocs()
+		for b.Loop() {
+			_, _ = Clone(table)
+		}
+	})
+
 	b.Run("interface", func(b *testing.B) {
 		var iface any = benchSimpleVal
 		b.ReportAllocs()
@@ -153,4 +613,36 @@ func BenchmarkClone(b *testing.B) {
 			_, _ = Clone(iface)
 		}
 	})
+
+	b.Run("interface_field", func(b *testing.B) {
+		boxed := benchBoxed{ID: 1, Payload: benchNested{ID: 2, Tags: []string{"a"}}, Tags: []string{"b"}}
+		b.ReportAllocs()
+		for b.Loop() {
+			_, _ = Clone(boxed)
+		}
+	})
+}
+
+// BenchmarkColdClone measures the first clone of a type, including struct
+// metadata analysis, against the same clone with a warm cache.
+func BenchmarkColdClone(b *testing.B) {
+	b.Cleanup(resetCache)
+
+	b.Run("deep_struct_cold", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			b.StopTimer()
+			resetCache()
+			b.StartTimer()
+			_, _ = Clone(benchDeepVal)
+		}
+	})
+
+	b.Run("deep_struct_warm", func(b *testing.B) {
+		_, _ = Clone(benchDeepVal)
+		b.ReportAllocs()
+		for b.Loop() {
+			_, _ = Clone(benchDeepVal)
+		}
+	})
 }
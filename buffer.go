@@ -0,0 +1,53 @@
+package deepclone
+
+import (
+	"bufio"
+	"reflect"
+)
+
+var (
+	bufioReaderPointerType = reflect.TypeFor[*bufio.Reader]()
+	bufioWriterPointerType = reflect.TypeFor[*bufio.Writer]()
+)
+
+// isBufferType reports whether t is a *bufio.Reader or a *bufio.Writer.
+func isBufferType(t reflect.Type) bool {
+	return t == bufioReaderPointerType || t == bufioWriterPointerType
+}
+
+// cloneBuffer returns a new reader or writer with an empty buffer of the same
+// size for v, a *bufio.Reader or *bufio.Writer, when RewrapBuffers is set. The
+// buffers keep their stream unexported, so the new one reads from or writes
+// to v itself.
+func (c *cloneContext) cloneBuffer(v reflect.Value) (reflect.Value, bool) {
+	if c.opts.bufferPolicy != RewrapBuffers || !isBufferType(v.Type()) || v.IsNil() || !v.CanInterface() {
+		return reflect.Value{}, false
+	}
+	switch b := v.Interface().(type) {
+	case *bufio.Reader:
+		return reflect.ValueOf(bufio.NewReaderSize(bufferedSource{b}, b.Size())), true
+	case *bufio.Writer:
+		return reflect.ValueOf(bufio.NewWriterSize(bufferedSink{b}, b.Size())), true
+	}
+	return reflect.Value{}, false
+}
+
+// bufferedSource reads from a *bufio.Reader under a type bufio.NewReaderSize
+// does not recognize, so it wraps the reader instead of returning it.
+type bufferedSource struct {
+	r *bufio.Reader
+}
+
+func (s bufferedSource) Read(p []byte) (int, error) {
+	return s.r.Read(p)
+}
+
+// bufferedSink writes to a *bufio.Writer under a type bufio.NewWriterSize
+// does not recognize, so it wraps the writer instead of returning it.
+type bufferedSink struct {
+	w *bufio.Writer
+}
+
+func (s bufferedSink) Write(p []byte) (int, error) {
+	return s.w.Write(p)
+}
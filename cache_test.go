@@ -54,6 +54,48 @@ func TestStructCacheMetricsIdempotent(t *testing.T) {
 	assert.Equal(t, 1, fields)
 }
 
+func TestStructCacheSharesAnonymousStructTypes(t *testing.T) {
+	resetCache()
+	t.Cleanup(resetCache)
+
+	point := func(x int) struct{ X int } { return struct{ X int }{X: x} }
+	for i := range 100 {
+		// Identical anonymous struct literals denote one type.
+		MustClone(struct{ X int }{X: i})
+		MustClone(point(i))
+	}
+
+	entries, _ := cacheStats()
+	assert.Equal(t, 1, entries, "identical anonymous structs share one entry")
+
+	original := struct {
+		Name string
+		Tags []string
+	}{Name: "a", Tags: []string{"x", "y"}}
+	cloned := MustClone(original)
+
+	assert.Equal(t, original, cloned)
+	cloned.Tags[0] = "changed"
+	assert.Equal(t, "x", original.Tags[0])
+	entries, fields := cacheStats()
+	assert.Equal(t, 2, entries, "a different shape gets its own entry")
+	assert.Equal(t, 3, fields)
+}
+
+func TestStructCacheHoldsOneEntryPerGenericInstantiation(t *testing.T) {
+	resetCache()
+	t.Cleanup(resetCache)
+
+	for range 10 {
+		MustClone(newGenericTree(1, 2))
+		MustClone(newGenericTree(&treeEntity{Name: "root"}, &treeEntity{Name: "child"}))
+	}
+
+	entries, fields := cacheStats()
+	assert.Equal(t, 3, entries, "genericTree[int], genericTree[*treeEntity], and treeEntity")
+	assert.Equal(t, 8, fields)
+}
+
 func TestStructCacheMetricsIncludesUnexportedFields(t *testing.T) {
 	resetCache()
 	t.Cleanup(resetCache)
@@ -1,11 +1,21 @@
 package deepclone
 
 import (
+	"bufio"
+	"cmp"
+	"context"
+	"encoding/json"
+	"io"
 	"maps"
+	"net/netip"
 	"os"
 	"reflect"
+	"slices"
+	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 )
 
 type fieldAction int
@@ -13,6 +23,8 @@ type fieldAction int
 const (
 	copyField fieldAction = iota
 	cloneField
+	shareField
+	zeroField
 )
 
 var (
@@ -20,9 +32,38 @@ var (
 	structCache = make(map[reflect.Type]*structTypeInfo)
 	cacheMutex  sync.RWMutex
 	errorType   = reflect.TypeFor[error]()
+	contextType = reflect.TypeFor[context.Context]()
+	// reflectTypeType is the interface implemented by runtime type descriptors.
+	reflectTypeType = reflect.TypeFor[reflect.Type]()
+
+	jsonMarshalerType   = reflect.TypeFor[json.Marshaler]()
+	jsonUnmarshalerType = reflect.TypeFor[json.Unmarshaler]()
+
+	afterClonerType = reflect.TypeFor[AfterCloner]()
+	immutableType   = reflect.TypeFor[Immutable]()
+	// handleType is implemented by OS handles such as *os.File.
+	handleType = reflect.TypeFor[interface{ Fd() uintptr }]()
+
+	stringSlicesType = reflect.TypeFor[map[string][]string]()
+	closerType       = reflect.TypeFor[io.Closer]()
+
+	syncMapPointerType = reflect.TypeFor[*sync.Map]()
+	syncPoolType       = reflect.TypeFor[sync.Pool]()
 )
 
+// valueTypes are standard library structs whose unexported references point
+// at state that never changes, such as the *time.Location of a time.Time, so
+// a copy by assignment is a clone.
+var valueTypes = map[reflect.Type]struct{}{
+	reflect.TypeFor[time.Time]():      {},
+	reflect.TypeFor[netip.Addr]():     {},
+	reflect.TypeFor[netip.AddrPort](): {},
+	reflect.TypeFor[netip.Prefix]():   {},
+}
+
 var unsupportedTypes = map[reflect.Type]string{
+	reflect.TypeFor[bufio.Reader]():   "buffered streams cannot be cloned",
+	reflect.TypeFor[bufio.Writer]():   "buffered streams cannot be cloned",
 	reflect.TypeFor[os.File]():        "files cannot be cloned",
 	reflect.TypeFor[sync.Cond]():      "sync primitives cannot be cloned",
 	reflect.TypeFor[sync.Map]():       "sync primitives cannot be cloned",
@@ -56,16 +97,329 @@ type visitKey struct {
 
 type cloneContext struct {
 	visited map[visitKey]reflect.Value
+	// active holds the keys still being cloned and, for pointers, the size in
+	// bytes of their target. It is only tracked when a cycle hook needs to tell
+	// back edges apart from shared references.
+	active map[visitKey]uintptr
+	// depth counts the cloneValue calls on the current path when a depth limit
+	// is set.
+	depth int
+	// backing holds the backing arrays found by scanBackingArrays when
+	// WithPreserveBackingArrays is set.
+	backing backingArrays
+	// dynamic reports whether map[string]any and []any take the type-switch
+	// path in dynamic.go.
+	dynamic bool
+	opts    options
 }
 
-func newCloneContext() *cloneContext {
-	return &cloneContext{
+func newCloneContext(opts options) *cloneContext {
+	c := &cloneContext{
 		visited: make(map[visitKey]reflect.Value, 8),
+		dynamic: opts.clonesDynamic(),
+		opts:    opts,
+	}
+	if opts.cycleHook != nil {
+		c.active = make(map[visitKey]uintptr)
+	}
+	return c
+}
+
+func (c *cloneContext) checkCollectionLen(v reflect.Value, path string) error {
+	if c.opts.maxCollectionLen > 0 && v.Len() > c.opts.maxCollectionLen {
+		return limitError(path, v.Type(), "collection length", c.opts.maxCollectionLen, v.Len())
+	}
+	return nil
+}
+
+// newValue returns a pointer to a new zero value of type t, from the configured
+// allocator when there is one.
+func (c *cloneContext) newValue(t reflect.Type, path string) (reflect.Value, error) {
+	if c.opts.allocator == nil {
+		return reflect.New(t), nil
+	}
+	ptr := c.opts.allocator(t)
+	if !ptr.IsValid() || ptr.Type() != reflect.PointerTo(t) || ptr.IsNil() {
+		return reflect.Value{}, unsupportedError(path, t, "allocator must return a non-nil pointer to the requested type")
+	}
+	return ptr, nil
+}
+
+// makeSlice returns a new slice of type t. With an allocator the backing array
+// is allocated as a [capacity]Elem value.
+func (c *cloneContext) makeSlice(t reflect.Type, length, capacity int, path string) (reflect.Value, error) {
+	if c.opts.allocator == nil || capacity == 0 {
+		return reflect.MakeSlice(t, length, capacity), nil
+	}
+	array, err := c.newValue(reflect.ArrayOf(capacity, t.Elem()), path)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	return array.Elem().Slice3(0, length, capacity).Convert(t), nil
+}
+
+// runsAfterClone reports whether WithAfterClone calls AfterClone on clones of
+// slice or map type t, which then cannot be copied by a shortcut.
+func (c *cloneContext) runsAfterClone(t reflect.Type) bool {
+	return c.opts.afterClone && hasAfterCloneType(t)
+}
+
+// afterCloneCollection calls AfterClone on a finished slice or map clone when
+// enabled and returns the clone. The hook is called through a pointer to a
+// copy of the header, so a pointer receiver may replace the header it returns.
+func (c *cloneContext) afterCloneCollection(cloned reflect.Value) reflect.Value {
+	if !c.runsAfterClone(cloned.Type()) || !cloned.CanInterface() {
+		return cloned
+	}
+	header := reflect.New(cloned.Type())
+	header.Elem().Set(cloned)
+	header.Interface().(AfterCloner).AfterClone()
+	return header.Elem()
+}
+
+// afterClone calls AfterClone on a finished struct clone when enabled.
+func (c *cloneContext) afterClone(info *structTypeInfo, clonedStruct reflect.Value) {
+	if !c.opts.afterClone || !info.afterClone || !clonedStruct.CanInterface() {
+		return
+	}
+	if clonedStruct.CanAddr() {
+		if hook, ok := clonedStruct.Addr().Interface().(AfterCloner); ok {
+			hook.AfterClone()
+			return
+		}
+	}
+	if hook, ok := clonedStruct.Interface().(AfterCloner); ok {
+		hook.AfterClone()
+	}
+}
+
+// sharesType reports whether values of type t are shared because t is
+// Immutable, a slice type registered by RegisterImmutableSlice, a parsed
+// template, or a handle under ShareHandles, or by WithShareTypes,
+// WithDefaultSharePredicate, or WithShareIOInterfaces. A pointer is shared
+// when the first two share its target type. Interface types are never shared by type checks other
+// than WithShareTypes; their dynamic values are checked once unwrapped.
+func (c *cloneContext) sharesType(t reflect.Type) bool {
+	if (isImmutableType(t) || isImmutableSlice(t) || c.opts.handlePolicy == ShareHandles && isHandleType(t)) && !c.hasCustomClone(t) {
+		return true
+	}
+	if isTemplateType(t) {
+		// Templates have a Clone method of their own, which only a
+		// WithCloneFunc overrides.
+		_, custom := c.opts.cloneFuncs[t]
+		return !custom
+	}
+	if c.opts.shareIO && t.Kind() != reflect.Interface && t.Implements(closerType) && !c.hasCustomClone(t) {
+		return true
+	}
+	// A pointer to a type the caller shares is shared too, ahead of the
+	// pointer's Clone method and the struct shortcuts in clonePointer.
+	return c.sharesListed(t) || t.Kind() == reflect.Pointer && c.sharesListed(t.Elem())
+}
+
+// sharesListed reports whether t is shared by WithDefaultSharePredicate or
+// WithShareTypes.
+func (c *cloneContext) sharesListed(t reflect.Type) bool {
+	if c.opts.sharePredicate != nil && c.opts.sharePredicate(t) {
+		return true
+	}
+	if len(c.opts.shareTypes) == 0 {
+		return false
+	}
+	_, ok := c.opts.shareTypes[t]
+	return ok
+}
+
+// sharesField reports whether the named exported field of struct type t is
+// shared because CloneShallowFields did not ask for it to be deep-cloned.
+func (c *cloneContext) sharesField(t reflect.Type, name string) bool {
+	if c.opts.shallowType != t {
+		return false
+	}
+	_, deep := c.opts.deepFields[name]
+	return !deep
+}
+
+// hasCustomClone reports whether values of type t are cloned by a Clone
+// method, a WithCloneFunc function, a RegisterLayout copier, or a dedicated
+// engine path, as for sync.Pool and rewrapped buffers, rather than field by
+// field.
+func (c *cloneContext) hasCustomClone(t reflect.Type) bool {
+	if hasCustomCloneType(t) && !c.forcesStructural(t) {
+		return true
+	}
+	if _, ok := c.opts.cloneFuncs[t]; ok {
+		return true
+	}
+	if c.opts.bufferPolicy == RewrapBuffers && isBufferType(t) {
+		return true
+	}
+	if t == syncPoolType {
+		return true
+	}
+	return hasLayout(t)
+}
+
+// copiesPlain reports whether values of type t are cloned by assignment, with
+// no per-value work.
+func (c *cloneContext) copiesPlain(t reflect.Type) bool {
+	// Plain types have no Clone method, so only clone funcs can replace them.
+	return !c.opts.forceReflection && isPlainType(t) && !c.hasCloneFunc(t)
+}
+
+// hasCloneFunc reports whether a WithCloneFunc function covers t or, for an
+// array type, the elements it nests, so that a plain value still needs
+// per-element work.
+func (c *cloneContext) hasCloneFunc(t reflect.Type) bool {
+	if len(c.opts.cloneFuncs) == 0 {
+		return false
+	}
+	for {
+		if _, ok := c.opts.cloneFuncs[t]; ok {
+			return true
+		}
+		if t.Kind() != reflect.Array {
+			return false
+		}
+		t = t.Elem()
+	}
+}
+
+// forcesStructural reports whether WithStructuralTypes or ForceStructural
+// lists t, or the type t points to, so its Clone method must be ignored.
+func (c *cloneContext) forcesStructural(t reflect.Type) bool {
+	if isForcedStructural(t) {
+		return true
+	}
+	if len(c.opts.structuralTypes) == 0 {
+		return false
+	}
+	if _, ok := c.opts.structuralTypes[t]; ok {
+		return true
+	}
+	if t.Kind() == reflect.Pointer {
+		_, ok := c.opts.structuralTypes[t.Elem()]
+		return ok
+	}
+	return false
+}
+
+// shareValue checks that v may be shared with the clone and records the
+// sharing.
+func (c *cloneContext) shareValue(v reflect.Value, path string) error {
+	if err := unsupportedSharedValue(v, path); err != nil {
+		return err
+	}
+	if holdsReferences(v) {
+		c.markShared()
+	}
+	return nil
+}
+
+func (c *cloneContext) markShared() {
+	if c.opts.shared != nil {
+		*c.opts.shared = true
+	}
+}
+
+// count records a newly cloned pointer target, slice, or map in the stats.
+func (c *cloneContext) count(kind visitKind) {
+	if c.opts.stats == nil {
+		return
+	}
+	switch kind {
+	case visitPointer:
+		c.opts.stats.Pointers++
+	case visitSlice:
+		c.opts.stats.Slices++
+	case visitMap:
+		c.opts.stats.Maps++
+	}
+}
+
+// enter registers cloned as the clone of key before its contents are cloned.
+func (c *cloneContext) enter(key visitKey, cloned reflect.Value) {
+	c.visited[key] = cloned
+	if c.active == nil {
+		return
+	}
+	if key.kind == visitPointer {
+		c.active[key] = key.typ.Elem().Size()
+		return
+	}
+	c.active[key] = 0
+}
+
+// mapPointer records that the source pointer src clones to cloned when
+// CloneWithMapping collects the mapping.
+func (c *cloneContext) mapPointer(src, cloned reflect.Value) {
+	if c.opts.mapping != nil && src.CanInterface() && cloned.CanInterface() {
+		c.opts.mapping[src.Interface()] = cloned.Interface()
+	}
+}
+
+// revisit reports key to the cycle hook when a visited hit points back at a
+// value that is still being cloned, or, for a pointer, into the target of a
+// pointer still being cloned, such as at one of its fields.
+func (c *cloneContext) revisit(key visitKey) {
+	if c.opts.stats != nil {
+		c.opts.stats.Reused++
+	}
+	if c.active == nil {
+		return
+	}
+	if _, cycle := c.active[key]; cycle || key.kind == visitPointer && c.insideActive(key.addr) {
+		c.opts.cycleHook(key.typ, key.addr)
+	}
+}
+
+// insideActive reports whether addr lies in the target of a pointer that is
+// still being cloned.
+func (c *cloneContext) insideActive(addr uintptr) bool {
+	for key, size := range c.active {
+		if key.addr <= addr && addr < key.addr+size {
+			return true
+		}
+	}
+	return false
+}
+
+// leave forgets key once its value is fully cloned when shared references are
+// expanded, so only ancestors on the current path are deduplicated.
+func (c *cloneContext) leave(key visitKey) {
+	if c.opts.expandShared {
+		delete(c.visited, key)
+	}
+	if c.active != nil {
+		delete(c.active, key)
 	}
 }
 
 type structTypeInfo struct {
 	fields []structFieldInfo
+	// walked holds the fields that need more than the shallow copy of the
+	// struct, skipping fields of plain types, which the copy already cloned.
+	walked []structFieldInfo
+	// plain reports whether a copy of the struct by assignment is a deep clone.
+	plain bool
+	// afterClone reports whether the struct or a pointer to it is an
+	// AfterCloner.
+	afterClone bool
+	// jsonRoundTrip reports whether a pointer to the struct implements both
+	// json.Marshaler and json.Unmarshaler.
+	jsonRoundTrip bool
+	// value reports whether the struct is one of valueTypes, which the
+	// shallow copy of the struct already cloned.
+	value bool
+	// opaque reports whether the struct has no exported fields but holds
+	// references in its unexported ones, and is neither one of valueTypes nor
+	// Immutable.
+	opaque bool
+	// transformed reports whether any field names a transform.
+	transformed bool
+	// atomicPointer reports whether the struct is a sync/atomic.Pointer, whose
+	// pointee is cloned through Load and Store.
+	atomicPointer bool
 }
 
 type structFieldInfo struct {
@@ -73,6 +427,11 @@ type structFieldInfo struct {
 	name     string
 	exported bool
 	action   fieldAction
+	// transform names the registered transform applied to the cloned field.
+	transform string
+	// cow reports whether the field is unexported and holds a COWMap by value,
+	// which the shallow copy cannot clone.
+	cow bool
 }
 
 func structInfo(t reflect.Type) *structTypeInfo {
@@ -83,14 +442,12 @@ func structInfo(t reflect.Type) *structTypeInfo {
 	}
 	cacheMutex.RUnlock()
 
-	cacheMutex.Lock()
-	defer cacheMutex.Unlock()
-
-	if info, exists := structCache[t]; exists {
-		return info
-	}
-
+	// Analyze without the lock because plain field types recurse into structInfo.
 	fields := make([]structFieldInfo, t.NumField())
+	var walked []structFieldInfo
+	plain := true
+	exported := false
+	transformed := false
 
 	for i := range t.NumField() {
 		field := t.Field(i)
@@ -100,17 +457,75 @@ func structInfo(t reflect.Type) *structTypeInfo {
 			exported: field.IsExported(),
 			action:   copyField,
 		}
-		if info.exported && shouldCloneType(field.Type) {
+		info.cow = !info.exported && containsCOWMap(field.Type)
+		if info.exported && shouldCloneType(field.Type) && !isEmptyStruct(field.Type) {
 			info.action = cloneField
 		}
+		if action, ok := fieldTagAction(field.Tag.Get(tagKey)); ok {
+			info.action = action
+		}
+		info.transform = fieldTagTransform(field.Tag.Get(tagKey))
+		if info.transform != "" {
+			transformed = true
+		}
+		if info.action == zeroField || info.transform != "" || !isPlainType(field.Type) {
+			plain = false
+			walked = append(walked, info)
+		}
+		if info.exported {
+			exported = true
+		}
 		fields[i] = info
 	}
 
-	info := &structTypeInfo{fields: fields}
+	cacheMutex.Lock()
+	defer cacheMutex.Unlock()
+
+	if info, exists := structCache[t]; exists {
+		return info
+	}
+	_, value := valueTypes[t]
+	info := &structTypeInfo{
+		fields:        fields,
+		walked:        walked,
+		plain:         plain,
+		afterClone:    hasAfterCloneType(t),
+		jsonRoundTrip: reflect.PointerTo(t).Implements(jsonMarshalerType) && reflect.PointerTo(t).Implements(jsonUnmarshalerType),
+		value:         value,
+		opaque:        !exported && !plain && !value && !isImmutableType(reflect.PointerTo(t)),
+		transformed:   transformed,
+		atomicPointer: isAtomicPointerType(t),
+	}
 	structCache[t] = info
 	return info
 }
 
+// isPlainType reports whether copying a value of type t by assignment is a
+// deep clone: t holds no references, has no custom Clone method, and is not
+// unsupported state.
+func isPlainType(t reflect.Type) bool {
+	if _, ok := unsupportedTypes[t]; ok {
+		return false
+	}
+	if hasCustomCloneType(t) || hasAfterCloneType(t) {
+		return false
+	}
+
+	switch t.Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128,
+		reflect.String:
+		return true
+	case reflect.Array:
+		return isPlainType(t.Elem())
+	case reflect.Struct:
+		return structInfo(t).plain
+	default:
+		return false
+	}
+}
+
 func cacheStats() (entries, fields int) {
 	cacheMutex.RLock()
 	defer cacheMutex.RUnlock()
@@ -138,12 +553,41 @@ func cloneSliceExact[S ~[]E, E any](s S) S {
 	return cloned
 }
 
+// cloneStringSlices clones a map of string slices, such as an http.Header,
+// copying every value into one shared backing array. Each value keeps its
+// length and capacity, so appending to one cannot overwrite another.
+func cloneStringSlices(m map[string][]string) map[string][]string {
+	if m == nil {
+		return nil
+	}
+	total := 0
+	for _, values := range m {
+		total += cap(values)
+	}
+	backing := make([]string, total)
+	cloned := make(map[string][]string, len(m))
+	for key, values := range m {
+		if values == nil {
+			cloned[key] = nil
+			continue
+		}
+		n := copy(backing, values)
+		cloned[key] = backing[:n:cap(values)]
+		backing = backing[cap(values):]
+	}
+	return cloned
+}
+
 func shouldCloneKind(kind reflect.Kind) bool {
 	return kind == reflect.Slice || kind == reflect.Map || kind == reflect.Pointer ||
 		kind == reflect.Interface || kind == reflect.Array || kind == reflect.Struct ||
 		kind == reflect.Chan || kind == reflect.Func || kind == reflect.UnsafePointer
 }
 
+func isEmptyStruct(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t.NumField() == 0 && !hasCustomCloneType(t)
+}
+
 func shouldCloneType(t reflect.Type) bool {
 	return shouldCloneKind(t.Kind()) || hasCustomCloneType(t)
 }
@@ -184,11 +628,47 @@ func unsupportedValue(v reflect.Value, path string) error {
 	}
 }
 
+// ignoresUnsupported reports whether v is a non-nil channel, function, or
+// unsafe pointer that WithUnsupportedHook accepts, calling the hook if so, or a
+// handle or buffer that ZeroHandles or ZeroBuffers leaves out of the clone.
+func (c *cloneContext) ignoresUnsupported(v reflect.Value, path string) bool {
+	if c.opts.handlePolicy == ZeroHandles && isHandleType(v.Type()) && !isNil(v) && !c.hasCustomClone(v.Type()) {
+		return true
+	}
+	if c.opts.bufferPolicy == ZeroBuffers && isBufferType(v.Type()) && !isNil(v) && !c.hasCustomClone(v.Type()) {
+		return true
+	}
+	if c.opts.unsupportedHook == nil || isNil(v) {
+		return false
+	}
+	switch v.Kind() {
+	case reflect.Chan, reflect.Func, reflect.UnsafePointer:
+		c.opts.unsupportedHook(path, v.Kind())
+		return true
+	default:
+		return false
+	}
+}
+
+// unsupportedSharedValue rejects values that cannot be shared by copying them,
+// such as a sync primitive held by value.
+func unsupportedSharedValue(v reflect.Value, path string) error {
+	if reason, ok := unsupportedTypes[v.Type()]; ok {
+		return unsupportedError(path, v.Type(), reason)
+	}
+	return nil
+}
+
 func unsupportedTypeReason(t reflect.Type) (string, bool) {
 	if reason, ok := unsupportedTypes[t]; ok {
 		return reason, true
 	}
-	if t.Kind() == reflect.Pointer {
+	// Handles reach this check only under RejectHandles.
+	if isHandleType(t) {
+		return "files cannot be cloned", true
+	}
+	// A *sync.Map is cloned entry by entry by cloneSyncMap.
+	if t.Kind() == reflect.Pointer && t != syncMapPointerType {
 		return unsupportedTypeReason(t.Elem())
 	}
 	return "", false
@@ -209,6 +689,36 @@ func isReferenceLike(kind reflect.Kind) bool {
 		kind == reflect.Interface
 }
 
+// holdsReferences reports whether v is or directly contains a non-nil
+// reference. Strings are immutable and do not count.
+func holdsReferences(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map, reflect.Pointer,
+		reflect.Slice, reflect.UnsafePointer:
+		return !v.IsNil()
+	case reflect.Struct:
+		for i := range v.NumField() {
+			if holdsReferences(v.Field(i)) {
+				return true
+			}
+		}
+	case reflect.Array:
+		for i := range v.Len() {
+			if holdsReferences(v.Index(i)) {
+				return true
+			}
+		}
+	default:
+	}
+	return false
+}
+
+// isNilPointer reports whether v is a nil pointer, which clones to itself, so
+// callers holding a shallow copy can skip it without building its path.
+func isNilPointer(v reflect.Value) bool {
+	return v.Kind() == reflect.Pointer && v.IsNil()
+}
+
 func isNil(v reflect.Value) bool {
 	switch v.Kind() {
 	case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map, reflect.Pointer,
@@ -219,6 +729,66 @@ func isNil(v reflect.Value) bool {
 	}
 }
 
+// sharesInterface reports whether a value of dynamic type concrete held in the
+// interface type iface is shared instead of cloned. Errors are treated as
+// immutable so sentinel comparisons and errors.Is keep working, and contexts
+// are immutable by contract and carry cancellation wiring that must not be
+// duplicated. A reflect.Type is shared in any interface: only package reflect
+// can implement it, and its values are immutable runtime type descriptors.
+func sharesInterface(iface, concrete reflect.Type) bool {
+	if concrete.Implements(reflectTypeType) {
+		return true
+	}
+	return (iface.Implements(errorType) || iface.Implements(contextType)) && !hasCustomCloneType(concrete)
+}
+
+// isHandleType reports whether t is an OS handle, which has an Fd method like
+// *os.File. Interface types are excluded so values are judged by their dynamic
+// type.
+func isHandleType(t reflect.Type) bool {
+	return t.Kind() != reflect.Interface && t.Implements(handleType)
+}
+
+// isAtomicPointerType reports whether t is an instantiation of the generic
+// sync/atomic.Pointer.
+func isAtomicPointerType(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t.PkgPath() == "sync/atomic" && strings.HasPrefix(t.Name(), "Pointer[")
+}
+
+// loadAtomicPointer returns the pointer stored in v, an atomic.Pointer that is
+// addressable or was not reached through unexported fields.
+func loadAtomicPointer(v reflect.Value) reflect.Value {
+	if !v.CanAddr() {
+		addressable := reflect.New(v.Type()).Elem()
+		addressable.Set(v)
+		v = addressable
+	}
+	return v.Addr().MethodByName("Load").Call(nil)[0]
+}
+
+// isTemplateType reports whether t is a *text/template.Template or a
+// *html/template.Template. Parsed templates are used read-only, and an
+// html/template refuses to Clone once it has executed, so they are shared.
+// They are matched by name so the package does not link either template
+// package.
+func isTemplateType(t reflect.Type) bool {
+	if t.Kind() != reflect.Pointer || t.Elem().Name() != "Template" {
+		return false
+	}
+	pkg := t.Elem().PkgPath()
+	return pkg == "text/template" || pkg == "html/template"
+}
+
+// isImmutableType reports whether t implements Immutable. Interface types are
+// excluded so values are judged by their dynamic type.
+func isImmutableType(t reflect.Type) bool {
+	return t.Kind() != reflect.Interface && t.Implements(immutableType)
+}
+
+func hasAfterCloneType(t reflect.Type) bool {
+	return t.Implements(afterClonerType) || reflect.PointerTo(t).Implements(afterClonerType)
+}
+
 func hasCustomCloneType(t reflect.Type) bool {
 	_, ok := customCloneMethod(t, t)
 	return ok
@@ -231,20 +801,83 @@ func customCloneMethod(t, target reflect.Type) (reflect.Method, bool) {
 	}
 
 	methodType := method.Type
-	if methodType.NumIn() != 1 || methodType.NumOut() != 2 {
-		return reflect.Method{}, false
-	}
-	if methodType.Out(1) != errorType {
+	if methodType.NumIn() != 1 {
 		return reflect.Method{}, false
 	}
-	output := methodType.Out(0)
-	if output == target || output.AssignableTo(target) || output.ConvertibleTo(target) {
-		return method, true
+	switch methodType.NumOut() {
+	case 1:
+		// The standard library convention, as in (*tls.Config).Clone, returns
+		// the copy alone; it must match the type exactly.
+		output := methodType.Out(0)
+		if output == target || output.AssignableTo(target) {
+			return method, true
+		}
+	case 2:
+		if methodType.Out(1) != errorType {
+			return reflect.Method{}, false
+		}
+		output := methodType.Out(0)
+		if output == target || output.AssignableTo(target) || output.ConvertibleTo(target) {
+			return method, true
+		}
 	}
 	return reflect.Method{}, false
 }
 
-func customCloneValue(v reflect.Value, path string) (reflect.Value, bool, error) {
+// guard runs fn, which calls code outside the package. Under WithRecover a
+// panic in fn is returned as a PanicError for the value of type t at path;
+// otherwise it propagates.
+func (c *cloneContext) guard(path string, t reflect.Type, fn func() error) (err error) {
+	if c.opts.recoverPanics {
+		defer recoverPanic(path, t, &err)
+	}
+	return fn()
+}
+
+// recoverPanic stores a recovered panic in err. It must be deferred directly
+// so recover sees the panic.
+func recoverPanic(path string, t reflect.Type, err *error) {
+	if r := recover(); r != nil {
+		*err = panicError(path, t, r)
+	}
+}
+
+// customClone runs the Clone method of v like customCloneValue. A pointer or
+// map that is reached again resolves to the clone its Clone method returned
+// the first time, so references shared across the graph stay shared in the
+// clone.
+func (c *cloneContext) customClone(v reflect.Value, path string) (reflect.Value, bool, error) {
+	var kind visitKind
+	switch {
+	case v.Kind() == reflect.Pointer:
+		kind = visitPointer
+	case v.Kind() == reflect.Map && !v.IsNil():
+		kind = visitMap
+	default:
+		return c.customCloneValue(v, path)
+	}
+	key := visitKey{kind: kind, addr: v.Pointer(), typ: v.Type()}
+	if cloned, exists := c.visited[key]; exists {
+		c.revisit(key)
+		if kind == visitPointer {
+			// Pointers into a struct or array resolve to registered
+			// addresses and are only mapped once reached.
+			c.mapPointer(v, cloned)
+		}
+		return cloned, true, nil
+	}
+	cloned, ok, err := c.customCloneValue(v, path)
+	if ok && err == nil {
+		c.enter(key, cloned)
+		c.leave(key)
+		if kind == visitPointer {
+			c.mapPointer(v, cloned)
+		}
+	}
+	return cloned, ok, err
+}
+
+func (c *cloneContext) customCloneValue(v reflect.Value, path string) (reflect.Value, bool, error) {
 	if v.Kind() == reflect.Interface || !v.CanInterface() {
 		return reflect.Value{}, false, nil
 	}
@@ -252,8 +885,14 @@ func customCloneValue(v reflect.Value, path string) (reflect.Value, bool, error)
 		return reflect.Value{}, false, nil
 	}
 
-	results := v.MethodByName("Clone").Call(nil)
-	if !results[1].IsNil() {
+	var results []reflect.Value
+	if err := c.guard(path, v.Type(), func() error {
+		results = v.MethodByName("Clone").Call(nil)
+		return nil
+	}); err != nil {
+		return reflect.Value{}, true, err
+	}
+	if len(results) == 2 && !results[1].IsNil() {
 		return reflect.Value{}, true, results[1].Interface().(error)
 	}
 
@@ -269,64 +908,56 @@ func customCloneValue(v reflect.Value, path string) (reflect.Value, bool, error)
 // Clone preserves circular references when it uses reflection. Types that
 // implement Cloner[T] control their own cloning behavior.
 func Clone[T any](src T) (T, error) {
-	switch any(src).(type) {
-	case bool, int, int8, int16, int32, int64,
-		uint, uint8, uint16, uint32, uint64, uintptr,
-		float32, float64, complex64, complex128,
-		string:
-		return src, nil
+	return cloneWith(src, newOptions(nil))
+}
+
+func cloneWith[T any](src T, opts options) (T, error) {
+	if opts.beforeClone != nil || opts.cloneDone != nil {
+		return cloneTraced(src, opts)
 	}
 
-	switch s := any(src).(type) {
-	case []int:
-		return any(cloneSliceExact(s)).(T), nil
-	case []int8:
-		return any(cloneSliceExact(s)).(T), nil
-	case []int16:
-		return any(cloneSliceExact(s)).(T), nil
-	case []int32:
-		return any(cloneSliceExact(s)).(T), nil
-	case []int64:
-		return any(cloneSliceExact(s)).(T), nil
-	case []uint:
-		return any(cloneSliceExact(s)).(T), nil
-	case []byte:
-		return any(cloneSliceExact(s)).(T), nil
-	case []uint16:
-		return any(cloneSliceExact(s)).(T), nil
-	case []uint32:
-		return any(cloneSliceExact(s)).(T), nil
-	case []uint64:
-		return any(cloneSliceExact(s)).(T), nil
-	case []float32:
-		return any(cloneSliceExact(s)).(T), nil
-	case []float64:
-		return any(cloneSliceExact(s)).(T), nil
-	case []string:
-		return any(cloneSliceExact(s)).(T), nil
-	case []bool:
-		return any(cloneSliceExact(s)).(T), nil
+	if !opts.skipsFastPaths() {
+		switch any(src).(type) {
+		case bool, int, int8, int16, int32, int64,
+			uint, uint8, uint16, uint32, uint64, uintptr,
+			float32, float64, complex64, complex128,
+			string:
+			return src, nil
+		case [2]float32, [3]float32, [4]float32, [2]float64, [3]float64, [4]float64,
+			[2]int, [3]int, [4]int, [16]byte, [32]byte, [256]byte:
+			// Vectors, IDs, digests, and lookup tables are copied by assignment.
+			return src, nil
+		}
 	}
 
-	// map[string]any is excluded so reflection can preserve circular references.
-	switch m := any(src).(type) {
-	case map[string]int:
-		return any(maps.Clone(m)).(T), nil
-	case map[string]string:
-		return any(maps.Clone(m)).(T), nil
-	case map[string]float64:
-		return any(maps.Clone(m)).(T), nil
-	case map[string]bool:
-		return any(maps.Clone(m)).(T), nil
-	case map[int]int:
-		return any(maps.Clone(m)).(T), nil
-	case map[int]string:
-		return any(maps.Clone(m)).(T), nil
-	case map[int]bool:
-		return any(maps.Clone(m)).(T), nil
+	// Registered immutable slices, held directly or in an interface, must reach
+	// sharesType, which the fast paths skip.
+	fast := !opts.skipsFastPaths() && (immutableSlices.Load() == nil || !isImmutableSlice(reflect.TypeOf(src)))
+	if fast {
+		if cloned, ok := cloneFast(src); ok {
+			return cloned, nil
+		}
+		// Named string types such as json.Number are immutable like strings.
+		if t := reflect.TypeFor[T](); t.Kind() == reflect.String && isPlainType(t) {
+			return src, nil
+		}
+	}
+	if fast && layouts.Load() != nil && !opts.recoverPanics && opts.mapping == nil && len(opts.structuralTypes) == 0 && !isForcedStructural(reflect.TypeFor[T]()) {
+		if cloned, ok := cloneLayout(src); ok {
+			return cloned, nil
+		}
+	}
+
+	// src is boxed once from here on; each conversion of a T that is not
+	// pointer-shaped to an interface allocates.
+	boxed := any(src)
+	if fast {
+		if cloned, ok := cloneFastMap[T](boxed); ok {
+			return cloned, nil
+		}
 	}
 
-	v := reflect.ValueOf(src)
+	v := reflect.ValueOf(boxed)
 	if !v.IsValid() {
 		return src, nil
 	}
@@ -335,23 +966,135 @@ func Clone[T any](src T) (T, error) {
 		return src, nil
 	}
 
-	if cloner, ok := any(src).(Cloner[T]); ok {
+	if cloner, ok := boxed.(Cloner[T]); ok && len(opts.shareTypes) == 0 && opts.sharePredicate == nil && len(opts.structuralTypes) == 0 && !isForcedStructural(v.Type()) && !opts.recoverPanics && opts.mapping == nil && !isTemplateType(v.Type()) {
 		return cloner.Clone()
 	}
 
-	ctx := newCloneContext()
+	if t := reflect.TypeFor[T](); t.Kind() == reflect.Interface && sharesInterface(t, v.Type()) {
+		if opts.shared != nil {
+			*opts.shared = true
+		}
+		return src, nil
+	}
+
+	if v.Kind() == reflect.Slice && fast && isPlainType(v.Type().Elem()) && !hasCustomCloneType(v.Type()) && !(opts.afterClone && hasAfterCloneType(v.Type())) {
+		// Named byte slices and other reference-free slices need no context.
+		if v.IsNil() {
+			return src, nil
+		}
+		cloned := reflect.MakeSlice(v.Type(), v.Len(), v.Cap())
+		reflect.Copy(cloned, v)
+		return cloned.Interface().(T), nil
+	}
+
+	ctx := newCloneContext(opts)
+	if opts.preserveBacking {
+		ctx.scanBackingArrays(v)
+	}
 	cloned, err := ctx.cloneValue(v, "$")
 	if err != nil {
 		var zero T
 		return zero, err
 	}
 	if cloned.IsValid() {
-		return cloned.Interface().(T), nil
+		return valueAs[T](cloned), nil
 	}
 
 	return src, nil
 }
 
+// valueAs returns the T held by v. An addressable clone, such as a struct or
+// array built by the engine, is read through a *T, which copies it once instead
+// of boxing a copy into an interface before the type assertion copies it again.
+func valueAs[T any](v reflect.Value) T {
+	if v.CanAddr() && v.CanInterface() {
+		if ptr, ok := v.Addr().Interface().(*T); ok {
+			return *ptr
+		}
+	}
+	return v.Interface().(T)
+}
+
+// SharesByDefault reports whether Clone, with no options, shares a value of
+// type t with the source instead of cloning it. held is the interface type the
+// value is held in, or nil. Immutable types and slices registered by
+// RegisterImmutableSlice without a Clone method, OS handles such as *os.File,
+// parsed templates, and reflect.Type values are shared, as are values held in
+// error and context.Context interfaces unless their type has a Clone method.
+// Test helpers use it to tell sharing by design from aliasing bugs.
+func SharesByDefault(t, held reflect.Type) bool {
+	if t == nil {
+		return false
+	}
+	if held != nil && held.Kind() == reflect.Interface && sharesInterface(held, t) {
+		return true
+	}
+	c := cloneContext{}
+	return t.Implements(reflectTypeType) || c.sharesType(t)
+}
+
+// CloneDisjoint returns a deep copy of src and reports whether the copy is
+// disjoint from src, meaning it shares no reachable pointer, slice, map,
+// interface, or other reference with it.
+//
+// Sharing comes from fields tagged clone:"shallow", Immutable values, slices
+// registered by RegisterImmutableSlice, parsed templates, values held in error
+// or context.Context interfaces, and unexported value-like fields whose copies
+// carry references.
+// Custom Clone methods are trusted to return disjoint values.
+func CloneDisjoint[T any](src T) (T, bool, error) {
+	var shared bool
+	opts := newOptions(nil)
+	opts.shared = &shared
+	cloned, err := cloneWith(src, opts)
+	if err != nil {
+		var zero T
+		return zero, false, err
+	}
+	return cloned, !shared, nil
+}
+
+// CloneWithMapping returns a deep copy of src and a map from each pointer the
+// clone reached in src to the pointer that replaced it, so callers can point
+// references held outside the graph at the clone.
+//
+// Keys are the original pointers, such as a *Node, and values are their
+// clones of the same type; a pointer to a struct and a pointer to its first
+// field are distinct keys. The map holds pointers found in the graph,
+// including pointers into structs and arrays, but not maps or slices, which
+// cannot be map keys. Pointers that were shared rather than cloned, such as
+// Immutable values, are absent, and a pointer cloned by its Clone method maps
+// to the pointer that method returned.
+func CloneWithMapping[T any](src T) (T, map[any]any, error) {
+	mapping := make(map[any]any)
+	opts := newOptions(nil)
+	opts.mapping = mapping
+	cloned, err := cloneWith(src, opts)
+	if err != nil {
+		var zero T
+		return zero, nil, err
+	}
+	return cloned, mapping, nil
+}
+
+// CloneInterfaceAs deep-clones the value held in v when it is a T and reports
+// whether it was. A mismatch returns ok false without cloning, where Clone
+// followed by a type assertion would panic. T may be an interface type, in
+// which case the concrete value is cloned by the usual rules, including its
+// Clone method.
+func CloneInterfaceAs[T any](v any) (T, bool, error) {
+	var zero T
+	typed, ok := v.(T)
+	if !ok {
+		return zero, false, nil
+	}
+	cloned, err := Clone(typed)
+	if err != nil {
+		return zero, true, err
+	}
+	return cloned, true, nil
+}
+
 // MustClone returns a deep copy of src or panics if src cannot be cloned.
 func MustClone[T any](src T) T {
 	cloned, err := Clone(src)
@@ -361,6 +1104,108 @@ func MustClone[T any](src T) T {
 	return cloned
 }
 
+// CloneOrDefault returns a deep copy of src, or the zero value of T if src
+// cannot be cloned. Best-effort callers, such as caches that treat a failed
+// clone as a miss, get nothing rather than a partial copy; use Clone to learn
+// why a clone failed.
+func CloneOrDefault[T any](src T) T {
+	cloned, err := Clone(src)
+	if err != nil {
+		var zero T
+		return zero
+	}
+	return cloned
+}
+
+// CloneValidated returns a deep copy of src after validate accepts it. If src
+// cannot be cloned or validate returns an error, the clone is discarded and
+// the zero value is returned with that error, so a snapshot that breaks its
+// invariants is never handed out. validate runs on the clone, not on src, and
+// a nil validate accepts every clone.
+func CloneValidated[T any](src T, validate func(T) error) (T, error) {
+	var zero T
+	cloned, err := Clone(src)
+	if err != nil {
+		return zero, err
+	}
+	if validate != nil {
+		if err := validate(cloned); err != nil {
+			return zero, err
+		}
+	}
+	return cloned, nil
+}
+
+// cloneFast clones common scalar slices without reflection.
+func cloneFast[T any](src T) (T, bool) {
+	switch s := any(src).(type) {
+	case []int:
+		return any(cloneSliceExact(s)).(T), true
+	case []int8:
+		return any(cloneSliceExact(s)).(T), true
+	case []int16:
+		return any(cloneSliceExact(s)).(T), true
+	case []int32:
+		return any(cloneSliceExact(s)).(T), true
+	case []int64:
+		return any(cloneSliceExact(s)).(T), true
+	case []uint:
+		return any(cloneSliceExact(s)).(T), true
+	case []byte:
+		return any(cloneSliceExact(s)).(T), true
+	case []uint16:
+		return any(cloneSliceExact(s)).(T), true
+	case []uint32:
+		return any(cloneSliceExact(s)).(T), true
+	case []uint64:
+		return any(cloneSliceExact(s)).(T), true
+	case []float32:
+		return any(cloneSliceExact(s)).(T), true
+	case []float64:
+		return any(cloneSliceExact(s)).(T), true
+	case []string:
+		return any(cloneSliceExact(s)).(T), true
+	case []bool:
+		return any(cloneSliceExact(s)).(T), true
+	case []json.Number:
+		return any(cloneSliceExact(s)).(T), true
+	}
+
+	var zero T
+	return zero, false
+}
+
+// cloneFastMap clones common scalar maps without reflection. src holds a T
+// already boxed by cloneWith, so the type switch does not box it again.
+func cloneFastMap[T any](src any) (T, bool) {
+	// map[string]any is excluded so reflection can preserve circular references.
+	switch m := src.(type) {
+	case map[string]int:
+		return any(maps.Clone(m)).(T), true
+	case map[string]string:
+		return any(maps.Clone(m)).(T), true
+	case map[string]float64:
+		return any(maps.Clone(m)).(T), true
+	case map[string]bool:
+		return any(maps.Clone(m)).(T), true
+	case map[int]int:
+		return any(maps.Clone(m)).(T), true
+	case map[int]string:
+		return any(maps.Clone(m)).(T), true
+	case map[int]bool:
+		return any(maps.Clone(m)).(T), true
+	case map[string]struct{}:
+		return any(maps.Clone(m)).(T), true
+	case map[string]json.Number:
+		return any(maps.Clone(m)).(T), true
+	case map[string][]string:
+		return any(cloneStringSlices(m)).(T), true
+	}
+
+	var zero T
+	return zero, false
+}
+
 func (c *cloneContext) cloneValue(v reflect.Value, path string) (reflect.Value, error) {
 	if !v.IsValid() {
 		return reflect.Value{}, nil
@@ -368,12 +1213,45 @@ func (c *cloneContext) cloneValue(v reflect.Value, path string) (reflect.Value,
 	if v.Kind() == reflect.Pointer && v.IsNil() {
 		return v, nil
 	}
-	if cloned, ok, err := customCloneValue(v, path); ok || err != nil {
+	if c.sharesType(v.Type()) {
+		return v, c.shareValue(v, path)
+	}
+	if !c.forcesStructural(v.Type()) {
+		if cloned, ok, err := c.customClone(v, path); ok || err != nil {
+			return cloned, err
+		}
+	}
+	if fn, ok := c.opts.cloneFuncs[v.Type()]; ok && v.CanInterface() {
+		var cloned reflect.Value
+		err := c.guard(path, v.Type(), func() (err error) {
+			cloned, err = fn(v)
+			return err
+		})
 		return cloned, err
 	}
+	if copier, ok := layoutCopier(v.Type()); ok && v.CanInterface() {
+		cloned := reflect.New(v.Type()).Elem()
+		return cloned, c.copyLayoutInto(cloned, v, copier, path)
+	}
+	if cloned, ok := c.cloneBuffer(v); ok {
+		return cloned, nil
+	}
+	if cloned, ok := clonePool(v); ok {
+		return cloned, nil
+	}
+	if c.ignoresUnsupported(v, path) {
+		return reflect.Zero(v.Type()), nil
+	}
 	if err := unsupportedValue(v, path); err != nil {
 		return reflect.Value{}, err
 	}
+	if c.opts.maxDepth > 0 {
+		if c.depth >= c.opts.maxDepth {
+			return reflect.Value{}, limitError(path, v.Type(), "depth", c.opts.maxDepth, c.depth+1)
+		}
+		c.depth++
+		defer func() { c.depth-- }()
+	}
 
 	switch v.Kind() {
 	case reflect.Pointer:
@@ -408,19 +1286,36 @@ func (c *cloneContext) clonePointer(v reflect.Value, path string) (reflect.Value
 	if v.IsNil() {
 		return v, nil
 	}
+	if v.Type() == syncMapPointerType {
+		return c.cloneSyncMap(v, path)
+	}
 
 	key := visitKey{kind: visitPointer, addr: v.Pointer(), typ: v.Type()}
 	if cloned, exists := c.visited[key]; exists {
+		c.revisit(key)
+		c.mapPointer(v, cloned)
 		return cloned, nil
 	}
 
-	clonedPtr := reflect.New(v.Type().Elem())
+	clonedPtr, err := c.newValue(v.Type().Elem(), path)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	c.count(visitPointer)
 
 	// Register before recursing to handle self-referencing structures.
-	c.visited[key] = clonedPtr
+	c.enter(key, clonedPtr)
+	defer c.leave(key)
+	c.mapPointer(v, clonedPtr)
 
 	elemValue := v.Elem()
-	if elemValue.Kind() == reflect.Struct {
+	if copier, ok := c.layoutFor(elemValue.Type()); ok && elemValue.CanInterface() {
+		if err := c.copyLayoutInto(clonedPtr.Elem(), elemValue, copier, path); err != nil {
+			return reflect.Value{}, err
+		}
+		return clonedPtr, nil
+	}
+	if _, ok := c.opts.cloneFuncs[elemValue.Type()]; elemValue.Kind() == reflect.Struct && !ok {
 		clonedPtr.Elem().Set(elemValue)
 		if err := c.cloneStructInto(elemValue, clonedPtr.Elem(), path); err != nil {
 			return reflect.Value{}, err
@@ -439,10 +1334,94 @@ func (c *cloneContext) clonePointer(v reflect.Value, path string) (reflect.Value
 	return clonedPtr, nil
 }
 
+// clonePool returns a new, empty sync.Pool with the New function of v, a
+// sync.Pool held by value. Pooled items are only a cache, so the clone starts
+// without them.
+func clonePool(v reflect.Value) (reflect.Value, bool) {
+	if v.Type() != syncPoolType || !v.CanInterface() {
+		return reflect.Value{}, false
+	}
+	cloned := reflect.New(syncPoolType).Elem()
+	cloned.FieldByName("New").Set(v.FieldByName("New"))
+	return cloned, true
+}
+
+// cloneSyncMap clones a *sync.Map into a new sync.Map holding clones of its
+// keys and values. The source is read with Range, so entries stored or deleted
+// while it is cloned may or may not be seen, as with any Range call.
+func (c *cloneContext) cloneSyncMap(v reflect.Value, path string) (reflect.Value, error) {
+	key := visitKey{kind: visitPointer, addr: v.Pointer(), typ: v.Type()}
+	if cloned, exists := c.visited[key]; exists {
+		c.revisit(key)
+		return cloned, nil
+	}
+
+	clonedPtr, err := c.newValue(v.Type().Elem(), path)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	c.count(visitPointer)
+	c.enter(key, clonedPtr)
+	defer c.leave(key)
+	c.mapPointer(v, clonedPtr)
+
+	cloned := clonedPtr.Interface().(*sync.Map)
+	v.Interface().(*sync.Map).Range(func(srcKey, srcValue any) bool {
+		entryPath := mapValuePath(path, reflect.ValueOf(srcKey))
+		var clonedKey, clonedValue any
+		if clonedKey, err = c.cloneAny(srcKey, entryPath); err != nil {
+			return false
+		}
+		if clonedValue, err = c.cloneAny(srcValue, entryPath); err != nil {
+			return false
+		}
+		if _, loaded := cloned.LoadOrStore(clonedKey, clonedValue); loaded {
+			err = unsupportedError(entryPath, reflect.TypeOf(srcKey), "cloned map key collides with another cloned key")
+			return false
+		}
+		return true
+	})
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	return clonedPtr, nil
+}
+
+// cloneAny clones a value held in an interface, keeping the interface type so
+// nil and shared values behave as they do in an []any.
+func (c *cloneContext) cloneAny(v any, path string) (any, error) {
+	var cloned any
+	err := c.cloneElementInto(reflect.ValueOf(&v).Elem(), reflect.ValueOf(&cloned).Elem(), path)
+	return cloned, err
+}
+
 func (c *cloneContext) cloneSlice(v reflect.Value, path string) (reflect.Value, error) {
 	if v.IsNil() {
+		if c.opts.emptyCollections == nilToEmpty {
+			return c.makeSlice(v.Type(), 0, 0, path)
+		}
 		return v, nil
 	}
+	if v.Len() == 0 && c.opts.emptyCollections == emptyToNil {
+		return reflect.Zero(v.Type()), nil
+	}
+	if err := c.checkCollectionLen(v, path); err != nil {
+		return reflect.Value{}, err
+	}
+	// Windows share one cloned array, so they have no clone of their own to
+	// pass to an AfterClone hook.
+	if c.backing != nil && !c.runsAfterClone(v.Type()) {
+		if cloned, ok, err := c.cloneSliceWindow(v, path); ok || err != nil {
+			return cloned, err
+		}
+	}
+	if c.dynamic && v.Type() == dynamicSliceType && v.CanInterface() {
+		cloned, err := c.cloneDynamicSlice(v.Interface().([]any), &dynamicPath{base: path})
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(cloned), nil
+	}
 
 	needsTracking := sliceCanContainCycles(v.Type().Elem().Kind())
 	addr := uintptr(0)
@@ -452,17 +1431,54 @@ func (c *cloneContext) cloneSlice(v reflect.Value, path string) (reflect.Value,
 		key := visitKey{kind: visitSlice, addr: addr, typ: v.Type()}
 		cloned, exists := c.visited[key]
 		if exists && cloned.Len() == v.Len() && cloned.Cap() == v.Cap() {
+			c.revisit(key)
 			return cloned, nil
 		}
 	}
 
-	clonedSlice := reflect.MakeSlice(v.Type(), v.Len(), v.Cap())
+	clonedSlice, err := c.makeSlice(v.Type(), v.Len(), v.Cap(), path)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	c.count(visitSlice)
 
 	if needsTracking {
-		c.visited[visitKey{kind: visitSlice, addr: addr, typ: v.Type()}] = clonedSlice
+		key := visitKey{kind: visitSlice, addr: addr, typ: v.Type()}
+		c.enter(key, clonedSlice)
+		defer c.leave(key)
+	}
+
+	if c.copiesPlain(v.Type().Elem()) {
+		// Named byte slices and slices of plain structs copy in bulk, unless
+		// a clone func replaces their elements.
+		reflect.Copy(clonedSlice, v)
+		return c.afterCloneCollection(clonedSlice), nil
+	}
+	if c.batchesStructPointers(v.Type().Elem()) {
+		if err := c.cloneStructPointersInto(v, clonedSlice, path); err != nil {
+			return reflect.Value{}, err
+		}
+		return c.afterCloneCollection(clonedSlice), nil
+	}
+
+	if kind := v.Type().Elem().Kind(); kind == reflect.Struct || kind == reflect.Array {
+		// Structs and arrays are cloned in place over a shallow copy, like
+		// array elements, so no temporary is allocated per element and
+		// pointers to their fields resolve into the cloned slice.
+		reflect.Copy(clonedSlice, v)
+		for i := range v.Len() {
+			if err := c.cloneElementInto(v.Index(i), clonedSlice.Index(i), indexPath(path, i)); err != nil {
+				return reflect.Value{}, err
+			}
+		}
+		return c.afterCloneCollection(clonedSlice), nil
 	}
 
 	for i := range v.Len() {
+		if isNilPointer(v.Index(i)) {
+			// The new slice already holds nil.
+			continue
+		}
 		elem, err := c.cloneValue(v.Index(i), indexPath(path, i))
 		if err != nil {
 			return reflect.Value{}, err
@@ -472,58 +1488,280 @@ func (c *cloneContext) cloneSlice(v reflect.Value, path string) (reflect.Value,
 		}
 	}
 
-	return clonedSlice, nil
+	return c.afterCloneCollection(clonedSlice), nil
+}
+
+// batchesStructPointers reports whether elements of type elemType can be
+// cloned by cloneStructPointersInto with the same result as clonePointer.
+func (c *cloneContext) batchesStructPointers(elemType reflect.Type) bool {
+	if elemType.Kind() != reflect.Pointer || elemType.Elem().Kind() != reflect.Struct {
+		return false
+	}
+	if c.opts.allocator != nil || c.opts.maxDepth > 0 || c.opts.forceReflection {
+		return false
+	}
+	structType := elemType.Elem()
+	if _, ok := unsupportedTypes[structType]; ok || isHandleType(elemType) {
+		return false
+	}
+	if c.sharesType(elemType) || c.sharesType(structType) {
+		return false
+	}
+	return !c.hasCustomClone(elemType) && !c.hasCustomClone(structType)
+}
+
+// cloneStructPointersInto clones a []*T of struct pointers into clonedSlice and
+// allocates the new structs in one backing array instead of one at a time.
+// Shared and cyclic pointers still resolve through visited. The batch stays
+// alive while any of its structs is reachable.
+func (c *cloneContext) cloneStructPointersInto(v, clonedSlice reflect.Value, path string) error {
+	info := structInfo(v.Type().Elem().Elem())
+	var batch reflect.Value
+	next := 0
+	for i := range v.Len() {
+		elem := v.Index(i)
+		if elem.IsNil() {
+			continue
+		}
+
+		key := visitKey{kind: visitPointer, addr: elem.Pointer(), typ: elem.Type()}
+		if cloned, exists := c.visited[key]; exists {
+			c.revisit(key)
+			c.mapPointer(elem, cloned)
+			clonedSlice.Index(i).Set(cloned)
+			continue
+		}
+
+		if !batch.IsValid() {
+			remaining := v.Len() - i
+			batch = reflect.MakeSlice(reflect.SliceOf(elem.Type().Elem()), remaining, remaining)
+		}
+		clonedPtr := batch.Index(next).Addr()
+		next++
+		c.count(visitPointer)
+
+		// Plain structs cannot fail, so their paths are never built.
+		var elemPath string
+		if !info.plain {
+			elemPath = indexPath(path, i)
+		}
+
+		c.enter(key, clonedPtr)
+		c.mapPointer(elem, clonedPtr)
+		clonedPtr.Elem().Set(elem.Elem())
+		err := c.cloneStructInto(elem.Elem(), clonedPtr.Elem(), elemPath)
+		c.leave(key)
+		if err != nil {
+			return err
+		}
+		clonedSlice.Index(i).Set(clonedPtr)
+	}
+	return nil
 }
 
 func (c *cloneContext) cloneMap(v reflect.Value, path string) (reflect.Value, error) {
 	if v.IsNil() {
+		if c.opts.emptyCollections == nilToEmpty {
+			return reflect.MakeMap(v.Type()), nil
+		}
 		return v, nil
 	}
+	if v.Len() == 0 && c.opts.emptyCollections == emptyToNil {
+		return reflect.Zero(v.Type()), nil
+	}
+	if c.dynamic && v.Type() == dynamicMapType && v.CanInterface() {
+		cloned, err := c.cloneDynamicMap(v.Interface().(map[string]any), &dynamicPath{base: path})
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(cloned), nil
+	}
 
 	key := visitKey{kind: visitMap, addr: v.Pointer(), typ: v.Type()}
 	if cloned, exists := c.visited[key]; exists {
+		c.revisit(key)
 		return cloned, nil
 	}
+	if err := c.checkCollectionLen(v, path); err != nil {
+		return reflect.Value{}, err
+	}
+
+	if v.Type().ConvertibleTo(stringSlicesType) && v.CanInterface() && !c.opts.skipsFastPaths() {
+		// Header maps, including named types such as http.Header, skip reflection.
+		cloned := reflect.ValueOf(cloneStringSlices(v.Convert(stringSlicesType).Interface().(map[string][]string)))
+		clonedMap := cloned.Convert(v.Type())
+		c.count(visitMap)
+		c.enter(key, clonedMap)
+		c.leave(key)
+		return c.afterCloneCollection(clonedMap), nil
+	}
+	if v.CanInterface() && !c.opts.skipsFastPaths() {
+		// Scalar maps held in fields, such as the data next to a mutex in a
+		// store, are copied by maps.Clone like top-level ones.
+		if cloned, ok := cloneFastMap[any](v.Interface()); ok {
+			clonedMap := reflect.ValueOf(cloned)
+			c.count(visitMap)
+			c.enter(key, clonedMap)
+			c.leave(key)
+			return clonedMap, nil
+		}
+	}
 
+	// Presize so large maps are not rehashed while entries are added.
 	clonedMap := reflect.MakeMapWithSize(v.Type(), v.Len())
-	c.visited[key] = clonedMap
+	c.count(visitMap)
+	c.enter(key, clonedMap)
+	defer c.leave(key)
 
+	if err := c.cloneMapInto(v, clonedMap, path); err != nil {
+		return reflect.Value{}, err
+	}
+	return c.afterCloneCollection(clonedMap), nil
+}
+
+func (c *cloneContext) cloneMapInto(v, clonedMap reflect.Value, path string) error {
 	keyType := v.Type().Key()
 	elemType := v.Type().Elem()
+	plainKeys := c.copiesPlain(keyType)
 	iter := v.MapRange()
-	for iter.Next() {
-		srcKey := iter.Key()
-		srcValue := iter.Value()
+	if plainKeys && c.copiesPlain(elemType) {
+		// Reference-free entries, such as digest keys, are copied through two
+		// reused values instead of being cloned one by one.
+		key := reflect.New(keyType).Elem()
+		value := reflect.New(elemType).Elem()
+		for iter.Next() {
+			key.SetIterKey(iter)
+			value.SetIterValue(iter)
+			clonedMap.SetMapIndex(key, value)
+		}
+		return nil
+	}
 
-		value, err := c.cloneValue(srcValue, mapValuePath(path, srcKey))
-		if err != nil {
-			return reflect.Value{}, err
+	if c.opts.deterministicOrder && isOrderedKind(keyType.Kind()) {
+		for _, entry := range sortedMapEntries(v) {
+			if err := c.cloneMapEntry(v, clonedMap, entry.key, entry.value, plainKeys, path); err != nil {
+				return err
+			}
 		}
-		key, err := c.cloneValue(srcKey, mapKeyPath(path, srcKey))
-		if err != nil {
-			return reflect.Value{}, err
+		return nil
+	}
+	if plainKeys {
+		// Plain keys are only copied, so one reused value holds each in turn
+		// instead of a new copy per entry.
+		key := reflect.New(keyType).Elem()
+		for iter.Next() {
+			key.SetIterKey(iter)
+			if err := c.cloneMapEntry(v, clonedMap, key, iter.Value(), plainKeys, path); err != nil {
+				return err
+			}
 		}
-
-		if !key.IsValid() || !value.IsValid() {
-			return reflect.Value{}, unsupportedError(path, v.Type(), "map key or value cloned to an invalid value")
+		return nil
+	}
+	for iter.Next() {
+		if err := c.cloneMapEntry(v, clonedMap, iter.Key(), iter.Value(), plainKeys, path); err != nil {
+			return err
 		}
+	}
+	return nil
+}
 
-		key, ok := assignableClone(key, keyType)
-		if !ok {
-			return reflect.Value{}, unsupportedError(mapKeyPath(path, srcKey), srcKey.Type(), "cloned map key is not assignable to the map key type")
-		}
+// cloneMapEntry clones one entry of the map v into clonedMap.
+func (c *cloneContext) cloneMapEntry(v, clonedMap, srcKey, srcValue reflect.Value, plainKeys bool, path string) error {
+	keyType := v.Type().Key()
+	elemType := v.Type().Elem()
 
-		value, ok = assignableClone(value, elemType)
+	value := srcValue
+	if !isNilPointer(srcValue) {
+		var err error
+		if value, err = c.cloneValue(srcValue, mapValuePath(path, srcKey)); err != nil {
+			return err
+		}
+	}
+	if plainKeys {
+		// A plain key is its own clone and cannot collide with another key.
+		if !value.IsValid() {
+			return unsupportedError(path, v.Type(), "map key or value cloned to an invalid value")
+		}
+		value, ok := assignableClone(value, elemType)
 		if !ok {
-			return reflect.Value{}, unsupportedError(mapValuePath(path, srcKey), srcValue.Type(), "cloned map value is not assignable to the map value type")
+			return unsupportedError(mapValuePath(path, srcKey), srcValue.Type(), "cloned map value is not assignable to the map value type")
 		}
-		clonedMap.SetMapIndex(key, value)
+		clonedMap.SetMapIndex(srcKey, value)
+		return nil
+	}
+	key, err := c.cloneValue(srcKey, mapKeyPath(path, srcKey))
+	if err != nil {
+		return err
+	}
+
+	if !key.IsValid() || !value.IsValid() {
+		return unsupportedError(path, v.Type(), "map key or value cloned to an invalid value")
+	}
+
+	key, ok := assignableClone(key, keyType)
+	if !ok {
+		return unsupportedError(mapKeyPath(path, srcKey), srcKey.Type(), "cloned map key is not assignable to the map key type")
+	}
+
+	value, ok = assignableClone(value, elemType)
+	if !ok {
+		return unsupportedError(mapValuePath(path, srcKey), srcValue.Type(), "cloned map value is not assignable to the map value type")
+	}
+
+	// A key whose clone equals an earlier cloned key would silently drop an entry.
+	size := clonedMap.Len()
+	clonedMap.SetMapIndex(key, value)
+	if clonedMap.Len() == size {
+		return unsupportedError(mapKeyPath(path, srcKey), srcKey.Type(), "cloned map key collides with another cloned key")
+	}
+	return nil
+}
+
+type mapEntry struct {
+	key, value reflect.Value
+}
+
+// isOrderedKind reports whether keys of kind have a natural order.
+func isOrderedKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.String:
+		return true
+	default:
+		return false
 	}
+}
 
-	return clonedMap, nil
+// sortedMapEntries returns the entries of v, whose key kind is ordered, in
+// ascending key order. Entries are collected while iterating rather than looked
+// up by key, so NaN keys are kept; they sort first.
+func sortedMapEntries(v reflect.Value) []mapEntry {
+	entries := make([]mapEntry, 0, v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		entries = append(entries, mapEntry{key: iter.Key(), value: iter.Value()})
+	}
+
+	var compare func(x, y reflect.Value) int
+	switch v.Type().Key().Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		compare = func(x, y reflect.Value) int { return cmp.Compare(x.Int(), y.Int()) }
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		compare = func(x, y reflect.Value) int { return cmp.Compare(x.Uint(), y.Uint()) }
+	case reflect.Float32, reflect.Float64:
+		compare = func(x, y reflect.Value) int { return cmp.Compare(x.Float(), y.Float()) }
+	default:
+		compare = func(x, y reflect.Value) int { return cmp.Compare(x.String(), y.String()) }
+	}
+	slices.SortStableFunc(entries, func(x, y mapEntry) int { return compare(x.key, y.key) })
+	return entries
 }
 
 func (c *cloneContext) cloneStruct(v reflect.Value, path string) (reflect.Value, error) {
+	if v.NumField() == 0 {
+		return v, nil
+	}
 	clonedStruct := reflect.New(v.Type()).Elem()
 	clonedStruct.Set(v)
 	if err := c.cloneStructInto(v, clonedStruct, path); err != nil {
@@ -533,6 +1771,9 @@ func (c *cloneContext) cloneStruct(v reflect.Value, path string) (reflect.Value,
 }
 
 func (c *cloneContext) registerAddress(src, dst reflect.Value) {
+	if c.opts.expandShared {
+		return
+	}
 	if src.CanAddr() && dst.CanAddr() {
 		addr := src.Addr()
 		c.visited[visitKey{kind: visitPointer, addr: addr.Pointer(), typ: addr.Type()}] = dst.Addr()
@@ -540,6 +1781,9 @@ func (c *cloneContext) registerAddress(src, dst reflect.Value) {
 }
 
 func (c *cloneContext) registerArrayElements(v, clonedArray reflect.Value) {
+	if c.opts.expandShared || !v.CanAddr() || !clonedArray.CanAddr() {
+		return
+	}
 	for i := range v.Len() {
 		src := v.Index(i)
 		dst := clonedArray.Index(i)
@@ -547,7 +1791,7 @@ func (c *cloneContext) registerArrayElements(v, clonedArray reflect.Value) {
 
 		switch src.Kind() {
 		case reflect.Struct:
-			if !hasCustomCloneType(src.Type()) {
+			if !c.hasCustomClone(src.Type()) {
 				c.registerStructFields(src, dst)
 			}
 		case reflect.Array:
@@ -558,19 +1802,22 @@ func (c *cloneContext) registerArrayElements(v, clonedArray reflect.Value) {
 }
 
 func (c *cloneContext) registerStructFields(v, clonedStruct reflect.Value) {
-	for i := range v.NumField() {
-		fieldInfo := v.Type().Field(i)
-		if !fieldInfo.IsExported() {
+	if c.opts.expandShared || !v.CanAddr() || !clonedStruct.CanAddr() {
+		return
+	}
+
+	for _, field := range structInfo(v.Type()).fields {
+		if !field.exported {
 			continue
 		}
 
-		src := v.Field(i)
-		dst := clonedStruct.Field(i)
+		src := v.Field(field.index)
+		dst := clonedStruct.Field(field.index)
 		c.registerAddress(src, dst)
 
 		switch src.Kind() {
 		case reflect.Struct:
-			if !hasCustomCloneType(src.Type()) {
+			if !c.hasCustomClone(src.Type()) {
 				c.registerStructFields(src, dst)
 			}
 		case reflect.Array:
@@ -582,29 +1829,98 @@ func (c *cloneContext) registerStructFields(v, clonedStruct reflect.Value) {
 
 func (c *cloneContext) cloneStructInto(v, clonedStruct reflect.Value, path string) error {
 	info := structInfo(v.Type())
+	if info.atomicPointer {
+		return c.cloneAtomicPointerInto(clonedStruct, path)
+	}
 	c.registerStructFields(v, clonedStruct)
+	if info.plain || info.value {
+		// The shallow copy made by the caller is already a deep clone.
+		c.afterClone(info, clonedStruct)
+		return nil
+	}
+	if c.opts.jsonFallback && info.jsonRoundTrip && clonedStruct.CanAddr() && v.CanInterface() {
+		if err := cloneJSONInto(v, clonedStruct, path); err != nil {
+			return err
+		}
+		if err := c.transformFields(info, clonedStruct, path); err != nil {
+			return err
+		}
+		c.afterClone(info, clonedStruct)
+		return nil
+	}
+	if c.opts.rejectOpaque && info.opaque {
+		return unsupportedError(path, v.Type(), "opaque structs hold only unexported state; implement Cloner[T] or use WithCloneFunc")
+	}
 
-	for _, field := range info.fields {
+	for _, field := range info.walked {
 		src := v.Field(field.index)
+		if field.action != zeroField && isNilPointer(src) {
+			continue
+		}
 		dst := clonedStruct.Field(field.index)
 		fieldNamePath := fieldPath(path, field.name)
+
+		action := field.action
+		if action != zeroField && c.sharesType(src.Type()) {
+			action = shareField
+		}
+		if action == cloneField && c.sharesField(v.Type(), field.name) {
+			action = shareField
+		}
+
+		switch action {
+		case shareField:
+			if err := c.shareValue(src, fieldNamePath); err != nil {
+				return err
+			}
+			if dst.CanSet() {
+				dst.Set(src)
+			}
+			continue
+		case zeroField:
+			if !field.exported {
+				return unsupportedError(fieldNamePath, src.Type(), "unexported fields cannot be zeroed")
+			}
+			dst.SetZero()
+			continue
+		case copyField, cloneField:
+		}
+
+		if c.ignoresUnsupported(src, fieldNamePath) {
+			if field.exported {
+				dst.SetZero()
+			} else {
+				// Unexported fields keep the shallow copy of the value.
+				c.markShared()
+			}
+			continue
+		}
 		if field.exported {
-			if err := unsupportedValue(src, fieldNamePath); err != nil {
+			// Custom clones, such as a WithCloneFunc for a channel type, run
+			// in cloneValue before its own unsupported check.
+			if err := unsupportedValue(src, fieldNamePath); err != nil && !c.hasCustomClone(src.Type()) {
 				return err
 			}
 		} else {
 			if err := unsupportedUnexportedField(src, fieldNamePath); err != nil {
 				return err
 			}
+			if field.cow && holdsCOWEntries(src) {
+				return unsupportedError(fieldNamePath, src.Type(), "unexported COWMap fields cannot record the clone as an owner; export the field or implement Cloner[T]")
+			}
 		}
 
-		switch field.action {
+		switch action {
+		case shareField, zeroField:
 		case copyField:
+			if c.opts.shared != nil && !field.exported && holdsReferences(src) {
+				c.markShared()
+			}
 			if dst.CanSet() {
 				dst.Set(src)
 			}
 		case cloneField:
-			if src.Kind() == reflect.Struct && dst.CanSet() && !hasCustomCloneType(src.Type()) {
+			if src.Kind() == reflect.Struct && dst.CanSet() && !c.hasCustomClone(src.Type()) {
 				if err := c.cloneStructInto(src, dst, fieldNamePath); err != nil {
 					return err
 				}
@@ -630,11 +1946,73 @@ func (c *cloneContext) cloneStructInto(v, clonedStruct reflect.Value, path strin
 			}
 		}
 	}
+	if err := c.transformFields(info, clonedStruct, path); err != nil {
+		return err
+	}
+	c.afterClone(info, clonedStruct)
+	return nil
+}
+
+// cloneAtomicPointerInto replaces the pointer in clonedStruct, a shallow copy
+// of an atomic.Pointer, with a clone of its pointee. The pointee goes through
+// cloneValue, so it is shared or deduplicated like any other pointer.
+func (c *cloneContext) cloneAtomicPointerInto(clonedStruct reflect.Value, path string) error {
+	if !clonedStruct.CanAddr() || !clonedStruct.CanInterface() {
+		return unsupportedError(path, clonedStruct.Type(), "unexported atomic.Pointer fields cannot be cloned")
+	}
+	loaded := loadAtomicPointer(clonedStruct)
+	cloned, err := c.cloneValue(loaded, path)
+	if err != nil {
+		return err
+	}
+	if !cloned.IsValid() {
+		cloned = reflect.Zero(loaded.Type())
+	}
+	clonedStruct.Addr().MethodByName("Store").Call([]reflect.Value{cloned})
+	return nil
+}
+
+// transformFields replaces the fields of clonedStruct that name a transform
+// with the transform's result.
+func (c *cloneContext) transformFields(info *structTypeInfo, clonedStruct reflect.Value, path string) error {
+	if !info.transformed {
+		return nil
+	}
+	for _, field := range info.walked {
+		if field.transform == "" {
+			continue
+		}
+		dst := clonedStruct.Field(field.index)
+		fieldNamePath := fieldPath(path, field.name)
+		if !field.exported || !dst.CanSet() {
+			return unsupportedError(fieldNamePath, dst.Type(), "unexported fields cannot be transformed")
+		}
+		fn, ok := lookupTransform(field.transform)
+		if !ok {
+			return unsupportedError(fieldNamePath, dst.Type(), "no transform registered as "+strconv.Quote(field.transform))
+		}
+		var transformed reflect.Value
+		if err := c.guard(fieldNamePath, dst.Type(), func() error {
+			transformed = fn(dst)
+			return nil
+		}); err != nil {
+			return err
+		}
+		if !transformed.IsValid() {
+			return unsupportedError(fieldNamePath, dst.Type(), "transform returned an invalid value")
+		}
+		transformed, ok = assignableClone(transformed, dst.Type())
+		if !ok {
+			return unsupportedError(fieldNamePath, dst.Type(), "transform result is not assignable to the field type")
+		}
+		dst.Set(transformed)
+	}
 	return nil
 }
 
 func (c *cloneContext) cloneArray(v reflect.Value, path string) (reflect.Value, error) {
 	clonedArray := reflect.New(v.Type()).Elem()
+	clonedArray.Set(v)
 	c.registerArrayElements(v, clonedArray)
 
 	if err := c.cloneArrayInto(v, clonedArray, path); err != nil {
@@ -643,25 +2021,88 @@ func (c *cloneContext) cloneArray(v reflect.Value, path string) (reflect.Value,
 	return clonedArray, nil
 }
 
+// cloneArrayInto deep-clones the elements of v into clonedArray, which holds a
+// shallow copy of v. Elements are cloned in place so registered interior
+// addresses keep pointing into clonedArray.
 func (c *cloneContext) cloneArrayInto(v, clonedArray reflect.Value, path string) error {
+	if isPlainType(v.Type()) && !c.hasCloneFunc(v.Type()) {
+		return nil
+	}
 	for i := range v.Len() {
-		elem, err := c.cloneValue(v.Index(i), indexPath(path, i))
-		if err != nil {
-			return err
+		elem := v.Index(i)
+		if isNilPointer(elem) {
+			continue
 		}
-		if elem.IsValid() {
-			clonedArray.Index(i).Set(elem)
+		if err := c.cloneElementInto(elem, clonedArray.Index(i), indexPath(path, i)); err != nil {
+			return err
 		}
 	}
 	return nil
 }
 
+// cloneElementInto deep-clones src into dst, which holds a shallow copy of src.
+func (c *cloneContext) cloneElementInto(src, dst reflect.Value, path string) error {
+	if c.sharesType(src.Type()) {
+		return c.shareValue(src, path)
+	}
+	if c.ignoresUnsupported(src, path) {
+		dst.SetZero()
+		return nil
+	}
+	if err := unsupportedValue(src, path); err != nil && !c.hasCustomClone(src.Type()) {
+		return err
+	}
+	if copier, ok := c.layoutFor(src.Type()); ok && src.CanInterface() && dst.CanAddr() {
+		return c.copyLayoutInto(dst, src, copier, path)
+	}
+
+	switch {
+	case src.Kind() == reflect.Struct && !c.hasCustomClone(src.Type()):
+		return c.cloneStructInto(src, dst, path)
+	case src.Kind() == reflect.Array:
+		return c.cloneArrayInto(src, dst, path)
+	}
+
+	cloned, err := c.cloneValue(src, path)
+	if err != nil {
+		return err
+	}
+	if cloned.IsValid() {
+		dst.Set(cloned)
+	}
+	return nil
+}
+
+// cloneJSONInto replaces the shallow copy in clonedStruct with a JSON round
+// trip of v through its MarshalJSON and UnmarshalJSON methods.
+func cloneJSONInto(v, clonedStruct reflect.Value, path string) error {
+	src := v
+	if !src.CanAddr() {
+		src = reflect.New(v.Type()).Elem()
+		src.Set(v)
+	}
+	data, err := json.Marshal(src.Addr().Interface())
+	if err != nil {
+		return unsupportedError(path, v.Type(), "JSON fallback failed to marshal: "+err.Error())
+	}
+	clonedStruct.SetZero()
+	if err := json.Unmarshal(data, clonedStruct.Addr().Interface()); err != nil {
+		return unsupportedError(path, v.Type(), "JSON fallback failed to unmarshal: "+err.Error())
+	}
+	return nil
+}
+
 func (c *cloneContext) cloneInterface(v reflect.Value, path string) (reflect.Value, error) {
 	if v.IsNil() {
 		return v, nil
 	}
+	if sharesInterface(v.Type(), v.Elem().Type()) {
+		c.markShared()
+		return v, nil
+	}
 
-	clonedElem, err := c.cloneValue(v.Elem(), path)
+	elem := v.Elem()
+	clonedElem, err := c.cloneValue(elem, path)
 	if err != nil {
 		return reflect.Value{}, err
 	}
@@ -669,7 +2110,11 @@ func (c *cloneContext) cloneInterface(v reflect.Value, path string) (reflect.Val
 		return v, nil
 	}
 
-	iface := reflect.New(v.Type()).Elem()
-	iface.Set(clonedElem)
-	return iface, nil
+	// Return the concrete clone and let the caller's Set box it once; only
+	// restore the source's dynamic type if a Clone method returned an
+	// assignable but differently named type.
+	if clonedElem.Type() != elem.Type() {
+		clonedElem = clonedElem.Convert(elem.Type())
+	}
+	return clonedElem, nil
 }
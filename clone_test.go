@@ -1,11 +1,28 @@
 package deepclone
 
 import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/json"
 	"errors"
 	"fmt"
+	htmltemplate "html/template"
+	"image"
+	"image/color"
+	"image/draw"
+	"io"
+	"math"
+	"net/http"
 	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
+	texttemplate "text/template"
 	"unsafe"
 
 	"github.com/google/go-cmp/cmp"
@@ -338,8 +355,24 @@ func TestCloneMaps(t *testing.T) {
 		assert.NotContains(t, cloned, 3)
 	})
 
+	t.Run("string set map", func(t *testing.T) {
+		t.Parallel()
+		original := map[string]struct{}{"a": {}, "b": {}}
+		cloned := MustClone(original)
+
+		if diff := cmp.Diff(original, cloned); diff != "" {
+			t.Errorf("Clone() mismatch (-want +got):\n%s", diff)
+		}
+
+		original["c"] = struct{}{}
+		assert.NotContains(t, cloned, "c")
+	})
+
 	t.Run("nil map types", func(t *testing.T) {
 		t.Parallel()
+		var nilStringSet map[string]struct{}
+		assert.Nil(t, MustClone(nilStringSet))
+
 		var nilStringFloat64 map[string]float64
 		assert.Nil(t, MustClone(nilStringFloat64))
 
@@ -496,6 +529,93 @@ func TestCloneStructs(t *testing.T) {
 	})
 }
 
+// flagSet mimics third-party value types whose state is unexported but safe to
+// copy by assignment.
+type flagSet struct {
+	Name   string
+	bits   uint64
+	counts [4]int32
+	window struct {
+		lo, hi float64
+	}
+}
+
+func TestCloneStructsPreserveUnexportedValueFields(t *testing.T) {
+	t.Parallel()
+	original := flagSet{Name: "flags", bits: 0b1011, counts: [4]int32{1, 2, 3, 4}}
+	original.window.lo, original.window.hi = -1.5, 2.5
+
+	t.Run("value", func(t *testing.T) {
+		t.Parallel()
+		cloned := MustClone(original)
+
+		assert.Equal(t, original, cloned)
+	})
+
+	t.Run("pointer", func(t *testing.T) {
+		t.Parallel()
+		cloned := MustClone(&original)
+
+		assert.Equal(t, original, *cloned)
+		assert.False(t, cloned == &original)
+	})
+
+	t.Run("slice element and map value", func(t *testing.T) {
+		t.Parallel()
+		type holder struct {
+			List []flagSet
+			ByID map[int]flagSet
+		}
+		cloned := MustClone(holder{List: []flagSet{original}, ByID: map[int]flagSet{1: original}})
+
+		assert.Equal(t, original, cloned.List[0])
+		assert.Equal(t, original, cloned.ByID[1])
+	})
+}
+
+func TestIsPlainType(t *testing.T) {
+	t.Parallel()
+	type withSlice struct {
+		Name  string
+		Items []int
+	}
+	type withZeroTag struct {
+		Secret string `clone:"zero"`
+	}
+	type nested struct {
+		Flags flagSet
+		Pairs [2][2]int
+	}
+
+	tests := []struct {
+		name  string
+		typ   reflect.Type
+		plain bool
+	}{
+		{"int", reflect.TypeFor[int](), true},
+		{"string", reflect.TypeFor[string](), true},
+		{"array of primitives", reflect.TypeFor[[3]float64](), true},
+		{"struct with unexported primitives", reflect.TypeFor[flagSet](), true},
+		{"nested plain structs", reflect.TypeFor[nested](), true},
+		{"empty struct", reflect.TypeFor[struct{}](), true},
+		{"pointer", reflect.TypeFor[*int](), false},
+		{"slice", reflect.TypeFor[[]int](), false},
+		{"interface", reflect.TypeFor[any](), false},
+		{"struct with slice", reflect.TypeFor[withSlice](), false},
+		{"array of pointers", reflect.TypeFor[[2]*int](), false},
+		{"zero tag", reflect.TypeFor[withZeroTag](), false},
+		{"custom clone", reflect.TypeFor[CustomType](), false},
+		{"sync primitive", reflect.TypeFor[sync.Mutex](), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.plain, isPlainType(tt.typ))
+		})
+	}
+}
+
 func TestCloneArrays(t *testing.T) {
 	t.Parallel()
 	t.Run("int array", func(t *testing.T) {
@@ -571,6 +691,12 @@ func (c CustomType) Clone() (CustomType, error) {
 	return CustomType{Value: c.Value + "_cloned"}, nil
 }
 
+// box is a generic user type whose fields depend on the type argument.
+type box[T any] struct {
+	Val     T
+	History []T
+}
+
 type convertibleCloner struct {
 	Value string
 	calls *int
@@ -592,6 +718,67 @@ func (*nilCloner) Clone() (*nilCloner, error) {
 	panic("nil cloner should not be cloned")
 }
 
+func TestCloneGenericStructs(t *testing.T) {
+	t.Parallel()
+
+	t.Run("pointer argument", func(t *testing.T) {
+		t.Parallel()
+		first, second := 1, 2
+		original := box[*int]{Val: &second, History: []*int{&first, &second}}
+
+		cloned, err := Clone(original)
+
+		require.NoError(t, err)
+		assert.NotSame(t, original.Val, cloned.Val)
+		assert.Equal(t, 2, *cloned.Val)
+		assert.Same(t, cloned.Val, cloned.History[1], "shared pointer should stay shared")
+		*cloned.History[0] = 10
+		assert.Equal(t, 1, first)
+	})
+
+	t.Run("slice argument", func(t *testing.T) {
+		t.Parallel()
+		original := &box[[]string]{Val: []string{"a"}, History: [][]string{{"b", "c"}}}
+
+		cloned, err := Clone(original)
+
+		require.NoError(t, err)
+		assert.Equal(t, original, cloned)
+		cloned.Val[0] = "changed"
+		cloned.History[0][1] = "changed"
+		assert.Equal(t, "a", original.Val[0])
+		assert.Equal(t, "c", original.History[0][1])
+	})
+
+	t.Run("cloner argument", func(t *testing.T) {
+		t.Parallel()
+		original := box[CustomType]{Val: CustomType{Value: "v"}, History: []CustomType{{Value: "h"}}}
+
+		cloned, err := Clone(original)
+
+		require.NoError(t, err)
+		assert.Equal(t, "v_cloned", cloned.Val.Value)
+		assert.Equal(t, []CustomType{{Value: "h_cloned"}}, cloned.History)
+	})
+
+	t.Run("instantiations are cached separately", func(t *testing.T) {
+		t.Parallel()
+		n := 1
+		plain := box[int]{Val: 1, History: []int{1}}
+		pointers := box[*int]{Val: &n}
+
+		clonedPlain, err := Clone(plain)
+		require.NoError(t, err)
+		clonedPointers, err := Clone(pointers)
+		require.NoError(t, err)
+
+		clonedPlain.History[0] = 2
+		assert.Equal(t, 1, plain.History[0])
+		assert.NotSame(t, pointers.Val, clonedPointers.Val)
+		assert.NotSame(t, structInfo(reflect.TypeFor[box[int]]()), structInfo(reflect.TypeFor[box[*int]]()))
+	})
+}
+
 // TestCloneCircularReference tests circular reference detection
 func TestCloneCircularReference(t *testing.T) {
 	t.Parallel()
@@ -1161,6 +1348,65 @@ func TestCloneUnexportedReferenceFieldsRejectsMutableState(t *testing.T) {
 	assert.Equal(t, "unexported reference-like fields cannot be cloned", unsupported.Reason)
 }
 
+// TestCloneSliceShapes checks that every slice path keeps the length,
+// capacity, and nil-ness of the source.
+func TestCloneSliceShapes(t *testing.T) {
+	t.Parallel()
+	type names []string
+	type refs []*int
+
+	t.Run("int", func(t *testing.T) {
+		t.Parallel()
+		checkSliceShapes[[]int](t, 7)
+	})
+	t.Run("byte", func(t *testing.T) {
+		t.Parallel()
+		checkSliceShapes[[]byte](t, 'a')
+	})
+	t.Run("string", func(t *testing.T) {
+		t.Parallel()
+		checkSliceShapes[[]string](t, "s")
+	})
+	t.Run("named plain slice", func(t *testing.T) {
+		t.Parallel()
+		checkSliceShapes[names](t, "s")
+	})
+	t.Run("reflection path", func(t *testing.T) {
+		t.Parallel()
+		n := 1
+		checkSliceShapes[refs](t, &n)
+	})
+}
+
+func checkSliceShapes[S ~[]E, E any](t *testing.T, elem E) {
+	t.Helper()
+	withLen := make(S, 3, 8)
+	for i := range withLen {
+		withLen[i] = elem
+	}
+	shapes := map[string]S{
+		"nil":            nil,
+		"empty":          S{},
+		"empty with cap": make(S, 0, 8),
+		"spare capacity": withLen,
+	}
+
+	for name, original := range shapes {
+		fast, err := Clone(original)
+		require.NoError(t, err)
+		reflected, err := CloneWith(original, WithForceReflection())
+		require.NoError(t, err)
+		field, err := Clone(struct{ Items S }{Items: original})
+		require.NoError(t, err)
+
+		for path, cloned := range map[string]S{"fast": fast, "reflection": reflected, "field": field.Items} {
+			assert.Equal(t, original == nil, cloned == nil, "%s %s: nil-ness", name, path)
+			assert.Len(t, cloned, len(original), "%s %s", name, path)
+			assert.Equal(t, cap(original), cap(cloned), "%s %s: capacity", name, path)
+		}
+	}
+}
+
 // TestCloneAdditionalSliceFastPaths covers the fast paths for []float64,
 // []bool, and []byte slices that were not exercised by existing tests.
 func TestCloneAdditionalSliceFastPaths(t *testing.T) {
@@ -1204,6 +1450,34 @@ func TestCloneAdditionalSliceFastPaths(t *testing.T) {
 		assert.NotEqual(t, original[0], cloned[0])
 	})
 
+	t.Run("byte slice with spare capacity", func(t *testing.T) {
+		t.Parallel()
+		type buffer []byte
+		type packet struct {
+			Payload []byte
+		}
+
+		original := make([]byte, 3, 64)
+		copy(original, "abc")
+
+		fast := MustClone(original)
+		named := MustClone(buffer(original))
+		field := MustClone(packet{Payload: original})
+
+		for name, cloned := range map[string][]byte{
+			"fast path":  fast,
+			"named type": named,
+			"field":      field.Payload,
+		} {
+			assert.Equal(t, []byte("abc"), cloned, name)
+			assert.Equal(t, cap(original), cap(cloned), name)
+
+			grown := append(cloned, 'd')
+			assert.Same(t, &cloned[0], &grown[0], "%s: append within capacity should not reallocate", name)
+			assert.Equal(t, byte(0), original[:4][3], "%s: append should not write into the original", name)
+		}
+	})
+
 	t.Run("nil float64 slice", func(t *testing.T) {
 		t.Parallel()
 		var original []float64
@@ -1268,628 +1542,3004 @@ func TestCloneNilMapFastPaths(t *testing.T) {
 	})
 }
 
-// TestCloneCircularMapReference covers the circular reference detection
-// path in cloneMap where a previously visited map is returned from cache.
-func TestCloneCircularMapReference(t *testing.T) {
+func TestCloneEmptyStructValues(t *testing.T) {
 	t.Parallel()
-	// A map[string]any that contains itself as a value triggers the
-	// circular reference detection directly inside cloneMap.
-	m := map[string]any{"key": "value"}
-	m["self"] = m // self-referencing map
+	type marker struct{}
+	type holder struct {
+		Name   string
+		Marker marker
+		Empty  struct{}
+		Set    map[int]struct{}
+		Items  []struct{}
+	}
 
-	cloned := MustClone(m)
+	original := holder{
+		Name:  "set",
+		Set:   map[int]struct{}{1: {}, 2: {}},
+		Items: make([]struct{}, 3),
+	}
+	cloned := MustClone(original)
 
-	require.NotNil(t, cloned)
-	assert.Equal(t, "value", cloned["key"])
-	// The "self" entry should reference the cloned map itself.
-	inner, ok := cloned["self"].(map[string]any)
-	require.True(t, ok)
-	assert.Equal(t, "value", inner["key"])
+	if diff := cmp.Diff(original, cloned); diff != "" {
+		t.Errorf("Clone() mismatch (-want +got):\n%s", diff)
+	}
+	original.Set[3] = struct{}{}
+	assert.NotContains(t, cloned.Set, 3)
+	assert.Equal(t, marker{}, MustClone(marker{}))
+	assert.Equal(t, struct{}{}, MustClone(struct{}{}))
 }
 
-// TestCloneCircularSliceReference covers the circular reference detection
-// path in cloneSlice where a previously visited slice with matching
-// len/cap is returned from cache.
-func TestCloneCircularSliceReference(t *testing.T) {
+func TestCloneDecodedJSON(t *testing.T) {
 	t.Parallel()
-	// A slice of any that contains itself triggers the circular
-	// reference detection directly inside cloneSlice.
-	s := make([]any, 2)
-	s[0] = "value"
-	s[1] = s // self-referencing slice
+	var original map[string]any
+	require.NoError(t, json.Unmarshal([]byte(`{"id":1,"tags":["a",null,true],"owner":{"name":"x","n":2.5}}`), &original))
+	shared := map[string]any{"k": "v"}
+	original["left"] = shared
+	original["right"] = []any{shared, map[string]any(nil), json.Number("7")}
+	original["self"] = original
+	original["point"] = benchSimple{ID: 3}
 
-	cloned := MustClone(s)
-
-	require.Len(t, cloned, 2)
-	assert.Equal(t, "value", cloned[0])
-	// The second element should reference the cloned slice itself.
-	inner, ok := cloned[1].([]any)
-	require.True(t, ok)
-	assert.Len(t, inner, 2)
-	assert.Equal(t, "value", inner[0])
-}
+	cloned, err := Clone(original)
+	require.NoError(t, err)
+	reflected, err := CloneWith(original, WithForceReflection())
+	require.NoError(t, err)
 
-func TestCloneCircularSliceThroughArrayElement(t *testing.T) {
-	t.Parallel()
+	for _, clone := range []map[string]any{cloned, reflected} {
+		assert.Equal(t, original["tags"], clone["tags"])
+		assert.Equal(t, original["owner"], clone["owner"])
+		assert.Equal(t, benchSimple{ID: 3}, clone["point"])
+		right, ok := clone["right"].([]any)
+		require.True(t, ok)
+		assert.IsType(t, map[string]any(nil), right[1])
+		assert.Equal(t, json.Number("7"), right[2])
 
-	original := make([][1]any, 1)
-	original[0][0] = original
+		left, ok := clone["left"].(map[string]any)
+		require.True(t, ok)
+		left["k"] = "changed"
+		assert.Equal(t, "changed", right[0].(map[string]any)["k"], "shared map should stay shared")
+		assert.Equal(t, "v", shared["k"])
 
-	cloned := MustClone(original)
+		self, ok := clone["self"].(map[string]any)
+		require.True(t, ok)
+		self["new"] = true
+		assert.Equal(t, true, clone["new"], "cycle should point at the clone")
+		assert.NotContains(t, original, "new")
+	}
 
-	require.Len(t, cloned, 1)
-	inner, ok := cloned[0][0].([][1]any)
-	require.True(t, ok)
-	require.Len(t, inner, 1)
-	assert.Same(t, &cloned[0], &inner[0], "array element should reference the cloned slice")
-	assert.NotSame(t, &original[0], &inner[0], "array element should not reference the original slice")
+	_, err = Clone(map[string]any{"items": []any{"ok", map[string]any{"ch": make(chan int)}}})
+	var unsupported *UnsupportedError
+	require.ErrorAs(t, err, &unsupported)
+	assert.Equal(t, `$["items"][1]["ch"]`, unsupported.Path)
 }
 
-// TestCloneSharedMapReference covers the case where the same map
-// is referenced from two struct fields, hitting the visited cache
-// in cloneMap on the second encounter.
-func TestCloneSharedMapReference(t *testing.T) {
+func TestCloneDecodedYAML(t *testing.T) {
 	t.Parallel()
-	type TwoMaps struct {
-		A map[string]any
-		B map[string]any
+	// YAML and TOML decoders produce int, int64, and uint64 numbers.
+	original := map[string]any{
+		"port":    8080,
+		"memory":  int64(512 << 20),
+		"retries": uint64(5),
+		"ports":   []any{8000, int64(9000), uint64(1)},
+		"limits":  map[string]any{"cpu": 0.5, "max": 100},
 	}
 
-	shared := map[string]any{"key": "value"}
-	original := TwoMaps{A: shared, B: shared}
-	cloned := MustClone(original)
+	cloned, err := Clone(original)
+	require.NoError(t, err)
+	reflected, err := CloneWith(original, WithForceReflection())
+	require.NoError(t, err)
 
-	assert.Equal(t, "value", cloned.A["key"])
-	assert.Equal(t, "value", cloned.B["key"])
+	for _, clone := range []map[string]any{cloned, reflected} {
+		assert.Equal(t, original, clone)
+		assert.IsType(t, 0, clone["port"])
+		assert.IsType(t, int64(0), clone["memory"])
+		assert.IsType(t, uint64(0), clone["retries"])
+		clone["ports"].([]any)[0] = 1
+		clone["limits"].(map[string]any)["max"] = 1
+		assert.Equal(t, 8000, original["ports"].([]any)[0])
+		assert.Equal(t, 100, original["limits"].(map[string]any)["max"])
+	}
+}
 
-	// Both fields should point to the same cloned map.
-	cloned.A["new"] = "added"
-	assert.Equal(t, "added", cloned.B["new"],
-		"shared map reference should be preserved")
+// nestedAnySlices returns depth levels of []any, such as a decoded JSON
+// array of arrays, where level i holds float64(i) and the next level.
+func nestedAnySlices(depth int) []any {
+	var inner []any
+	for i := depth - 1; i >= 0; i-- {
+		inner = []any{float64(i), inner}
+	}
+	return inner
 }
 
-// TestCloneSharedSliceReference covers the case where the same slice
-// is referenced from two struct fields with identical len/cap, hitting
-// the visited cache in cloneSlice on the second encounter.
-func TestCloneSharedSliceReference(t *testing.T) {
+func TestCloneDeeplyNestedAnySlices(t *testing.T) {
 	t.Parallel()
-	type TwoSlices struct {
-		A []any
-		B []any
+	const depth = 10_000
+	tests := []struct {
+		name string
+		opts []Option
+	}{
+		{name: "default"},
+		{name: "force reflection", opts: []Option{WithForceReflection()}},
+		{name: "stack safety margin", opts: []Option{WithStackSafetyMargin()}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			original := nestedAnySlices(depth)
+
+			cloned, err := CloneWith(original, tt.opts...)
+
+			require.NoError(t, err)
+			src, dst := original, cloned
+			for i := range depth {
+				require.Len(t, dst, 2, "level %d", i)
+				require.Equal(t, float64(i), dst[0], "level %d", i)
+				require.NotSame(t, &src[0], &dst[0], "level %d should be a new slice", i)
+				dst[0] = "changed"
+				require.Equal(t, float64(i), src[0], "level %d", i)
+				src, _ = src[1].([]any)
+				dst, _ = dst[1].([]any)
+			}
+			assert.Nil(t, dst, "the innermost level should end the chain")
+		})
 	}
 
-	shared := []any{"x", "y"}
-	original := TwoSlices{A: shared, B: shared}
-	cloned := MustClone(original)
+	t.Run("errors report the full path", func(t *testing.T) {
+		t.Parallel()
+		original := []any{0.0, []any{1.0, map[string]any{"rows": []any{[]any{1, 2, 3}}}}}
 
-	if diff := cmp.Diff([]any{"x", "y"}, cloned.A); diff != "" {
-		t.Errorf("shared slice field A mismatch (-want +got):\n%s", diff)
-	}
-	if diff := cmp.Diff([]any{"x", "y"}, cloned.B); diff != "" {
-		t.Errorf("shared slice field B mismatch (-want +got):\n%s", diff)
-	}
+		_, err := CloneWith(original, WithMaxCollectionLen(2))
 
-	// Both fields should point to the same cloned slice.
-	cloned.A[0] = "modified"
-	assert.Equal(t, "modified", cloned.B[0],
-		"shared slice reference should be preserved")
+		var limit *LimitError
+		require.ErrorAs(t, err, &limit)
+		assert.Equal(t, `$[1][1]["rows"][0]`, limit.Path)
+	})
 }
 
-// TestCloneMapTypeAliasConversions covers the type alias conversion
-// branches in cloneMap: ConvertibleTo, AssignableTo, and incompatible.
-func TestCloneMapTypeAliasConversions(t *testing.T) {
+// accountID is a named string type like json.Number.
+type accountID string
+
+// maskedString is a named string type whose Clone method replaces the value.
+type maskedString string
+
+func (maskedString) Clone() maskedString { return "***" }
+
+func TestCloneNamedStringTypes(t *testing.T) {
 	t.Parallel()
-	t.Run("convertible value type", func(t *testing.T) {
+	type record struct {
+		ID      accountID
+		Amount  json.Number
+		Aliases []accountID
+		Totals  map[accountID]json.Number
+		Any     any
+	}
+	var decoded map[string]any
+	decoder := json.NewDecoder(strings.NewReader(`{"amount":12.50,"items":[1,2.5e3],"nested":{"n":7}}`))
+	decoder.UseNumber()
+	require.NoError(t, decoder.Decode(&decoded))
+	decoded["id"] = accountID("acct-1")
+	decoded["ids"] = []any{accountID("a"), accountID("b")}
+
+	t.Run("every position", func(t *testing.T) {
 		t.Parallel()
-		type MyInt int
+		original := &record{
+			ID:      "acct-1",
+			Amount:  "12.50",
+			Aliases: []accountID{"a", "b"},
+			Totals:  map[accountID]json.Number{"a": "1"},
+			Any:     accountID("boxed"),
+		}
+
+		assert.Equal(t, accountID("acct-1"), MustClone(original.ID))
+		assert.Equal(t, json.Number("12.50"), MustClone(original.Amount))
+		assert.Equal(t, []json.Number{"1", "2"}, MustClone([]json.Number{"1", "2"}))
+		assert.Equal(t, map[string]json.Number{"a": "1"}, MustClone(map[string]json.Number{"a": "1"}))
+		assert.Equal(t, any(json.Number("3")), MustClone(any(json.Number("3"))))
 
-		// map[string]any with a MyInt value — when cloned via reflection,
-		// the concrete type may differ from the map's element type (any),
-		// exercising the ConvertibleTo branch.
-		original := map[string]MyInt{"key": 42}
 		cloned := MustClone(original)
+		assert.Equal(t, original, cloned)
+		assert.IsType(t, accountID(""), cloned.Any)
+		cloned.Aliases[0] = "changed"
+		cloned.Totals["a"] = "2"
+		assert.Equal(t, accountID("a"), original.Aliases[0])
+		assert.Equal(t, json.Number("1"), original.Totals["a"])
+	})
 
-		require.NotNil(t, cloned)
-		assert.Equal(t, MyInt(42), cloned["key"])
+	t.Run("decoded map", func(t *testing.T) {
+		t.Parallel()
+		cloned := MustClone(decoded)
+		reflected := MustCloneWith(decoded, WithForceReflection())
+
+		for _, clone := range []map[string]any{cloned, reflected} {
+			assert.Equal(t, decoded, clone)
+			assert.IsType(t, json.Number(""), clone["amount"])
+			assert.IsType(t, accountID(""), clone["id"])
+			clone["items"].([]any)[0] = json.Number("9")
+			clone["nested"].(map[string]any)["n"] = json.Number("9")
+			assert.Equal(t, json.Number("1"), decoded["items"].([]any)[0])
+			assert.Equal(t, json.Number("7"), decoded["nested"].(map[string]any)["n"])
+		}
+	})
+
+	t.Run("Clone methods still run", func(t *testing.T) {
+		t.Parallel()
+		assert.Equal(t, maskedString("***"), MustClone(maskedString("secret")))
+		cloned := MustClone(map[string]any{"m": maskedString("secret")})
+		assert.Equal(t, maskedString("***"), cloned["m"])
+		assert.Equal(t, accountID("custom"), MustCloneWith(accountID("id"), WithCloneFunc(func(accountID) (accountID, error) {
+			return "custom", nil
+		})))
 	})
 }
 
-// TestCloneStructFieldTypeConversion covers the type alias conversion
-// path in cloneStruct where a cloned field's type differs from the
-// destination field type and needs Convert().
-func TestCloneStructFieldTypeConversion(t *testing.T) {
+// TestCloneNamedStringTypeAllocs is not parallel because testing.AllocsPerRun
+// panics in parallel tests.
+func TestCloneNamedStringTypeAllocs(t *testing.T) {
+	number := json.Number("12.50")
+	numbers := []json.Number{"1", "2"}
+	named := map[string]any{"amount": number, "id": accountID("acct-1"), "ids": []any{accountID("a")}}
+	plain := map[string]any{"amount": "12.50", "id": "acct-1", "ids": []any{"a"}}
+
+	assert.Zero(t, testing.AllocsPerRun(100, func() { _, _ = Clone(number) }))
+	assert.Zero(t, testing.AllocsPerRun(100, func() { _, _ = Clone(accountID("acct-1")) }))
+	assert.Equal(t, 1.0, testing.AllocsPerRun(100, func() { _, _ = Clone(numbers) }))
+	assert.Equal(t,
+		testing.AllocsPerRun(100, func() { _, _ = Clone(plain) }),
+		testing.AllocsPerRun(100, func() { _, _ = Clone(named) }),
+		"named strings in decoded maps should cost no more than strings")
+}
+
+func TestCloneMapsKeepNilEntries(t *testing.T) {
 	t.Parallel()
-	type Inner struct {
-		Value int
-	}
+	one := 1
+	pointers := map[string]*int{"set": &one, "unset": nil}
+	dynamic := map[string]any{"set": 1.0, "unset": nil}
+	headers := map[string][]string{"set": {"a"}, "unset": nil}
 
-	type Outer struct {
-		Field Inner
+	tests := []struct {
+		name string
+		opts []Option
+	}{
+		{name: "default"},
+		{name: "force reflection", opts: []Option{WithForceReflection()}},
+		{name: "deterministic order", opts: []Option{WithDeterministicOrder()}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			clonedPointers, err := CloneWith(pointers, tt.opts...)
+			require.NoError(t, err)
+			clonedDynamic, err := CloneWith(dynamic, tt.opts...)
+			require.NoError(t, err)
+			clonedHeaders, err := CloneWith(headers, tt.opts...)
+			require.NoError(t, err)
+
+			require.Len(t, clonedPointers, 2)
+			assert.Equal(t, 1, *clonedPointers["set"])
+			pointer, ok := clonedPointers["unset"]
+			assert.True(t, ok, "the nil-valued key should be present")
+			assert.Nil(t, pointer)
+			_, ok = clonedPointers["missing"]
+			assert.False(t, ok, "an absent key should stay absent")
+
+			require.Len(t, clonedDynamic, 2)
+			value, ok := clonedDynamic["unset"]
+			assert.True(t, ok)
+			assert.Nil(t, value)
+
+			require.Len(t, clonedHeaders, 2)
+			header, ok := clonedHeaders["unset"]
+			assert.True(t, ok)
+			assert.Nil(t, header)
+		})
+	}
+
+	t.Run("CloneMapInto", func(t *testing.T) {
+		t.Parallel()
+		cloned, err := CloneMapInto(map[string]*int{"stale": &one}, pointers)
+
+		require.NoError(t, err)
+		assert.Len(t, cloned, 2)
+		value, ok := cloned["unset"]
+		assert.True(t, ok)
+		assert.Nil(t, value)
+	})
+
+	t.Run("CloneMapSeq", func(t *testing.T) {
+		t.Parallel()
+		seq, errFn := CloneMapSeq(pointers)
+		cloned := make(map[string]*int)
+		for key, value := range seq {
+			cloned[key] = value
+		}
+
+		require.NoError(t, errFn())
+		value, ok := cloned["unset"]
+		assert.True(t, ok)
+		assert.Nil(t, value)
+	})
+
+	t.Run("struct field", func(t *testing.T) {
+		t.Parallel()
+		type registry struct {
+			Entries map[string]*int
+		}
+
+		cloned := MustClone(&registry{Entries: pointers})
+
+		value, ok := cloned.Entries["unset"]
+		assert.True(t, ok)
+		assert.Nil(t, value)
+		assert.Len(t, cloned.Entries, 2)
+	})
+}
+
+func TestCloneHeaderMaps(t *testing.T) {
+	t.Parallel()
+	original := map[string][]string{
+		"X":      {"a", "b"},
+		"Accept": make([]string, 1, 4),
+		"Empty":  {},
+		"Nil":    nil,
+	}
+	original["Accept"][0] = "text/html"
+
+	cloned, err := Clone(original)
+	require.NoError(t, err)
+	reflected, err := CloneWith(original, WithForceReflection())
+	require.NoError(t, err)
+
+	for _, clone := range []map[string][]string{cloned, reflected} {
+		assert.Equal(t, original, clone)
+		assert.Nil(t, clone["Nil"])
+		assert.NotNil(t, clone["Empty"])
+		assert.Equal(t, 4, cap(clone["Accept"]))
+
+		clone["X"][0] = "changed"
+		assert.Equal(t, "a", original["X"][0])
+		clone["Accept"] = append(clone["Accept"], "text/plain")
+		assert.Equal(t, "b", clone["X"][1], "appending should not reach another value")
+	}
+
+	t.Run("named header type in a struct", func(t *testing.T) {
+		t.Parallel()
+		type request struct {
+			Header  http.Header
+			Trailer http.Header
+		}
+		header := http.Header{"Content-Type": {"application/json"}}
+		src := request{Header: header, Trailer: header}
+
+		cloned, err := Clone(src)
+		require.NoError(t, err)
+		cloned.Header.Set("Content-Type", "text/plain")
+		assert.Equal(t, "application/json", header.Get("Content-Type"))
+		assert.Equal(t, "text/plain", cloned.Trailer.Get("Content-Type"), "shared header should stay shared")
+	})
+}
+
+// TestCloneCircularMapReference covers a map[string]any stored as its own
+// value, through cloneDynamicMap and through the reflection path that options
+// such as WithForceReflection select instead.
+func TestCloneCircularMapReference(t *testing.T) {
+	t.Parallel()
+	// A map[string]any that contains itself as a value triggers the
+	// circular reference detection directly inside cloneMap.
+	m := map[string]any{"key": "value"}
+	m["self"] = m // self-referencing map
+
+	cloned := MustClone(m)
+
+	require.NotNil(t, cloned)
+	assert.Equal(t, "value", cloned["key"])
+	// The "self" entry should reference the cloned map itself.
+	inner, ok := cloned["self"].(map[string]any)
+	require.True(t, ok)
+	assert.Equal(t, "value", inner["key"])
+}
+
+// TestCloneCircularMapIdentity checks that self-references in a
+// map[string]any resolve to the clone itself on every engine path.
+func TestCloneCircularMapIdentity(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		opts []Option
+	}{
+		{name: "dynamic path"},
+		{name: "force reflection", opts: []Option{WithForceReflection()}},
+		{name: "deterministic order", opts: []Option{WithDeterministicOrder()}},
+		{name: "stack safety margin", opts: []Option{WithStackSafetyMargin()}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			m := map[string]any{"key": "value"}
+			m["self"] = m
+			m["nested"] = []any{m, map[string]any{"parent": m}}
+
+			cloned, err := CloneWith(m, tt.opts...)
+
+			require.NoError(t, err)
+			assert.Equal(t, "value", cloned["key"])
+			inner, ok := cloned["self"].(map[string]any)
+			require.True(t, ok)
+			assert.Equal(t, reflect.ValueOf(cloned).Pointer(), reflect.ValueOf(inner).Pointer(), "the self-reference should point at the clone")
+			assert.NotEqual(t, reflect.ValueOf(m).Pointer(), reflect.ValueOf(inner).Pointer())
+			nested := cloned["nested"].([]any)
+			assert.Equal(t, reflect.ValueOf(cloned).Pointer(), reflect.ValueOf(nested[0]).Pointer())
+			parent := nested[1].(map[string]any)["parent"]
+			assert.Equal(t, reflect.ValueOf(cloned).Pointer(), reflect.ValueOf(parent).Pointer())
+
+			inner["added"] = true
+			assert.Equal(t, true, cloned["added"])
+			assert.NotContains(t, m, "added")
+		})
+	}
+}
+
+// dynamicNode leaves the map[string]any path for reflection and points back
+// into it.
+type dynamicNode struct {
+	Name  string
+	Attrs map[string]any
+}
+
+// TestCloneCyclesAcrossDynamicMapValues covers cycles that span several
+// values of a map[string]any, including one that passes through a struct
+// cloned by reflection. One clone graph must see them all.
+func TestCloneCyclesAcrossDynamicMapValues(t *testing.T) {
+	t.Parallel()
+	root := map[string]any{"name": "root"}
+	child := map[string]any{"name": "child", "root": root}
+	node := &dynamicNode{Name: "node", Attrs: map[string]any{"child": child, "root": root}}
+	root["child"] = child
+	root["children"] = []any{child, node}
+	root["node"] = node
+	child["node"] = node
+
+	for _, opts := range [][]Option{nil, {WithForceReflection()}} {
+		cloned, err := CloneWith(root, opts...)
+
+		require.NoError(t, err)
+		clonedChild := cloned["child"].(map[string]any)
+		clonedNode := cloned["node"].(*dynamicNode)
+		children := cloned["children"].([]any)
+		assert.NotSame(t, node, clonedNode)
+		assert.Same(t, clonedNode, clonedChild["node"], "map values reaching one pointer should share its clone")
+		assert.Same(t, clonedNode, children[1])
+		assert.Equal(t, reflect.ValueOf(cloned).Pointer(), reflect.ValueOf(clonedChild["root"]).Pointer(), "the cycle back to the root should reach the clone")
+		assert.Equal(t, reflect.ValueOf(cloned).Pointer(), reflect.ValueOf(clonedNode.Attrs["root"]).Pointer())
+		assert.Equal(t, reflect.ValueOf(clonedChild).Pointer(), reflect.ValueOf(children[0]).Pointer())
+		assert.Equal(t, reflect.ValueOf(clonedChild).Pointer(), reflect.ValueOf(clonedNode.Attrs["child"]).Pointer())
+
+		clonedChild["name"] = "changed"
+		assert.Equal(t, "child", child["name"])
+		assert.Equal(t, "changed", clonedNode.Attrs["child"].(map[string]any)["name"])
+	}
+}
+
+// TestCloneCircularSliceReference covers the circular reference detection
+// path in cloneSlice where a previously visited slice with matching
+// len/cap is returned from cache.
+func TestCloneCircularSliceReference(t *testing.T) {
+	t.Parallel()
+	// A slice of any that contains itself triggers the circular
+	// reference detection directly inside cloneSlice.
+	s := make([]any, 2)
+	s[0] = "value"
+	s[1] = s // self-referencing slice
+
+	cloned := MustClone(s)
+
+	require.Len(t, cloned, 2)
+	assert.Equal(t, "value", cloned[0])
+	// The second element should reference the cloned slice itself.
+	inner, ok := cloned[1].([]any)
+	require.True(t, ok)
+	assert.Len(t, inner, 2)
+	assert.Equal(t, "value", inner[0])
+}
+
+func TestCloneCircularSliceThroughArrayElement(t *testing.T) {
+	t.Parallel()
+
+	original := make([][1]any, 1)
+	original[0][0] = original
+
+	cloned := MustClone(original)
+
+	require.Len(t, cloned, 1)
+	inner, ok := cloned[0][0].([][1]any)
+	require.True(t, ok)
+	require.Len(t, inner, 1)
+	assert.Same(t, &cloned[0], &inner[0], "array element should reference the cloned slice")
+	assert.NotSame(t, &original[0], &inner[0], "array element should not reference the original slice")
+}
+
+// TestCloneCircularSliceThroughBoxedOwner covers a []any whose elements box
+// the pointer that owns the slice.
+func TestCloneCircularSliceThroughBoxedOwner(t *testing.T) {
+	t.Parallel()
+	type C struct {
+		Name  string
+		Items []any
+	}
+
+	t.Run("exported items", func(t *testing.T) {
+		t.Parallel()
+		original := &C{Name: "owner"}
+		original.Items = []any{original, "leaf", original}
+
+		cloned := MustClone(original)
+
+		require.NotNil(t, cloned)
+		require.Len(t, cloned.Items, 3)
+		first, ok := cloned.Items[0].(*C)
+		require.True(t, ok)
+		last, ok := cloned.Items[2].(*C)
+		require.True(t, ok)
+		assert.True(t, first == cloned, "boxed owner should point at the cloned owner")
+		assert.True(t, last == cloned, "repeated boxed owner should share the cloned owner")
+		assert.False(t, first == original)
+		assert.Equal(t, "leaf", cloned.Items[1])
+	})
+
+	t.Run("unexported items", func(t *testing.T) {
+		t.Parallel()
+		type hidden struct {
+			items []any
+		}
+		original := &hidden{}
+		original.items = []any{original}
+
+		_, err := Clone(original)
+
+		var unsupported *UnsupportedError
+		require.ErrorAs(t, err, &unsupported)
+		assert.Equal(t, "$.items", unsupported.Path)
+		assert.Equal(t, "unexported reference-like fields cannot be cloned", unsupported.Reason)
+	})
+}
+
+// TestCloneSharedMapReference covers the case where the same map
+// is referenced from two struct fields, hitting the visited cache
+// in cloneMap on the second encounter.
+func TestCloneSharedMapReference(t *testing.T) {
+	t.Parallel()
+	type TwoMaps struct {
+		A map[string]any
+		B map[string]any
+	}
+
+	shared := map[string]any{"key": "value"}
+	original := TwoMaps{A: shared, B: shared}
+	cloned := MustClone(original)
+
+	assert.Equal(t, "value", cloned.A["key"])
+	assert.Equal(t, "value", cloned.B["key"])
+
+	// Both fields should point to the same cloned map.
+	cloned.A["new"] = "added"
+	assert.Equal(t, "added", cloned.B["new"],
+		"shared map reference should be preserved")
+}
+
+// TestCloneSharedSliceReference covers the case where the same slice
+// is referenced from two struct fields with identical len/cap, hitting
+// the visited cache in cloneSlice on the second encounter.
+func TestCloneSharedSliceReference(t *testing.T) {
+	t.Parallel()
+	type TwoSlices struct {
+		A []any
+		B []any
+	}
+
+	shared := []any{"x", "y"}
+	original := TwoSlices{A: shared, B: shared}
+	cloned := MustClone(original)
+
+	if diff := cmp.Diff([]any{"x", "y"}, cloned.A); diff != "" {
+		t.Errorf("shared slice field A mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]any{"x", "y"}, cloned.B); diff != "" {
+		t.Errorf("shared slice field B mismatch (-want +got):\n%s", diff)
+	}
+
+	// Both fields should point to the same cloned slice.
+	cloned.A[0] = "modified"
+	assert.Equal(t, "modified", cloned.B[0],
+		"shared slice reference should be preserved")
+}
+
+type collidingKey struct {
+	ID int
+}
+
+func (collidingKey) Clone() (collidingKey, error) {
+	return collidingKey{}, nil
+}
+
+func TestCloneMapKeys(t *testing.T) {
+	t.Parallel()
+
+	t.Run("array of pointer keys", func(t *testing.T) {
+		t.Parallel()
+		a, b := 1, 2
+		original := map[[2]*int]string{
+			{&a, &b}:  "ab",
+			{&b, &a}:  "ba",
+			{&a, nil}: "a",
+		}
+
+		cloned := MustClone(original)
+
+		require.Len(t, cloned, 3)
+		var clonedA, clonedB *int
+		for key, value := range cloned {
+			assert.False(t, key[0] == &a || key[0] == &b, "keys should not point at the original")
+			switch value {
+			case "ab":
+				clonedA, clonedB = key[0], key[1]
+			case "a":
+				assert.Nil(t, key[1])
+			}
+		}
+		require.NotNil(t, clonedA)
+		require.NotNil(t, clonedB)
+		assert.Equal(t, 1, *clonedA)
+		assert.Equal(t, 2, *clonedB)
+		assert.Equal(t, "ba", cloned[[2]*int{clonedB, clonedA}], "shared key pointers should clone once")
+	})
+
+	t.Run("interface keys boxing pointers", func(t *testing.T) {
+		t.Parallel()
+		a, b := 1, 2
+		original := map[any]int{&a: 1, &b: 2, "plain": 3}
+
+		cloned := MustClone(original)
+
+		require.Len(t, cloned, 3)
+		assert.Equal(t, 3, cloned["plain"])
+		_, ok := cloned[&a]
+		assert.False(t, ok, "pointer keys should be cloned")
+	})
+
+	t.Run("colliding cloned keys", func(t *testing.T) {
+		t.Parallel()
+		original := map[collidingKey]string{{ID: 1}: "one", {ID: 2}: "two"}
+
+		cloned, err := Clone(original)
+
+		var unsupported *UnsupportedError
+		require.ErrorAs(t, err, &unsupported)
+		assert.Equal(t, "cloned map key collides with another cloned key", unsupported.Reason)
+		assert.Equal(t, reflect.TypeFor[collidingKey](), unsupported.Type)
+		assert.Nil(t, cloned)
+	})
+
+	t.Run("array keys with plain values", func(t *testing.T) {
+		t.Parallel()
+		type uuid [16]byte
+		original := map[uuid]int{{1}: 1, {2, 15: 0xff}: 2}
+		set := map[[32]byte]struct{}{{0xaa}: {}, {0xbb}: {}}
+
+		for name, opts := range map[string][]Option{"copy": nil, "reflection": {WithForceReflection()}} {
+			cloned, err := CloneWith(original, opts...)
+			require.NoError(t, err, name)
+			assert.Equal(t, original, cloned, name)
+			cloned[uuid{3}] = 3
+			assert.NotContains(t, original, uuid{3}, name)
+
+			clonedSet, err := CloneWith(set, opts...)
+			require.NoError(t, err, name)
+			assert.Equal(t, set, clonedSet, name)
+		}
+	})
+
+	t.Run("array keys with reference values", func(t *testing.T) {
+		t.Parallel()
+		original := map[[16]byte][]string{{1}: {"a"}, {2}: nil}
+
+		cloned := MustClone(original)
+
+		assert.Equal(t, original, cloned)
+		assert.Nil(t, cloned[[16]byte{2}])
+		cloned[[16]byte{1}][0] = "changed"
+		assert.Equal(t, "a", original[[16]byte{1}][0])
+	})
+
+	t.Run("clone funcs still apply to plain keys", func(t *testing.T) {
+		t.Parallel()
+		original := map[[2]int]int{{1, 2}: 3}
+
+		cloned, err := CloneWith(original, WithCloneFunc(func(k [2]int) ([2]int, error) {
+			return [2]int{k[1], k[0]}, nil
+		}))
+
+		require.NoError(t, err)
+		assert.Equal(t, map[[2]int]int{{2, 1}: 3}, cloned)
+	})
+}
+
+// TestCloneMapTypeAliasConversions covers the type alias conversion
+// branches in cloneMap: ConvertibleTo, AssignableTo, and incompatible.
+func TestCloneMapTypeAliasConversions(t *testing.T) {
+	t.Parallel()
+	t.Run("convertible value type", func(t *testing.T) {
+		t.Parallel()
+		type MyInt int
+
+		// map[string]any with a MyInt value — when cloned via reflection,
+		// the concrete type may differ from the map's element type (any),
+		// exercising the ConvertibleTo branch.
+		original := map[string]MyInt{"key": 42}
+		cloned := MustClone(original)
+
+		require.NotNil(t, cloned)
+		assert.Equal(t, MyInt(42), cloned["key"])
+	})
+}
+
+// TestCloneStructFieldTypeConversion covers the type alias conversion
+// path in cloneStruct where a cloned field's type differs from the
+// destination field type and needs Convert().
+func TestCloneStructFieldTypeConversion(t *testing.T) {
+	t.Parallel()
+	type Inner struct {
+		Value int
+	}
+
+	type Outer struct {
+		Field Inner
+	}
+
+	original := Outer{Field: Inner{Value: 42}}
+	cloned := MustClone(original)
+
+	assert.Equal(t, 42, cloned.Field.Value)
+}
+
+// TestCloneMapArrayValues covers map values that are arrays of structs, both
+// reference-free ones copied by value and ones whose structs hold references.
+func TestCloneMapArrayValues(t *testing.T) {
+	t.Parallel()
+	type point struct {
+		X, Y int
+	}
+	type stop struct {
+		Name string
+		Tags []string
+	}
+
+	t.Run("reference-free arrays", func(t *testing.T) {
+		t.Parallel()
+		original := map[string][8]point{
+			"a": {{X: 1, Y: 2}, {X: 3, Y: 4}},
+			"b": {7: {X: 9, Y: 9}},
+		}
+
+		for _, opts := range [][]Option{nil, {WithForceReflection()}} {
+			cloned, err := CloneWith(original, opts...)
+			require.NoError(t, err)
+			assert.Equal(t, original, cloned)
+
+			path := cloned["a"]
+			path[0].X = 100
+			cloned["a"] = path
+			assert.Equal(t, 1, original["a"][0].X)
+		}
+	})
+
+	t.Run("arrays of structs with references", func(t *testing.T) {
+		t.Parallel()
+		original := map[string][2]stop{
+			"route": {{Name: "start", Tags: []string{"depot"}}, {Name: "end", Tags: []string{"hub"}}},
+		}
+
+		cloned := MustClone(original)
+
+		assert.Equal(t, original, cloned)
+		cloned["route"][0].Tags[0] = "changed"
+		assert.Equal(t, "depot", original["route"][0].Tags[0])
+	})
+}
+
+// secretBytes is a named byte slice, as key types often are.
+type secretBytes []byte
+
+// TestCloneSecretBytesDoNotAlias guarantees that every []byte field of a
+// cloned struct gets its own backing array, so wiping a secret in the
+// original leaves the clone's copy intact.
+func TestCloneSecretBytesDoNotAlias(t *testing.T) {
+	t.Parallel()
+	type credentials struct {
+		User    string
+		Secret  []byte
+		Key     secretBytes
+		Nested  *struct{ Token []byte }
+		Backup  [][]byte
+		Wiped   []byte `clone:"zero"`
+		Borrow  []byte `clone:"shallow"`
+		Archive []byte `json:"archive"`
+	}
+	newCredentials := func() *credentials {
+		return &credentials{
+			User:    "svc",
+			Secret:  []byte("s3cret"),
+			Key:     secretBytes("k3y"),
+			Nested:  &struct{ Token []byte }{Token: []byte("t0ken")},
+			Backup:  [][]byte{[]byte("b4ckup")},
+			Wiped:   []byte("wiped"),
+			Borrow:  []byte("borrowed"),
+			Archive: []byte("archive"),
+		}
+	}
+	wipe := func(b []byte) {
+		for i := range b {
+			b[i] = 0
+		}
+	}
+
+	for _, opts := range [][]Option{nil, {WithForceReflection()}, {WithPreserveBackingArrays()}} {
+		original := newCredentials()
+
+		cloned, err := CloneWith(original, opts...)
+		require.NoError(t, err)
+		wipe(original.Secret)
+		wipe(original.Key)
+		wipe(original.Nested.Token)
+		wipe(original.Backup[0])
+		wipe(original.Borrow)
+		wipe(original.Archive)
+
+		assert.Equal(t, []byte("s3cret"), cloned.Secret)
+		assert.Equal(t, secretBytes("k3y"), cloned.Key)
+		assert.Equal(t, []byte("t0ken"), cloned.Nested.Token)
+		assert.Equal(t, []byte("b4ckup"), cloned.Backup[0])
+		assert.Equal(t, []byte("archive"), cloned.Archive)
+		assert.Nil(t, cloned.Wiped, "zero fields should not carry the secret")
+		assert.Equal(t, make([]byte, len("borrowed")), cloned.Borrow, "shallow fields share the secret by design")
+	}
+}
+
+// bitset is a named []uint64 with one bit per index.
+type bitset []uint64
+
+func (b bitset) Set(i int)      { b[i/64] |= 1 << (i % 64) }
+func (b bitset) Clear(i int)    { b[i/64] &^= 1 << (i % 64) }
+func (b bitset) Has(i int) bool { return b[i/64]&(1<<(i%64)) != 0 }
+
+func TestCloneBitsets(t *testing.T) {
+	t.Parallel()
+	type filter struct {
+		Name string
+		Bits bitset
+		Mask []uint32
+	}
+	newBits := func() bitset {
+		bits := make(bitset, 4, 8)
+		bits.Set(1)
+		bits.Set(130)
+		return bits
+	}
+
+	t.Run("top level", func(t *testing.T) {
+		t.Parallel()
+		original := newBits()
+
+		cloned, err := Clone(original)
+
+		require.NoError(t, err)
+		assert.Equal(t, original, cloned)
+		assert.Equal(t, cap(original), cap(cloned))
+		cloned.Set(2)
+		cloned.Clear(130)
+		assert.False(t, original.Has(2))
+		assert.True(t, original.Has(130))
+	})
+
+	t.Run("struct field", func(t *testing.T) {
+		t.Parallel()
+		original := &filter{Name: "bloom", Bits: newBits(), Mask: []uint32{0xff}}
+
+		for _, opts := range [][]Option{nil, {WithForceReflection()}} {
+			cloned, err := CloneWith(original, opts...)
+
+			require.NoError(t, err)
+			assert.Equal(t, original, cloned)
+			cloned.Bits.Clear(1)
+			cloned.Mask[0] = 0
+			assert.True(t, original.Bits.Has(1))
+			assert.Equal(t, uint32(0xff), original.Mask[0])
+		}
+	})
+}
+
+func TestCloneNamedPlainSliceAllocs(t *testing.T) {
+	bits := make(bitset, 16)
+	unnamed := []uint64(bits)
+
+	named := testing.AllocsPerRun(100, func() { _, _ = Clone(bits) })
+	fast := testing.AllocsPerRun(100, func() { _, _ = Clone(unnamed) })
+
+	// The box shared by the reflection checks, the backing array, and the
+	// reflect.MakeSlice header.
+	assert.LessOrEqual(t, named, 3.0)
+	assert.InDelta(t, 1, fast, 0)
+}
+
+// TestCloneFloatBitPatterns checks that NaN payloads and negative zero survive every clone path.
+func TestCloneFloatBitPatterns(t *testing.T) {
+	t.Parallel()
+	values := []float64{
+		math.NaN(),
+		math.Float64frombits(0x7ff8_0000_0000_0abc), // quiet NaN with a payload
+		math.Float64frombits(0x7ff0_0000_0000_0001), // signaling NaN
+		math.Copysign(0, -1),
+		math.Inf(-1),
+	}
+	type sample struct {
+		Value  float64
+		Values []float64
+		Vector [2]float64
+		Boxed  any
+		Single float32
+	}
+	type readings []float64
+	singleNaN := math.Float32frombits(0x7f80_0001)
+
+	assertBits := func(t *testing.T, want, got float64, msg string) {
+		t.Helper()
+		assert.Equal(t, math.Float64bits(want), math.Float64bits(got), msg)
+	}
+
+	for _, opts := range [][]Option{nil, {WithForceReflection()}} {
+		cloned, err := CloneWith(values, opts...)
+		require.NoError(t, err)
+		named, err := CloneWith(readings(values), opts...)
+		require.NoError(t, err)
+		mapped, err := CloneWith(map[string]float64{"v": values[1]}, opts...)
+		require.NoError(t, err)
+		boxed, err := CloneWith(map[string]any{"v": values[2]}, opts...)
+		require.NoError(t, err)
+
+		for i, want := range values {
+			direct, err := CloneWith(want, opts...)
+			require.NoError(t, err)
+			assertBits(t, want, direct, "direct")
+			assertBits(t, want, cloned[i], "slice")
+			assertBits(t, want, named[i], "named slice")
+
+			field, err := CloneWith(&sample{Value: want, Values: []float64{want}, Vector: [2]float64{want}, Boxed: want, Single: singleNaN}, opts...)
+			require.NoError(t, err)
+			assertBits(t, want, field.Value, "struct field")
+			assertBits(t, want, field.Values[0], "nested slice")
+			assertBits(t, want, field.Vector[0], "array")
+			assertBits(t, want, field.Boxed.(float64), "interface")
+			assert.Equal(t, math.Float32bits(singleNaN), math.Float32bits(field.Single), "float32")
+		}
+		assertBits(t, values[1], mapped["v"], "map value")
+		assertBits(t, values[2], boxed["v"].(float64), "dynamic map value")
+	}
+}
+
+// TestCloneComplexPrimitives covers complex64 and complex128 types
+// through the reflection path (via pointer indirection).
+func TestCloneComplexPrimitives(t *testing.T) {
+	t.Parallel()
+	t.Run("complex64", func(t *testing.T) {
+		t.Parallel()
+		v := complex(float32(1.0), float32(2.0))
+		cloned := MustClone(v)
+		assert.Equal(t, v, cloned)
+	})
+
+	t.Run("complex128", func(t *testing.T) {
+		t.Parallel()
+		v := complex(1.0, 2.0)
+		cloned := MustClone(v)
+		assert.Equal(t, v, cloned)
+	})
+
+	t.Run("uintptr", func(t *testing.T) {
+		t.Parallel()
+		v := uintptr(0xDEADBEEF)
+		cloned := MustClone(v)
+		assert.Equal(t, v, cloned)
+	})
+}
+
+// TestCloneChannelViaReflection covers the channel rejection path in
+// cloneValue when a channel is inside an interface.
+func TestCloneChannelViaReflection(t *testing.T) {
+	t.Parallel()
+	type WithInterface struct {
+		Value any
+	}
+
+	ch := make(chan int, 5)
+	original := WithInterface{Value: ch}
+	cloned, err := Clone(original)
+	require.Error(t, err)
+	assert.Zero(t, cloned)
+	assert.Contains(t, err.Error(), "channels cannot be cloned")
+}
+
+func TestCloneStructChannelFieldZeroed(t *testing.T) {
+	t.Parallel()
+	type WithChannel struct {
+		Name string
+		Ch   chan int
+	}
+
+	original := WithChannel{Name: "events", Ch: make(chan int, 1)}
+	cloned, err := Clone(original)
+	require.Error(t, err)
+	assert.Zero(t, cloned)
+	assert.Contains(t, err.Error(), "channels cannot be cloned")
+}
+
+// TestCloneChannelPolicies checks that a channel field is handled by the
+// chosen policy while the rest of the struct is still deep-cloned.
+func TestCloneChannelPolicies(t *testing.T) {
+	t.Parallel()
+	type snapshot struct {
+		Done    chan struct{}
+		Results []int
+	}
+	type tagged struct {
+		Done    chan struct{} `clone:"zero"`
+		Shared  chan struct{} `clone:"share"`
+		Results []int
+	}
+	done := make(chan struct{}, 2)
+	original := snapshot{Done: done, Results: []int{1, 2}}
+	chanType := reflect.TypeFor[chan struct{}]()
+	fresh := WithCloneFunc(func(ch chan struct{}) (chan struct{}, error) {
+		return make(chan struct{}, cap(ch)), nil
+	})
+
+	_, err := Clone(original)
+	var unsupported *UnsupportedError
+	require.ErrorAs(t, err, &unsupported, "channels are rejected by default")
+	assert.Equal(t, "$.Done", unsupported.Path)
+
+	for name, tt := range map[string]struct {
+		opts  []Option
+		check func(t *testing.T, ch chan struct{})
+	}{
+		"nil": {
+			opts:  []Option{WithUnsupportedHook(func(string, reflect.Kind) {})},
+			check: func(t *testing.T, ch chan struct{}) { assert.Nil(t, ch) },
+		},
+		"share": {
+			opts:  []Option{WithShareTypes(chanType)},
+			check: func(t *testing.T, ch chan struct{}) { assert.Equal(t, done, ch) },
+		},
+		"new": {
+			opts: []Option{fresh},
+			check: func(t *testing.T, ch chan struct{}) {
+				assert.NotEqual(t, done, ch)
+				assert.Equal(t, cap(done), cap(ch))
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			cloned, err := CloneWith(original, tt.opts...)
+
+			require.NoError(t, err)
+			tt.check(t, cloned.Done)
+			cloned.Results[0] = 10
+			assert.Equal(t, 1, original.Results[0], "results should be independent")
+		})
+	}
+
+	t.Run("tags", func(t *testing.T) {
+		t.Parallel()
+		original := tagged{Done: done, Shared: done, Results: []int{1}}
+
+		cloned, err := Clone(original)
+
+		require.NoError(t, err)
+		assert.Nil(t, cloned.Done)
+		assert.Equal(t, done, cloned.Shared)
+		cloned.Results[0] = 10
+		assert.Equal(t, 1, original.Results[0])
+	})
+}
+
+// TestCloneFuncViaReflection covers the function rejection path in cloneValue
+// when a func is inside a struct.
+func TestCloneFuncViaReflection(t *testing.T) {
+	t.Parallel()
+	type WithFunc struct {
+		Fn   func() int
+		Name string
+	}
+
+	fn := func() int { return 42 }
+	original := WithFunc{Fn: fn, Name: "test"}
+	cloned, err := Clone(original)
+	require.Error(t, err)
+	assert.Zero(t, cloned)
+	assert.Contains(t, err.Error(), "functions cannot be cloned")
+}
+
+// TestCloneNilInterface covers the nil interface return path in
+// cloneInterface when an interface field holds nil.
+func TestCloneNilInterface(t *testing.T) {
+	t.Parallel()
+	type WithInterface struct {
+		Value any
+	}
+
+	t.Run("nil interface value", func(t *testing.T) {
+		t.Parallel()
+		original := WithInterface{Value: nil}
+		cloned := MustClone(original)
+
+		assert.Nil(t, cloned.Value)
+	})
+
+	t.Run("typed nil pointer value", func(t *testing.T) {
+		t.Parallel()
+		var value *int
+		original := WithInterface{Value: value}
+		cloned := MustClone(original)
+
+		clonedValue, ok := cloned.Value.(*int)
+		require.True(t, ok)
+		assert.Nil(t, clonedValue)
+	})
+}
+
+// sparseNode is a large struct whose pointers are nil in every position a
+// clone can reach them.
+type sparseNode struct {
+	Name     string
+	Left     *sparseNode
+	Right    *sparseNode
+	Parent   *sparseNode
+	Payload  *benchLarge
+	Children []*sparseNode
+	Index    map[string]*sparseNode
+	Slots    [8]*sparseNode
+	Links    struct{ Next, Prev *sparseNode }
+	Boxed    any
+}
+
+// newSparseNode returns a sparseNode with n nil children and n nil-valued
+// index entries.
+func newSparseNode(n int) sparseNode {
+	node := sparseNode{Name: "root", Children: make([]*sparseNode, n), Index: make(map[string]*sparseNode, n)}
+	for i := range n {
+		node.Index[strconv.Itoa(i)] = nil
+	}
+	return node
+}
+
+func TestCloneNilPointersInEveryPosition(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		opts []Option
+	}{
+		{name: "default"},
+		{name: "force reflection", opts: []Option{WithForceReflection()}},
+		{name: "deterministic order", opts: []Option{WithDeterministicOrder()}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			original := newSparseNode(4)
+			original.Boxed = (*sparseNode)(nil)
+
+			cloned, err := CloneWith(&original, tt.opts...)
+
+			require.NoError(t, err)
+			assert.Equal(t, &original, cloned)
+			assert.Nil(t, cloned.Left)
+			assert.Nil(t, cloned.Payload)
+			require.Len(t, cloned.Children, 4)
+			for _, child := range cloned.Children {
+				assert.Nil(t, child)
+			}
+			require.Len(t, cloned.Index, 4)
+			for _, entry := range cloned.Index {
+				assert.Nil(t, entry)
+			}
+			assert.Equal(t, [8]*sparseNode{}, cloned.Slots)
+			assert.Nil(t, cloned.Links.Next)
+			boxed, ok := cloned.Boxed.(*sparseNode)
+			require.True(t, ok, "a typed nil pointer should keep its type")
+			assert.Nil(t, boxed)
+		})
+	}
+}
+
+// TestCloneNilPointerAllocs is not parallel because testing.AllocsPerRun
+// panics in parallel tests.
+func TestCloneNilPointerAllocs(t *testing.T) {
+	few, many := newSparseNode(1), newSparseNode(8)
+	many.Children = make([]*sparseNode, 1000)
+
+	assert.Equal(t,
+		testing.AllocsPerRun(100, func() { _, _ = Clone(&few) }),
+		testing.AllocsPerRun(100, func() { _, _ = Clone(&many) }),
+		"nil pointers should cost no allocation in slices, maps, arrays, and fields")
+}
+
+type nestedCloner struct {
+	Value string
+}
+
+func (n nestedCloner) Clone() (nestedCloner, error) {
+	return nestedCloner{Value: n.Value + " cloned"}, nil
+}
+
+type replacementCloner struct {
+	Value string
+}
+
+type replacementCloneResult struct {
+	Value string
+}
+
+func (r replacementCloner) Clone() (replacementCloneResult, error) {
+	return replacementCloneResult{Value: r.Value + " cloned"}, nil
+}
+
+func TestCloneNestedClonerValues(t *testing.T) {
+	t.Parallel()
+	type Holder struct {
+		Field nestedCloner
+		Iface any
+		Map   map[string]any
+		Slice []any
+	}
+
+	original := Holder{
+		Field: nestedCloner{Value: "field"},
+		Iface: nestedCloner{Value: "iface"},
+		Map:   map[string]any{"item": nestedCloner{Value: "map"}},
+		Slice: []any{nestedCloner{Value: "slice"}},
+	}
+	cloned := MustClone(original)
+
+	assert.Equal(t, "field cloned", cloned.Field.Value)
+	assert.Equal(t, "iface cloned", cloned.Iface.(nestedCloner).Value)
+	assert.Equal(t, "map cloned", cloned.Map["item"].(nestedCloner).Value)
+	assert.Equal(t, "slice cloned", cloned.Slice[0].(nestedCloner).Value)
+}
+
+func TestCloneHeterogeneousClonerMapValues(t *testing.T) {
+	t.Parallel()
+	shared := &rect{Sides: []int{2, 3}}
+	original := map[string]any{
+		"nested": nestedCloner{Value: "n"},
+		"square": square{Side: 4},
+		"rect":   shared,
+		"alias":  shared,
+		"list":   []any{shared, &rect{Sides: []int{5}}},
+		"plain":  1,
+	}
+
+	for name, opts := range map[string][]Option{"dynamic": nil, "reflection": {WithForceReflection()}} {
+		cloned, err := CloneWith(original, opts...)
+		require.NoError(t, err, name)
+
+		assert.Equal(t, "n cloned", cloned["nested"].(nestedCloner).Value, name)
+		assert.Equal(t, 1, cloned["square"].(square).Clones, name)
+		clonedRect := cloned["rect"].(*rect)
+		assert.Equal(t, 1, clonedRect.Clones, name)
+		assert.NotSame(t, shared, clonedRect, name)
+		assert.Same(t, clonedRect, cloned["alias"], "%s: shared pointers should be cloned once", name)
+		list := cloned["list"].([]any)
+		assert.Same(t, clonedRect, list[0], name)
+		assert.Equal(t, 1, list[1].(*rect).Clones, name)
+		assert.Equal(t, 1, cloned["plain"], name)
+		assert.Zero(t, shared.Clones, name)
+	}
+}
+
+// entity is an ORM-style record whose pointer implements Cloner.
+type entity struct {
+	ID     int
+	Tags   []string
+	Clones int
+}
+
+func (e *entity) Clone() (*entity, error) {
+	return &entity{ID: e.ID, Tags: append([]string(nil), e.Tags...), Clones: e.Clones + 1}, nil
+}
+
+func TestClonePointerReceiverClonerSlice(t *testing.T) {
+	t.Parallel()
+	first := &entity{ID: 1, Tags: []string{"a"}}
+	second := &entity{ID: 2}
+	original := []*entity{first, second, first, nil}
+
+	for name, opts := range map[string][]Option{"default": nil, "reflection": {WithForceReflection()}} {
+		cloned, err := CloneWith(original, opts...)
+		require.NoError(t, err, name)
+
+		require.Len(t, cloned, 4, name)
+		for i, e := range cloned[:3] {
+			assert.NotSame(t, original[i], e, name)
+			assert.Equal(t, original[i].ID, e.ID, name)
+			assert.Equal(t, 1, e.Clones, "%s: Clone should run on element %d", name, i)
+		}
+		assert.Same(t, cloned[0], cloned[2], "%s: shared entities should be cloned once", name)
+		assert.Nil(t, cloned[3], name)
+		cloned[0].Tags[0] = "changed"
+		assert.Equal(t, "a", first.Tags[0], name)
+	}
+
+	type page struct {
+		Items []*entity
+		Top   *entity
+	}
+	cloned := MustClone(page{Items: original, Top: first})
+	assert.Same(t, cloned.Items[0], cloned.Top)
+	assert.Equal(t, 1, cloned.Top.Clones)
+
+	expanded, err := CloneWith(original, WithExpandSharedPointers())
+	require.NoError(t, err)
+	assert.NotSame(t, expanded[0], expanded[2], "expanded sharing clones every reference")
+}
+
+type shape interface {
+	Area() int
+}
+
+type square struct {
+	Side   int
+	Clones int
+}
+
+func (s square) Area() int { return s.Side * s.Side }
+
+func (s square) Clone() (square, error) {
+	return square{Side: s.Side, Clones: s.Clones + 1}, nil
+}
+
+type rect struct {
+	Sides  []int
+	Clones int
+}
+
+func (r *rect) Area() int { return r.Sides[0] * r.Sides[1] }
+
+func (r *rect) Clone() (*rect, error) {
+	return &rect{Sides: append([]int(nil), r.Sides...), Clones: r.Clones + 1}, nil
+}
+
+func TestCloneInterfaceSliceUsesElementCloners(t *testing.T) {
+	t.Parallel()
+	sharedRect := &rect{Sides: []int{2, 3}}
+	type canvas struct {
+		Shapes []shape
+	}
+	original := canvas{
+		Shapes: []shape{square{Side: 4}, sharedRect, nil},
+	}
+
+	cloned := MustClone(original)
+
+	require.Len(t, cloned.Shapes, 3)
+	clonedSquare, ok := cloned.Shapes[0].(square)
+	require.True(t, ok)
+	assert.Equal(t, 1, clonedSquare.Clones, "value receiver Clone should run")
+	assert.Equal(t, 16, clonedSquare.Area())
+
+	clonedRect, ok := cloned.Shapes[1].(*rect)
+	require.True(t, ok)
+	assert.Equal(t, 1, clonedRect.Clones, "pointer receiver Clone should run")
+	assert.False(t, clonedRect == sharedRect)
+	assert.Equal(t, 6, clonedRect.Area())
+	assert.Nil(t, cloned.Shapes[2])
+
+	clonedRect.Sides[0] = 10
+	assert.Equal(t, 2, sharedRect.Sides[0])
+	cloned.Shapes[0] = nil
+	assert.NotNil(t, original.Shapes[0])
+}
+
+func TestCloneInterfaceUsesConvertibleClonerResult(t *testing.T) {
+	t.Parallel()
+	t.Run("non-nil cloner", func(t *testing.T) {
+		t.Parallel()
+		original := map[string]any{
+			"item": replacementCloner{Value: "interface"},
+		}
+
+		cloned := MustClone(original)
+
+		result, ok := cloned["item"].(replacementCloner)
+		require.True(t, ok)
+		assert.Equal(t, "interface cloned", result.Value)
+	})
+
+	t.Run("typed nil cloner pointer", func(t *testing.T) {
+		t.Parallel()
+		var item *nilCloner
+		original := map[string]any{"item": item}
+
+		var cloned map[string]any
+		require.NotPanics(t, func() {
+			cloned = MustClone(original)
+		})
+
+		clonedItem, ok := cloned["item"].(*nilCloner)
+		require.True(t, ok)
+		assert.Nil(t, clonedItem)
+	})
+}
+
+// bufferReader is an io.Reader whose state is all exported, so reflection can
+// clone it.
+type bufferReader struct {
+	Data []byte
+	Pos  int
+}
+
+func (r *bufferReader) Read(p []byte) (int, error) {
+	if r.Pos >= len(r.Data) {
+		return 0, io.EOF
+	}
+	n := copy(p, r.Data[r.Pos:])
+	r.Pos += n
+	return n, nil
+}
+
+// countingReader reads nothing but records how often it was cloned.
+type countingReader struct {
+	Clones int
+}
+
+func (r countingReader) Read([]byte) (int, error) { return 0, io.EOF }
+
+func (r countingReader) Clone() (countingReader, error) {
+	return countingReader{Clones: r.Clones + 1}, nil
+}
+
+func TestCloneEmbeddedInterface(t *testing.T) {
+	t.Parallel()
+	type source struct {
+		io.Reader
+		Name string
+	}
+
+	t.Run("concrete implementer", func(t *testing.T) {
+		t.Parallel()
+		reader := &bufferReader{Data: []byte("hello")}
+		original := source{Reader: reader, Name: "in"}
+
+		cloned := MustClone(original)
+
+		clonedReader, ok := cloned.Reader.(*bufferReader)
+		require.True(t, ok, "clone should keep the dynamic type")
+		assert.NotSame(t, reader, clonedReader)
+		data, err := io.ReadAll(cloned)
+		require.NoError(t, err)
+		assert.Equal(t, "hello", string(data))
+		assert.Zero(t, reader.Pos, "reading the clone should not advance the source")
+		clonedReader.Data[0] = 'j'
+		assert.Equal(t, "hello", string(reader.Data))
+	})
+
+	t.Run("custom clone implementer", func(t *testing.T) {
+		t.Parallel()
+		cloned := MustClone(source{Reader: countingReader{}})
+
+		clonedReader, ok := cloned.Reader.(countingReader)
+		require.True(t, ok)
+		assert.Equal(t, 1, clonedReader.Clones, "Clone method should run")
+	})
+}
+
+// labelList clones into its unnamed underlying type, which is assignable but
+// not identical to labelList.
+type labelList []string
+
+func (l labelList) Clone() ([]string, error) {
+	return append([]string(nil), l...), nil
+}
+
+func TestCloneInterfaceKeepsDynamicType(t *testing.T) {
+	t.Parallel()
+	type holder struct {
+		Value  any
+		Labels any
+		Count  any
+	}
+	original := holder{
+		Value:  benchSimple{ID: 1, Name: "value"},
+		Labels: labelList{"a"},
+		Count:  7,
+	}
+
+	cloned := MustClone(original)
+
+	assert.IsType(t, benchSimple{}, cloned.Value)
+	assert.IsType(t, labelList{}, cloned.Labels)
+	assert.IsType(t, 0, cloned.Count)
+	assert.Equal(t, original, cloned)
+	cloned.Labels.(labelList)[0] = "changed"
+	assert.Equal(t, labelList{"a"}, original.Labels)
+}
+
+// TestCloneIgnoresNonConformingCloneMethod covers a Clone method that does not
+// satisfy Cloner[T]. It should be ignored and cloned through reflection.
+func TestCloneIgnoresNonConformingCloneMethod(t *testing.T) {
+	t.Parallel()
+	original := &WrongTypeCloneMethod{Value: "test"}
+	cloned, err := Clone(original)
+
+	require.NoError(t, err)
+	require.NotNil(t, cloned)
+	assert.Equal(t, "test", cloned.Value)
+}
+
+type WrongTypeCloneMethod struct {
+	Value string
+}
+
+func (w *WrongTypeCloneMethod) Clone() any {
+	return "not the right type"
+}
+
+// TestCloneUsesSingleResultCloneMethods covers the standard library
+// convention of a Clone method that returns only the copy.
+func TestCloneUsesSingleResultCloneMethods(t *testing.T) {
+	t.Parallel()
+
+	t.Run("pointer receiver", func(t *testing.T) {
+		t.Parallel()
+		original := &connectionSettings{Host: "example.com", counter: new(int)}
+
+		cloned, err := Clone(original)
+
+		require.NoError(t, err)
+		assert.NotSame(t, original, cloned)
+		assert.Equal(t, "example.com", cloned.Host)
+		assert.True(t, cloned.viaClone)
+	})
+
+	t.Run("tls.Config field", func(t *testing.T) {
+		t.Parallel()
+		type server struct {
+			Name string
+			TLS  *tls.Config
+		}
+		original := &server{Name: "api", TLS: &tls.Config{ServerName: "api.example.com", MinVersion: tls.VersionTLS12}}
+
+		cloned, err := Clone(original)
+
+		require.NoError(t, err)
+		require.NotNil(t, cloned.TLS)
+		assert.NotSame(t, original.TLS, cloned.TLS)
+		assert.Equal(t, "api.example.com", cloned.TLS.ServerName)
+		assert.Equal(t, uint16(tls.VersionTLS12), cloned.TLS.MinVersion)
+	})
+}
+
+// connectionSettings has an unexported pointer that reflection would reject,
+// so only its Clone method can copy it.
+type connectionSettings struct {
+	Host     string
+	counter  *int
+	viaClone bool
+}
+
+func (c *connectionSettings) Clone() *connectionSettings {
+	counter := *c.counter
+	return &connectionSettings{Host: c.Host, counter: &counter, viaClone: true}
+}
+
+// TestCloneSliceSubSliceAliasing verifies that sub-slices sharing the
+// same backing array are not incorrectly aliased via the visited cache.
+func TestCloneSliceSubSliceAliasing(t *testing.T) {
+	t.Parallel()
+	type TwoSlices struct {
+		Full []any
+		Sub  []any
+	}
+
+	full := make([]any, 3, 5)
+	full[0] = "a"
+	full[1] = "b"
+	full[2] = "c"
+	sub := full[:2]
+
+	original := &TwoSlices{Full: full, Sub: sub}
+	cloned := MustClone(original)
+
+	require.NotNil(t, cloned)
+	assert.Len(t, cloned.Full, 3)
+	assert.Len(t, cloned.Sub, 2)
+	assert.Equal(t, 5, cap(cloned.Full))
+	assert.Equal(t, 5, cap(cloned.Sub))
+}
+
+func TestClonePointerToSliceElementDoesNotCollideWithSlice(t *testing.T) {
+	t.Parallel()
+	type node struct {
+		Value int
+	}
+
+	t.Run("pointer before slice", func(t *testing.T) {
+		t.Parallel()
+		type Holder struct {
+			First *node
+			Items []node
+		}
+
+		items := []node{{Value: 1}, {Value: 2}}
+		original := Holder{First: &items[0], Items: items}
+
+		var cloned Holder
+		require.NotPanics(t, func() {
+			cloned = MustClone(original)
+		})
+		require.NotNil(t, cloned.First)
+		assert.Equal(t, 1, cloned.First.Value)
+		if diff := cmp.Diff(items, cloned.Items); diff != "" {
+			t.Errorf("slice field mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("slice before pointer", func(t *testing.T) {
+		t.Parallel()
+		type Holder struct {
+			Items []node
+			First *node
+		}
+
+		items := []node{{Value: 1}, {Value: 2}}
+		original := Holder{Items: items, First: &items[0]}
+		cloned := MustClone(original)
+
+		require.NotNil(t, cloned.First)
+		assert.Equal(t, 1, cloned.First.Value)
+		if diff := cmp.Diff(items, cloned.Items); diff != "" {
+			t.Errorf("slice field mismatch (-want +got):\n%s", diff)
+		}
+	})
+}
+
+type panicCloner struct{}
+
+var errPanicCloner = errors.New("panic cloner")
+
+func (panicCloner) Clone() (panicCloner, error) {
+	panic(errPanicCloner)
+}
+
+func TestClonerPanicPropagates(t *testing.T) {
+	t.Parallel()
+	defer func() {
+		r := recover()
+		require.NotNil(t, r)
+
+		err, ok := r.(error)
+		require.True(t, ok)
+		assert.ErrorIs(t, err, errPanicCloner)
+	}()
+
+	MustClone(panicCloner{})
+}
+
+type errorCloner struct{}
+
+var errCloner = errors.New("cloner error")
+
+func (errorCloner) Clone() (errorCloner, error) {
+	return errorCloner{}, errCloner
+}
+
+func TestClonerErrorPropagates(t *testing.T) {
+	t.Parallel()
+
+	_, err := Clone(errorCloner{})
+	require.ErrorIs(t, err, errCloner)
+}
+
+func TestCloneInterfaceAs(t *testing.T) {
+	t.Parallel()
+
+	t.Run("matching type is cloned", func(t *testing.T) {
+		t.Parallel()
+		original := []int{1, 2}
+
+		cloned, ok, err := CloneInterfaceAs[[]int](any(original))
+
+		require.NoError(t, err)
+		require.True(t, ok)
+		assert.Equal(t, original, cloned)
+		cloned[0] = 10
+		assert.Equal(t, []int{1, 2}, original)
+	})
+
+	t.Run("mismatch reports not ok", func(t *testing.T) {
+		t.Parallel()
+		cloned, ok, err := CloneInterfaceAs[[]string](any([]int{1}))
+
+		require.NoError(t, err)
+		assert.False(t, ok)
+		assert.Nil(t, cloned)
+
+		_, ok, err = CloneInterfaceAs[[]int](nil)
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("interface target uses the concrete Clone method", func(t *testing.T) {
+		t.Parallel()
+		var v any = counterCloner{Value: 1}
+
+		cloned, ok, err := CloneInterfaceAs[fmt.Stringer](v)
+
+		require.NoError(t, err)
+		require.True(t, ok)
+		assert.Equal(t, counterCloner{Value: 2}, cloned)
+	})
+
+	t.Run("clone errors are returned", func(t *testing.T) {
+		t.Parallel()
+		_, ok, err := CloneInterfaceAs[errorCloner](any(errorCloner{}))
+
+		assert.True(t, ok)
+		require.ErrorIs(t, err, errCloner)
+	})
+}
+
+// counterCloner counts clones so tests can tell its Clone method ran.
+type counterCloner struct {
+	Value int
+}
+
+func (c counterCloner) Clone() (counterCloner, error) {
+	return counterCloner{Value: c.Value + 1}, nil
+}
+
+func (c counterCloner) String() string {
+	return strconv.Itoa(c.Value)
+}
+
+// palette is never modified once built, so clones share pointers to it.
+type palette struct {
+	Name   string
+	Colors []string
+}
+
+func (*palette) Immutable() {}
+
+// version is immutable by value, so clones share its parts.
+type version struct {
+	Parts []int
+}
+
+func (version) Immutable() {}
+
+// clonedImmutable is Immutable but also has a Clone method, which wins.
+type clonedImmutable struct {
+	Tags []string
+}
+
+func (clonedImmutable) Immutable() {}
+
+func (c clonedImmutable) Clone() (clonedImmutable, error) {
+	return clonedImmutable{Tags: append([]string{"cloned"}, c.Tags...)}, nil
+}
+
+func TestCloneSharesImmutableTypes(t *testing.T) {
+	t.Parallel()
+	type theme struct {
+		Palette  *palette
+		Palettes []*palette
+		ByName   map[string]*palette
+		Any      any
+		Version  version
+		Value    palette
+		Cloned   clonedImmutable
+	}
+	shared := &palette{Name: "warm", Colors: []string{"red", "orange"}}
+	original := &theme{
+		Palette:  shared,
+		Palettes: []*palette{shared},
+		ByName:   map[string]*palette{"warm": shared},
+		Any:      shared,
+		Version:  version{Parts: []int{1, 2}},
+		Value:    palette{Name: "cold", Colors: []string{"blue"}},
+		Cloned:   clonedImmutable{Tags: []string{"a"}},
+	}
+
+	cloned, disjoint, err := CloneDisjoint(original)
+
+	require.NoError(t, err)
+	assert.False(t, disjoint, "shared immutable values should be reported")
+	assert.NotSame(t, original, cloned)
+	assert.Same(t, shared, cloned.Palette)
+	assert.Same(t, shared, cloned.Palettes[0])
+	assert.Same(t, shared, cloned.ByName["warm"])
+	assert.Same(t, shared, cloned.Any)
+	assert.Same(t, &original.Version.Parts[0], &cloned.Version.Parts[0], "value receivers share values")
+	assert.NotSame(t, &original.Value.Colors[0], &cloned.Value.Colors[0], "a pointer receiver does not share values")
+	assert.Equal(t, []string{"cloned", "a"}, cloned.Cloned.Tags, "a Clone method should win over Immutable")
+	cloned.Palettes[0] = nil
+	assert.Same(t, shared, original.Palettes[0])
+}
+
+// lookupTable is registered by TestCloneSharesRegisteredImmutableSlices.
+type lookupTable []int
+
+// scratchTable has the element type of lookupTable but is not registered.
+type scratchTable []int
+
+func TestCloneSharesRegisteredImmutableSlices(t *testing.T) {
+	t.Parallel()
+	// Registration is global, so the test registers its own type.
+	RegisterImmutableSlice(reflect.TypeFor[lookupTable]())
+	type codec struct {
+		Table   lookupTable
+		Tables  []lookupTable
+		Any     any
+		Scratch scratchTable
+	}
+	table := lookupTable{1, 2, 4, 8}
+	original := &codec{
+		Table:   table,
+		Tables:  []lookupTable{table},
+		Any:     table,
+		Scratch: scratchTable{1, 2},
+	}
+
+	cloned, disjoint, err := CloneDisjoint(original)
+
+	require.NoError(t, err)
+	assert.False(t, disjoint, "shared registered slices should be reported")
+	assert.Same(t, &table[0], &cloned.Table[0])
+	assert.Same(t, &table[0], &cloned.Tables[0][0])
+	assert.Same(t, &table[0], &cloned.Any.(lookupTable)[0])
+	assert.NotSame(t, &original.Tables[0], &cloned.Tables[0], "the slice holding registered slices is still copied")
+	assert.NotSame(t, &original.Scratch[0], &cloned.Scratch[0], "other slice types are still copied")
+
+	top, err := Clone(table)
+	require.NoError(t, err)
+	assert.Same(t, &table[0], &top[0])
+	boxed, err := Clone[any](table)
+	require.NoError(t, err)
+	assert.Same(t, &table[0], &boxed.(lookupTable)[0])
+	assert.Zero(t, EstimateCloneBytes(table))
+
+	assert.Panics(t, func() { RegisterImmutableSlice(reflect.TypeFor[[4]int]()) })
+	assert.Panics(t, func() { RegisterImmutableSlice(nil) })
+}
+
+// priorityItem is an element of byPriority.
+type priorityItem struct {
+	Name     string
+	Priority int
+	Tags     []string
+}
+
+// byPriority sorts items by descending priority through sort.Interface.
+type byPriority []priorityItem
+
+func (p byPriority) Len() int           { return len(p) }
+func (p byPriority) Less(i, j int) bool { return p[i].Priority > p[j].Priority }
+func (p byPriority) Swap(i, j int)      { p[i], p[j] = p[j], p[i] }
+
+// prioritySorter sorts items with a comparator held in a field.
+type prioritySorter struct {
+	Items   []priorityItem
+	Compare func(a, b priorityItem) bool `clone:"shallow"`
+}
+
+func (s prioritySorter) Len() int           { return len(s.Items) }
+func (s prioritySorter) Less(i, j int) bool { return s.Compare(s.Items[i], s.Items[j]) }
+func (s prioritySorter) Swap(i, j int)      { s.Items[i], s.Items[j] = s.Items[j], s.Items[i] }
+
+func TestCloneSortableSlices(t *testing.T) {
+	t.Parallel()
+	newItems := func() []priorityItem {
+		return []priorityItem{
+			{Name: "low", Priority: 1, Tags: []string{"a"}},
+			{Name: "high", Priority: 3},
+			{Name: "mid", Priority: 2, Tags: []string{"b"}},
+		}
+	}
+
+	t.Run("sorting the clone keeps the original order", func(t *testing.T) {
+		t.Parallel()
+		original := byPriority(newItems())
+
+		cloned, err := Clone(original)
+
+		require.NoError(t, err)
+		sort.Sort(cloned)
+		assert.True(t, sort.IsSorted(cloned))
+		assert.Equal(t, []string{"high", "mid", "low"}, []string{cloned[0].Name, cloned[1].Name, cloned[2].Name})
+		assert.Equal(t, byPriority(newItems()), original)
+		cloned[2].Tags[0] = "changed"
+		assert.Equal(t, "a", original[0].Tags[0])
+	})
+
+	t.Run("a sortable slice held in sort.Interface", func(t *testing.T) {
+		t.Parallel()
+		original := byPriority(newItems())
+		var sorter sort.Interface = original
+
+		cloned, err := Clone(sorter)
+
+		require.NoError(t, err)
+		sort.Sort(cloned)
+		assert.IsType(t, byPriority{}, cloned)
+		assert.Equal(t, "high", cloned.(byPriority)[0].Name)
+		assert.Equal(t, byPriority(newItems()), original)
+	})
+
+	t.Run("a shallow comparator field is shared", func(t *testing.T) {
+		t.Parallel()
+		original := prioritySorter{
+			Items:   newItems(),
+			Compare: func(a, b priorityItem) bool { return a.Priority < b.Priority },
+		}
+
+		cloned, err := Clone(original)
+
+		require.NoError(t, err)
+		sort.Sort(cloned)
+		assert.Equal(t, "low", cloned.Items[0].Name)
+		assert.Equal(t, "high", cloned.Items[2].Name)
+		assert.Equal(t, newItems(), original.Items)
+	})
+
+	t.Run("an untagged comparator field follows the function policy", func(t *testing.T) {
+		t.Parallel()
+		type sorter struct {
+			Items []priorityItem
+			Less  func(a, b priorityItem) bool
+		}
+		original := sorter{
+			Items: newItems(),
+			Less:  func(a, b priorityItem) bool { return a.Priority < b.Priority },
+		}
+
+		_, err := Clone(original)
+
+		var unsupported *UnsupportedError
+		require.True(t, errors.As(err, &unsupported))
+		assert.Equal(t, "$.Less", unsupported.Path)
+
+		cloned, err := CloneWith(original, WithUnsupportedHook(func(string, reflect.Kind) {}))
+
+		require.NoError(t, err)
+		assert.Nil(t, cloned.Less, "an accepted function is left out of the clone")
+		assert.Equal(t, original.Items, cloned.Items)
+	})
+}
+
+// article clones its fields through Fields.
+type article struct {
+	Title    string
+	Tags     []string
+	Meta     map[string]any
+	Author   *string
+	Reviewer *string
+}
+
+func (a article) Clone() (article, error) {
+	var f Fields
+	cloned := article{
+		Title:    a.Title,
+		Tags:     CloneField(&f, a.Tags),
+		Meta:     CloneField(&f, a.Meta),
+		Author:   CloneField(&f, a.Author),
+		Reviewer: CloneField(&f, a.Reviewer),
 	}
+	return cloned, f.Err()
+}
+
+func TestCloneField(t *testing.T) {
+	t.Parallel()
+
+	t.Run("fields are deep-cloned", func(t *testing.T) {
+		t.Parallel()
+		author := "ada"
+		original := article{
+			Title:    "clones",
+			Tags:     []string{"go"},
+			Meta:     map[string]any{"draft": []any{1}},
+			Author:   &author,
+			Reviewer: &author,
+		}
+
+		cloned, err := Clone(original)
+
+		require.NoError(t, err)
+		assert.Equal(t, original, cloned)
+		cloned.Tags[0] = "rust"
+		cloned.Meta["draft"].([]any)[0] = 2
+		*cloned.Author = "grace"
+		assert.Equal(t, []string{"go"}, original.Tags)
+		assert.Equal(t, []any{1}, original.Meta["draft"])
+		assert.Equal(t, "ada", author)
+		assert.Same(t, cloned.Author, cloned.Reviewer, "references shared between fields should stay shared")
+	})
 
-	original := Outer{Field: Inner{Value: 42}}
-	cloned := MustClone(original)
+	t.Run("plain and nil values", func(t *testing.T) {
+		t.Parallel()
+		var f Fields
 
-	assert.Equal(t, 42, cloned.Field.Value)
+		assert.Equal(t, 7, CloneField(&f, 7))
+		assert.Nil(t, CloneField[[]int](&f, nil))
+		assert.Nil(t, CloneField[any](&f, nil))
+		assert.Equal(t, any([]int{1}), CloneField[any](&f, []int{1}))
+		assert.NoError(t, f.Err())
+	})
+
+	t.Run("first error is kept", func(t *testing.T) {
+		t.Parallel()
+		var f Fields
+
+		assert.Zero(t, CloneField(&f, errorCloner{}))
+		assert.Nil(t, CloneField(&f, []string{"skipped"}))
+		assert.ErrorIs(t, f.Err(), errCloner)
+	})
 }
 
-// TestCloneComplexPrimitives covers complex64 and complex128 types
-// through the reflection path (via pointer indirection).
-func TestCloneComplexPrimitives(t *testing.T) {
+// TestCloneUnsafePointer covers the unsafe.Pointer rejection path.
+func TestCloneUnsafePointer(t *testing.T) {
 	t.Parallel()
-	t.Run("complex64", func(t *testing.T) {
+	t.Run("direct via interface", func(t *testing.T) {
 		t.Parallel()
-		v := complex(float32(1.0), float32(2.0))
-		cloned := MustClone(v)
-		assert.Equal(t, v, cloned)
+		x := 42
+		ptr := unsafe.Pointer(&x)
+		cloned, err := Clone(ptr)
+
+		require.Error(t, err)
+		assert.Nil(t, cloned)
+		assert.Contains(t, err.Error(), "unsafe pointers cannot be cloned")
 	})
 
-	t.Run("complex128", func(t *testing.T) {
+	t.Run("struct field", func(t *testing.T) {
 		t.Parallel()
-		v := complex(1.0, 2.0)
-		cloned := MustClone(v)
-		assert.Equal(t, v, cloned)
+		type WithUnsafePointer struct {
+			Ptr  unsafe.Pointer
+			Name string
+		}
+
+		x := 99
+		original := WithUnsafePointer{
+			Ptr:  unsafe.Pointer(&x),
+			Name: "test",
+		}
+		cloned, err := Clone(original)
+
+		require.Error(t, err)
+		assert.Zero(t, cloned)
+		assert.Contains(t, err.Error(), "unsafe pointers cannot be cloned")
 	})
 
-	t.Run("uintptr", func(t *testing.T) {
+	t.Run("nil unsafe pointer", func(t *testing.T) {
 		t.Parallel()
-		v := uintptr(0xDEADBEEF)
-		cloned := MustClone(v)
-		assert.Equal(t, v, cloned)
+		type WithUnsafePointer struct {
+			Ptr unsafe.Pointer
+		}
+
+		original := WithUnsafePointer{Ptr: nil}
+		cloned := MustClone(original)
+
+		assert.Equal(t, unsafe.Pointer(nil), cloned.Ptr)
+	})
+
+	t.Run("inside interface field", func(t *testing.T) {
+		t.Parallel()
+		type WithInterface struct {
+			Value any
+		}
+
+		x := 7
+		ptr := unsafe.Pointer(&x)
+		original := WithInterface{Value: ptr}
+		cloned, err := Clone(original)
+
+		require.Error(t, err)
+		assert.Zero(t, cloned)
+		assert.Contains(t, err.Error(), "unsafe pointers cannot be cloned")
 	})
 }
 
-// TestCloneChannelViaReflection covers the channel rejection path in
-// cloneValue when a channel is inside an interface.
-func TestCloneChannelViaReflection(t *testing.T) {
+func TestCloneUnsupportedErrorDetails(t *testing.T) {
 	t.Parallel()
-	type WithInterface struct {
-		Value any
+	type withChannel struct {
+		Ch chan int
 	}
 
-	ch := make(chan int, 5)
-	original := WithInterface{Value: ch}
-	cloned, err := Clone(original)
+	_, err := Clone(withChannel{Ch: make(chan int)})
 	require.Error(t, err)
-	assert.Zero(t, cloned)
-	assert.Contains(t, err.Error(), "channels cannot be cloned")
+
+	var unsupported *UnsupportedError
+	require.ErrorAs(t, err, &unsupported)
+	assert.Equal(t, "$.Ch", unsupported.Path)
+	assert.Equal(t, "channels cannot be cloned", unsupported.Reason)
 }
 
-func TestCloneStructChannelFieldZeroed(t *testing.T) {
+func TestCloneRejectsRuntimeState(t *testing.T) {
 	t.Parallel()
-	type WithChannel struct {
-		Name string
-		Ch   chan int
-	}
 
-	original := WithChannel{Name: "events", Ch: make(chan int, 1)}
-	cloned, err := Clone(original)
-	require.Error(t, err)
-	assert.Zero(t, cloned)
-	assert.Contains(t, err.Error(), "channels cannot be cloned")
+	t.Run("sync primitive", func(t *testing.T) {
+		t.Parallel()
+		type withMutex struct {
+			Mu sync.Mutex
+		}
+
+		_, err := Clone(withMutex{})
+		require.Error(t, err)
+
+		var unsupported *UnsupportedError
+		require.ErrorAs(t, err, &unsupported)
+		assert.Equal(t, "$.Mu", unsupported.Path)
+		assert.Equal(t, "sync primitives cannot be cloned", unsupported.Reason)
+	})
+
+	t.Run("file handle", func(t *testing.T) {
+		t.Parallel()
+		file, err := os.CreateTemp(t.TempDir(), "deepclone-*")
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			require.NoError(t, file.Close())
+		})
+
+		type withFile struct {
+			File *os.File
+		}
+
+		_, err = CloneWith(withFile{File: file}, WithHandlePolicy(RejectHandles))
+		require.Error(t, err)
+
+		var unsupported *UnsupportedError
+		require.ErrorAs(t, err, &unsupported)
+		assert.Equal(t, "$.File", unsupported.Path)
+		assert.Equal(t, "files cannot be cloned", unsupported.Reason)
+	})
+
+	t.Run("buffered stream", func(t *testing.T) {
+		t.Parallel()
+		type withStreams struct {
+			In  *bufio.Reader
+			Out *bufio.Writer `clone:"-"`
+		}
+		original := withStreams{
+			In:  bufio.NewReader(strings.NewReader("data")),
+			Out: bufio.NewWriter(io.Discard),
+		}
+
+		_, err := Clone(original)
+		var unsupported *UnsupportedError
+		require.ErrorAs(t, err, &unsupported)
+		assert.Equal(t, "$.In", unsupported.Path)
+		assert.Equal(t, "buffered streams cannot be cloned", unsupported.Reason)
+
+		cloned, err := CloneExcept(original, reflect.TypeFor[*bufio.Reader]())
+		require.NoError(t, err)
+		assert.Same(t, original.In, cloned.In)
+		assert.Nil(t, cloned.Out)
+	})
 }
 
-// TestCloneFuncViaReflection covers the function rejection path in cloneValue
-// when a func is inside a struct.
-func TestCloneFuncViaReflection(t *testing.T) {
+func TestCloneSyncMap(t *testing.T) {
 	t.Parallel()
-	type WithFunc struct {
-		Fn   func() int
-		Name string
+	type service struct {
+		Name   string
+		Cache  *sync.Map
+		Shared *sync.Map `clone:"shallow"`
+	}
+	newService := func() *service {
+		cache := new(sync.Map)
+		entry := &benchSimple{ID: 1}
+		cache.Store("a", entry)
+		cache.Store("b", entry)
+		cache.Store(1, []int{1, 2})
+		shared := new(sync.Map)
+		shared.Store("hits", 1)
+		return &service{Name: "api", Cache: cache, Shared: shared}
 	}
 
-	fn := func() int { return 42 }
-	original := WithFunc{Fn: fn, Name: "test"}
-	cloned, err := Clone(original)
-	require.Error(t, err)
-	assert.Zero(t, cloned)
-	assert.Contains(t, err.Error(), "functions cannot be cloned")
+	t.Run("deep copy by default", func(t *testing.T) {
+		t.Parallel()
+		original := newService()
+
+		cloned, err := Clone(original)
+
+		require.NoError(t, err)
+		assert.NotSame(t, original.Cache, cloned.Cache)
+		a, _ := cloned.Cache.Load("a")
+		b, _ := cloned.Cache.Load("b")
+		originalA, _ := original.Cache.Load("a")
+		assert.NotSame(t, originalA, a)
+		assert.Same(t, a, b, "values shared between entries should stay shared")
+		a.(*benchSimple).ID = 10
+		assert.Equal(t, 1, originalA.(*benchSimple).ID)
+		numbers, _ := cloned.Cache.Load(1)
+		assert.Equal(t, []int{1, 2}, numbers)
+
+		cloned.Cache.Store("c", 3)
+		cloned.Cache.Delete("b")
+		_, ok := original.Cache.Load("c")
+		assert.False(t, ok)
+		_, ok = original.Cache.Load("b")
+		assert.True(t, ok)
+	})
+
+	t.Run("shallow tag shares", func(t *testing.T) {
+		t.Parallel()
+		original := newService()
+
+		cloned := MustClone(original)
+
+		assert.Same(t, original.Shared, cloned.Shared)
+		cloned.Shared.Store("hits", 2)
+		hits, _ := original.Shared.Load("hits")
+		assert.Equal(t, 2, hits)
+	})
+
+	t.Run("shared by option", func(t *testing.T) {
+		t.Parallel()
+		original := newService()
+
+		cloned, err := CloneWith(original, WithShareTypes(reflect.TypeFor[*sync.Map]()))
+
+		require.NoError(t, err)
+		assert.Same(t, original.Cache, cloned.Cache)
+	})
+
+	t.Run("nil and cyclic maps", func(t *testing.T) {
+		t.Parallel()
+		assert.Nil(t, MustClone(&service{}).Cache)
+
+		original := new(sync.Map)
+		original.Store("self", original)
+
+		cloned := MustClone(original)
+
+		self, _ := cloned.Load("self")
+		assert.Same(t, cloned, self)
+	})
+
+	t.Run("unsupported entries", func(t *testing.T) {
+		t.Parallel()
+		original := new(sync.Map)
+		original.Store("events", make(chan int))
+
+		_, err := Clone(original)
+
+		var unsupported *UnsupportedError
+		require.ErrorAs(t, err, &unsupported)
+		assert.Equal(t, `$["events"]`, unsupported.Path)
+		assert.Equal(t, "channels cannot be cloned", unsupported.Reason)
+	})
+
+	t.Run("held by value", func(t *testing.T) {
+		t.Parallel()
+		type withMap struct {
+			Cache sync.Map
+		}
+
+		_, err := Clone(&withMap{})
+
+		var unsupported *UnsupportedError
+		require.ErrorAs(t, err, &unsupported)
+		assert.Equal(t, "sync primitives cannot be cloned", unsupported.Reason)
+	})
 }
 
-// TestCloneNilInterface covers the nil interface return path in
-// cloneInterface when an interface field holds nil.
-func TestCloneNilInterface(t *testing.T) {
+// atomicConfig is swapped in and out of atomicServer.Config.
+type atomicConfig struct {
+	Name  string
+	Hosts []string
+}
+
+// atomicServer publishes its config through an atomic pointer.
+type atomicServer struct {
+	Config   atomic.Pointer[atomicConfig]
+	Fallback *atomicConfig
+	Shared   atomic.Pointer[atomicConfig] `clone:"shallow"`
+	history  atomic.Pointer[atomicConfig]
+}
+
+func TestCloneAtomicPointer(t *testing.T) {
 	t.Parallel()
-	type WithInterface struct {
-		Value any
+	newServer := func() *atomicServer {
+		server := &atomicServer{}
+		server.Config.Store(&atomicConfig{Name: "primary", Hosts: []string{"a", "b"}})
+		server.Shared.Store(&atomicConfig{Name: "shared"})
+		return server
 	}
 
-	t.Run("nil interface value", func(t *testing.T) {
+	t.Run("pointee is deep-cloned", func(t *testing.T) {
 		t.Parallel()
-		original := WithInterface{Value: nil}
+		original := newServer()
+
+		cloned, err := Clone(original)
+
+		require.NoError(t, err)
+		config := cloned.Config.Load()
+		require.NotNil(t, config)
+		assert.NotSame(t, original.Config.Load(), config)
+		assert.Equal(t, *original.Config.Load(), *config)
+		config.Hosts[0] = "changed"
+		assert.Equal(t, "a", original.Config.Load().Hosts[0])
+
+		cloned.Config.Store(&atomicConfig{Name: "swapped"})
+		assert.Equal(t, "primary", original.Config.Load().Name, "storing into the clone should not reach the original")
+	})
+
+	t.Run("pointee shared with another field stays shared", func(t *testing.T) {
+		t.Parallel()
+		original := newServer()
+		original.Fallback = original.Config.Load()
+
 		cloned := MustClone(original)
 
-		assert.Nil(t, cloned.Value)
+		assert.Same(t, cloned.Fallback, cloned.Config.Load())
+		assert.NotSame(t, original.Fallback, cloned.Fallback)
+	})
+
+	t.Run("shallow tag shares the pointee", func(t *testing.T) {
+		t.Parallel()
+		original := newServer()
+
+		cloned := MustClone(original)
+
+		assert.Same(t, original.Shared.Load(), cloned.Shared.Load())
+	})
+
+	t.Run("nil pointer", func(t *testing.T) {
+		t.Parallel()
+		cloned := MustClone(&atomicServer{})
+
+		assert.Nil(t, cloned.Config.Load())
+	})
+
+	t.Run("unexported atomic pointer keeps the shallow copy", func(t *testing.T) {
+		t.Parallel()
+		original := newServer()
+		original.history.Store(&atomicConfig{Name: "old"})
+
+		cloned, disjoint, err := CloneDisjoint(original)
+
+		require.NoError(t, err)
+		assert.False(t, disjoint)
+		assert.Same(t, original.history.Load(), cloned.history.Load())
+	})
+
+	t.Run("estimate counts the pointee", func(t *testing.T) {
+		t.Parallel()
+		original := newServer()
+
+		assert.Positive(t, EstimateCloneBytes(original))
+		assert.Greater(t, EstimateCloneBytes(original), EstimateCloneBytes(&atomicServer{}))
+	})
+}
+
+func TestCloneUnsupportedErrorPathIncludesIndexAndMapKey(t *testing.T) {
+	t.Parallel()
+
+	t.Run("slice index", func(t *testing.T) {
+		t.Parallel()
+		values := []any{"ok", make(chan int)}
+
+		_, err := Clone(values)
+		require.Error(t, err)
+
+		var unsupported *UnsupportedError
+		require.ErrorAs(t, err, &unsupported)
+		assert.Equal(t, "$[1]", unsupported.Path)
+		assert.Equal(t, "channels cannot be cloned", unsupported.Reason)
 	})
 
-	t.Run("typed nil pointer value", func(t *testing.T) {
+	t.Run("string map key", func(t *testing.T) {
 		t.Parallel()
-		var value *int
-		original := WithInterface{Value: value}
-		cloned := MustClone(original)
+		type worker struct {
+			Ch chan int
+		}
+		workers := map[string]worker{
+			"main": {Ch: make(chan int)},
+		}
 
-		clonedValue, ok := cloned.Value.(*int)
-		require.True(t, ok)
-		assert.Nil(t, clonedValue)
+		_, err := Clone(workers)
+		require.Error(t, err)
+
+		var unsupported *UnsupportedError
+		require.ErrorAs(t, err, &unsupported)
+		assert.Equal(t, `$["main"].Ch`, unsupported.Path)
+		assert.Equal(t, "channels cannot be cloned", unsupported.Reason)
 	})
 }
 
-type nestedCloner struct {
-	Value string
-}
+func TestCloneRejectsUnsupportedUnexportedField(t *testing.T) {
+	t.Parallel()
+	type withHiddenFunc struct {
+		name   string
+		hidden func()
+	}
 
-func (n nestedCloner) Clone() (nestedCloner, error) {
-	return nestedCloner{Value: n.Value + " cloned"}, nil
+	_, err := Clone(withHiddenFunc{name: "x", hidden: func() {}})
+	require.Error(t, err)
+
+	var unsupported *UnsupportedError
+	require.ErrorAs(t, err, &unsupported)
+	assert.Equal(t, "$.hidden", unsupported.Path)
+	assert.Equal(t, "functions cannot be cloned", unsupported.Reason)
 }
 
-type replacementCloner struct {
-	Value string
+type cloneableError struct {
+	Codes []int
 }
 
-type replacementCloneResult struct {
-	Value string
+func (e *cloneableError) Error() string {
+	return "cloneable error"
 }
 
-func (r replacementCloner) Clone() (replacementCloneResult, error) {
-	return replacementCloneResult{Value: r.Value + " cloned"}, nil
+func (e *cloneableError) Clone() (*cloneableError, error) {
+	return &cloneableError{Codes: append([]int(nil), e.Codes...)}, nil
 }
 
-func TestCloneNestedClonerValues(t *testing.T) {
+func TestCloneStandardImages(t *testing.T) {
 	t.Parallel()
-	type Holder struct {
-		Field nestedCloner
-		Iface any
-		Map   map[string]any
-		Slice []any
+	bounds := image.Rect(0, 0, 4, 3)
+	palette := color.Palette{color.Black, color.White}
+	tests := []struct {
+		name string
+		img  draw.Image
+	}{
+		{"RGBA", image.NewRGBA(bounds)},
+		{"RGBA64", image.NewRGBA64(bounds)},
+		{"NRGBA", image.NewNRGBA(bounds)},
+		{"NRGBA64", image.NewNRGBA64(bounds)},
+		{"Alpha", image.NewAlpha(bounds)},
+		{"Gray", image.NewGray(bounds)},
+		{"Gray16", image.NewGray16(bounds)},
+		{"CMYK", image.NewCMYK(bounds)},
+		{"Paletted", image.NewPaletted(bounds, palette)},
+		{"sub-image", image.NewRGBA(image.Rect(0, 0, 8, 8)).SubImage(bounds).(draw.Image)},
 	}
 
-	original := Holder{
-		Field: nestedCloner{Value: "field"},
-		Iface: nestedCloner{Value: "iface"},
-		Map:   map[string]any{"item": nestedCloner{Value: "map"}},
-		Slice: []any{nestedCloner{Value: "slice"}},
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			tt.img.Set(1, 1, color.White)
+
+			cloned, err := Clone[image.Image](tt.img)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.img, cloned)
+			draw.Draw(cloned.(draw.Image), bounds, image.Transparent, image.Point{}, draw.Src)
+			assert.Equal(t, color.Gray{Y: 0xff}, color.GrayModel.Convert(tt.img.At(1, 1)), "original pixels should not alias the clone")
+			assert.Equal(t, color.Gray{}, color.GrayModel.Convert(cloned.At(1, 1)))
+		})
 	}
+}
+
+func TestCloneYCbCrImage(t *testing.T) {
+	t.Parallel()
+	original := image.NewYCbCr(image.Rect(0, 0, 4, 4), image.YCbCrSubsampleRatio420)
+	original.Y[0] = 200
+
 	cloned := MustClone(original)
 
-	assert.Equal(t, "field cloned", cloned.Field.Value)
-	assert.Equal(t, "iface cloned", cloned.Iface.(nestedCloner).Value)
-	assert.Equal(t, "map cloned", cloned.Map["item"].(nestedCloner).Value)
-	assert.Equal(t, "slice cloned", cloned.Slice[0].(nestedCloner).Value)
+	assert.Equal(t, original, cloned)
+	cloned.Y[0] = 0
+	cloned.Cb[0] = 1
+	assert.Equal(t, uint8(200), original.Y[0])
+	assert.Zero(t, original.Cb[0])
 }
 
-func TestCloneInterfaceUsesConvertibleClonerResult(t *testing.T) {
+func TestCloneContextValuesAreShared(t *testing.T) {
 	t.Parallel()
-	t.Run("non-nil cloner", func(t *testing.T) {
-		t.Parallel()
-		original := map[string]any{
-			"item": replacementCloner{Value: "interface"},
-		}
+	type ctxKey struct{}
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), ctxKey{}, "v"))
+	defer cancel()
 
-		cloned := MustClone(original)
+	type request struct {
+		Ctx  context.Context
+		Tags []string
+	}
+	original := request{Ctx: ctx, Tags: []string{"a"}}
 
-		result, ok := cloned["item"].(replacementCloner)
-		require.True(t, ok)
-		assert.Equal(t, "interface cloned", result.Value)
-	})
+	cloned, disjoint, err := CloneDisjoint(original)
 
-	t.Run("typed nil cloner pointer", func(t *testing.T) {
-		t.Parallel()
-		var item *nilCloner
-		original := map[string]any{"item": item}
+	require.NoError(t, err)
+	assert.True(t, cloned.Ctx == original.Ctx, "context should be shared by reference")
+	assert.False(t, disjoint, "shared context should be reported")
+	assert.NotSame(t, &original.Tags[0], &cloned.Tags[0])
+	assert.Equal(t, "v", cloned.Ctx.Value(ctxKey{}))
 
-		var cloned map[string]any
-		require.NotPanics(t, func() {
-			cloned = MustClone(original)
-		})
+	cancel()
+	require.ErrorIs(t, cloned.Ctx.Err(), context.Canceled)
 
-		clonedItem, ok := cloned["item"].(*nilCloner)
-		require.True(t, ok)
-		assert.Nil(t, clonedItem)
-	})
+	top := MustClone(ctx)
+	assert.True(t, top == ctx)
 }
 
-// TestCloneIgnoresNonConformingCloneMethod covers a Clone method that does not
-// satisfy Cloner[T]. It should be ignored and cloned through reflection.
-func TestCloneIgnoresNonConformingCloneMethod(t *testing.T) {
+func TestCloneReflectTypesAreShared(t *testing.T) {
 	t.Parallel()
-	original := &WrongTypeCloneMethod{Value: "test"}
+	type column struct {
+		Name string
+		Type reflect.Type
+		Any  any
+	}
+	type schema struct {
+		Columns []column
+		ByType  map[reflect.Type]string
+	}
+	intType := reflect.TypeFor[int]()
+	structType := reflect.TypeFor[column]()
+	original := &schema{
+		Columns: []column{{Name: "id", Type: intType, Any: structType}},
+		ByType:  map[reflect.Type]string{intType: "id"},
+	}
+
 	cloned, err := Clone(original)
 
 	require.NoError(t, err)
-	require.NotNil(t, cloned)
-	assert.Equal(t, "test", cloned.Value)
-}
-
-type WrongTypeCloneMethod struct {
-	Value string
-}
+	assert.True(t, cloned.Columns[0].Type == intType, "reflect.Type should be shared")
+	assert.True(t, cloned.Columns[0].Any == structType, "reflect.Type in any should be shared")
+	assert.Equal(t, "id", cloned.ByType[intType])
 
-func (w *WrongTypeCloneMethod) Clone() any {
-	return "not the right type"
+	top, err := Clone(structType)
+	require.NoError(t, err)
+	assert.True(t, top == structType)
 }
 
-// TestCloneSliceSubSliceAliasing verifies that sub-slices sharing the
-// same backing array are not incorrectly aliased via the visited cache.
-func TestCloneSliceSubSliceAliasing(t *testing.T) {
+func TestCloneTemplatesAreShared(t *testing.T) {
 	t.Parallel()
-	type TwoSlices struct {
-		Full []any
-		Sub  []any
+	type page struct {
+		Title  string
+		Layout *htmltemplate.Template
+		Plain  *texttemplate.Template
+		Views  map[string]any
+	}
+	newPage := func() *page {
+		layout := htmltemplate.Must(htmltemplate.New("layout").Parse(`<h1>{{.}}</h1>`))
+		plain := texttemplate.Must(texttemplate.New("plain").Parse(`# {{.}}`))
+		return &page{Title: "home", Layout: layout, Plain: plain, Views: map[string]any{"layout": layout}}
+	}
+	render := func(t *testing.T, p *page) (string, string) {
+		t.Helper()
+		var html, text strings.Builder
+		require.NoError(t, p.Layout.Execute(&html, p.Title))
+		require.NoError(t, p.Plain.Execute(&text, p.Title))
+		return html.String(), text.String()
 	}
 
-	full := make([]any, 3, 5)
-	full[0] = "a"
-	full[1] = "b"
-	full[2] = "c"
-	sub := full[:2]
+	t.Run("clone executes shared templates", func(t *testing.T) {
+		t.Parallel()
+		original := newPage()
+		// An html/template that has executed refuses to Clone.
+		render(t, original)
 
-	original := &TwoSlices{Full: full, Sub: sub}
-	cloned := MustClone(original)
+		cloned, shared, err := CloneDisjoint(original)
 
-	require.NotNil(t, cloned)
-	assert.Len(t, cloned.Full, 3)
-	assert.Len(t, cloned.Sub, 2)
-	assert.Equal(t, 5, cap(cloned.Full))
-	assert.Equal(t, 5, cap(cloned.Sub))
+		require.NoError(t, err)
+		assert.False(t, shared)
+		assert.Same(t, original.Layout, cloned.Layout)
+		assert.Same(t, original.Plain, cloned.Plain)
+		assert.Same(t, original.Layout, cloned.Views["layout"])
+		cloned.Title = "<about>"
+		html, text := render(t, cloned)
+		assert.Equal(t, "<h1>&lt;about&gt;</h1>", html)
+		assert.Equal(t, "# <about>", text)
+	})
+
+	t.Run("top-level template", func(t *testing.T) {
+		t.Parallel()
+		original := newPage().Layout
+
+		cloned := MustClone(original)
+
+		assert.Same(t, original, cloned)
+		assert.Zero(t, EstimateCloneBytes(original))
+	})
+
+	t.Run("clone func clones templates", func(t *testing.T) {
+		t.Parallel()
+		original := newPage()
+
+		cloned := MustCloneWith(original, WithCloneFunc(func(t *texttemplate.Template) (*texttemplate.Template, error) {
+			return t.Clone()
+		}))
+
+		assert.NotSame(t, original.Plain, cloned.Plain)
+		assert.Same(t, original.Layout, cloned.Layout)
+		_, text := render(t, cloned)
+		assert.Equal(t, "# home", text)
+	})
 }
 
-func TestClonePointerToSliceElementDoesNotCollideWithSlice(t *testing.T) {
+func TestCloneErrorValuesAreShared(t *testing.T) {
 	t.Parallel()
-	type node struct {
-		Value int
-	}
+	wrapped := fmt.Errorf("read config: %w", io.EOF)
 
-	t.Run("pointer before slice", func(t *testing.T) {
+	t.Run("error slice", func(t *testing.T) {
 		t.Parallel()
-		type Holder struct {
-			First *node
-			Items []node
-		}
+		original := []error{io.EOF, wrapped, nil}
 
-		items := []node{{Value: 1}, {Value: 2}}
-		original := Holder{First: &items[0], Items: items}
+		cloned := MustClone(original)
 
-		var cloned Holder
-		require.NotPanics(t, func() {
-			cloned = MustClone(original)
-		})
-		require.NotNil(t, cloned.First)
-		assert.Equal(t, 1, cloned.First.Value)
-		if diff := cmp.Diff(items, cloned.Items); diff != "" {
-			t.Errorf("slice field mismatch (-want +got):\n%s", diff)
-		}
+		require.Len(t, cloned, 3)
+		assert.True(t, cloned[0] == io.EOF, "sentinel identity should be preserved")
+		require.ErrorIs(t, cloned[0], io.EOF)
+		require.ErrorIs(t, cloned[1], io.EOF)
+		assert.Equal(t, wrapped.Error(), cloned[1].Error())
+		assert.NoError(t, cloned[2])
+		assert.NotSame(t, &original[0], &cloned[0])
 	})
 
-	t.Run("slice before pointer", func(t *testing.T) {
+	t.Run("error map", func(t *testing.T) {
 		t.Parallel()
-		type Holder struct {
-			Items []node
-			First *node
-		}
+		original := map[string]error{"eof": io.EOF, "wrapped": wrapped}
 
-		items := []node{{Value: 1}, {Value: 2}}
-		original := Holder{Items: items, First: &items[0]}
 		cloned := MustClone(original)
 
-		require.NotNil(t, cloned.First)
-		assert.Equal(t, 1, cloned.First.Value)
-		if diff := cmp.Diff(items, cloned.Items); diff != "" {
-			t.Errorf("slice field mismatch (-want +got):\n%s", diff)
+		require.ErrorIs(t, cloned["eof"], io.EOF)
+		require.ErrorIs(t, cloned["wrapped"], io.EOF)
+		cloned["new"] = errCloner
+		assert.NotContains(t, original, "new")
+	})
+
+	t.Run("error field", func(t *testing.T) {
+		t.Parallel()
+		type result struct {
+			Values []int
+			Err    error
 		}
+		original := result{Values: []int{1}, Err: wrapped}
+
+		cloned := MustClone(original)
+
+		assert.True(t, cloned.Err == wrapped)
+		assert.NotSame(t, &original.Values[0], &cloned.Values[0])
 	})
-}
 
-type panicCloner struct{}
+	t.Run("top-level error", func(t *testing.T) {
+		t.Parallel()
+		var original error = wrapped
 
-var errPanicCloner = errors.New("panic cloner")
+		cloned := MustClone(original)
 
-func (panicCloner) Clone() (panicCloner, error) {
-	panic(errPanicCloner)
+		assert.True(t, cloned == wrapped)
+	})
+
+	t.Run("cloner error is cloned", func(t *testing.T) {
+		t.Parallel()
+		custom := &cloneableError{Codes: []int{1, 2}}
+		original := []error{custom}
+
+		cloned := MustClone(original)
+
+		var clonedCustom *cloneableError
+		require.ErrorAs(t, cloned[0], &clonedCustom)
+		assert.False(t, clonedCustom == custom)
+		assert.Equal(t, custom.Codes, clonedCustom.Codes)
+	})
 }
 
-func TestClonerPanicPropagates(t *testing.T) {
+func TestCloneDisjoint(t *testing.T) {
 	t.Parallel()
-	defer func() {
-		r := recover()
-		require.NotNil(t, r)
+	type inner struct {
+		Ref *int
+	}
 
-		err, ok := r.(error)
-		require.True(t, ok)
-		assert.ErrorIs(t, err, errPanicCloner)
-	}()
+	tests := []struct {
+		name     string
+		input    any
+		disjoint bool
+	}{
+		{"primitive", 42, true},
+		{"scalar slice", []int{1, 2}, true},
+		{"nested data", map[string]any{"a": []any{1, "x"}, "b": &inner{}}, true},
+		{"nil error", struct{ Err error }{}, true},
+		{"error interface", struct{ Err error }{Err: io.EOF}, false},
+		{"shallow tagged field", struct {
+			Lookup []string `clone:"shallow"`
+		}{Lookup: []string{"a"}}, false},
+		{"nil shallow tagged field", struct {
+			Lookup []string `clone:"shallow"`
+		}{}, true},
+		{"shallow tagged func", struct {
+			Fn func() `clone:"shallow"`
+		}{Fn: func() {}}, false},
+		{"unexported struct holding a pointer", struct {
+			hidden inner
+		}{hidden: inner{Ref: new(int)}}, false},
+		{"unexported struct holding nil", struct {
+			hidden inner
+		}{}, true},
+	}
 
-	MustClone(panicCloner{})
-}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, disjoint, err := CloneDisjoint(tt.input)
+			require.NoError(t, err)
+			assert.Equal(t, tt.disjoint, disjoint)
+		})
+	}
 
-type errorCloner struct{}
+	t.Run("top-level error", func(t *testing.T) {
+		t.Parallel()
+		cloned, disjoint, err := CloneDisjoint(io.EOF)
 
-var errCloner = errors.New("cloner error")
+		require.NoError(t, err)
+		assert.False(t, disjoint)
+		assert.True(t, cloned == io.EOF)
+	})
 
-func (errorCloner) Clone() (errorCloner, error) {
-	return errorCloner{}, errCloner
+	t.Run("unsupported value", func(t *testing.T) {
+		t.Parallel()
+		cloned, disjoint, err := CloneDisjoint(struct{ Ch chan int }{Ch: make(chan int)})
+
+		var unsupported *UnsupportedError
+		require.ErrorAs(t, err, &unsupported)
+		assert.False(t, disjoint)
+		assert.Nil(t, cloned.Ch)
+	})
 }
 
-func TestClonerErrorPropagates(t *testing.T) {
+func TestSharesByDefault(t *testing.T) {
 	t.Parallel()
+	// Registration is global, so the test registers its own type.
+	RegisterImmutableSlice(reflect.TypeFor[lookupTable]())
+	errorType := reflect.TypeFor[error]()
 
-	_, err := Clone(errorCloner{})
-	require.ErrorIs(t, err, errCloner)
+	tests := []struct {
+		name  string
+		typ   reflect.Type
+		held  reflect.Type
+		share bool
+	}{
+		{"nil type", nil, nil, false},
+		{"plain slice", reflect.TypeFor[[]int](), nil, false},
+		{"immutable value", reflect.TypeFor[version](), nil, true},
+		{"immutable pointer", reflect.TypeFor[*palette](), nil, true},
+		{"value of immutable pointer", reflect.TypeFor[palette](), nil, false},
+		{"immutable with Clone method", reflect.TypeFor[clonedImmutable](), nil, false},
+		{"registered slice", reflect.TypeFor[lookupTable](), nil, true},
+		{"file", reflect.TypeFor[*os.File](), nil, true},
+		{"text template", reflect.TypeFor[*texttemplate.Template](), nil, true},
+		{"html template", reflect.TypeFor[*htmltemplate.Template](), nil, true},
+		{"reflect type", reflect.TypeOf(reflect.TypeFor[int]()), nil, true},
+		{"error in error interface", reflect.TypeOf(io.EOF), errorType, true},
+		{"error in any", reflect.TypeOf(io.EOF), reflect.TypeFor[any](), false},
+		{"error with Clone method", reflect.TypeFor[*cloneableError](), errorType, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.share, SharesByDefault(tt.typ, tt.held))
+		})
+	}
+}
+
+// mappedNode is a graph node for TestCloneWithMapping.
+type mappedNode struct {
+	Name  string
+	Next  *mappedNode
+	Peers []*mappedNode
 }
 
-// TestCloneUnsafePointer covers the unsafe.Pointer rejection path.
-func TestCloneUnsafePointer(t *testing.T) {
+func TestCloneWithMapping(t *testing.T) {
 	t.Parallel()
-	t.Run("direct via interface", func(t *testing.T) {
-		t.Parallel()
-		x := 42
-		ptr := unsafe.Pointer(&x)
-		cloned, err := Clone(ptr)
 
-		require.Error(t, err)
-		assert.Nil(t, cloned)
-		assert.Contains(t, err.Error(), "unsafe pointers cannot be cloned")
-	})
-
-	t.Run("struct field", func(t *testing.T) {
+	t.Run("shared pointers and cycles", func(t *testing.T) {
 		t.Parallel()
-		type WithUnsafePointer struct {
-			Ptr  unsafe.Pointer
-			Name string
+		a := &mappedNode{Name: "a"}
+		b := &mappedNode{Name: "b", Next: a}
+		c := &mappedNode{Name: "c", Next: b}
+		a.Next = c
+		a.Peers = []*mappedNode{b, c}
+		type graph struct {
+			Root  *mappedNode
+			Nodes map[string]*mappedNode
 		}
+		original := &graph{Root: a, Nodes: map[string]*mappedNode{"a": a, "b": b}}
 
-		x := 99
-		original := WithUnsafePointer{
-			Ptr:  unsafe.Pointer(&x),
-			Name: "test",
-		}
-		cloned, err := Clone(original)
+		cloned, mapping, err := CloneWithMapping(original)
 
-		require.Error(t, err)
-		assert.Zero(t, cloned)
-		assert.Contains(t, err.Error(), "unsafe pointers cannot be cloned")
+		require.NoError(t, err)
+		require.Len(t, mapping, 4, "the graph and its three nodes")
+		assert.Same(t, cloned, mapping[original])
+		for _, node := range []*mappedNode{a, b, c} {
+			clonedNode, ok := mapping[node].(*mappedNode)
+			require.True(t, ok, "node %s should be mapped", node.Name)
+			assert.NotSame(t, node, clonedNode)
+			assert.Equal(t, node.Name, clonedNode.Name)
+		}
+		assert.Same(t, cloned.Root, mapping[a])
+		assert.Same(t, cloned.Nodes["b"], mapping[b])
+		assert.Same(t, cloned.Root.Next, mapping[c])
+
+		// An external reference to the original is patched through the mapping.
+		external := map[string]*mappedNode{"current": b}
+		external["current"] = mapping[external["current"]].(*mappedNode)
+		assert.Same(t, cloned.Root.Peers[0], external["current"])
 	})
 
-	t.Run("nil unsafe pointer", func(t *testing.T) {
+	t.Run("pointers into a struct", func(t *testing.T) {
 		t.Parallel()
-		type WithUnsafePointer struct {
-			Ptr unsafe.Pointer
+		type inner struct{ Value int }
+		type holder struct {
+			Inner inner
+			Ref   *inner
 		}
+		original := &holder{}
+		original.Ref = &original.Inner
 
-		original := WithUnsafePointer{Ptr: nil}
-		cloned := MustClone(original)
+		cloned, mapping, err := CloneWithMapping(original)
 
-		assert.Equal(t, unsafe.Pointer(nil), cloned.Ptr)
+		require.NoError(t, err)
+		assert.Same(t, cloned, mapping[original])
+		assert.Same(t, &cloned.Inner, mapping[&original.Inner], "a pointer to a field is keyed by its own type")
 	})
 
-	t.Run("inside interface field", func(t *testing.T) {
+	t.Run("values without pointers", func(t *testing.T) {
 		t.Parallel()
-		type WithInterface struct {
-			Value any
-		}
+		cloned, mapping, err := CloneWithMapping(map[string][]int{"a": {1}})
 
-		x := 7
-		ptr := unsafe.Pointer(&x)
-		original := WithInterface{Value: ptr}
-		cloned, err := Clone(original)
+		require.NoError(t, err)
+		assert.Equal(t, map[string][]int{"a": {1}}, cloned)
+		assert.Empty(t, mapping)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		t.Parallel()
+		_, mapping, err := CloneWithMapping(&struct{ Ch chan int }{Ch: make(chan int)})
 
 		require.Error(t, err)
-		assert.Zero(t, cloned)
-		assert.Contains(t, err.Error(), "unsafe pointers cannot be cloned")
+		assert.Nil(t, mapping)
 	})
 }
 
-func TestCloneUnsupportedErrorDetails(t *testing.T) {
+func TestMustClonePanicsOnUnsupported(t *testing.T) {
 	t.Parallel()
-	type withChannel struct {
-		Ch chan int
-	}
+	ch := make(chan int)
 
-	_, err := Clone(withChannel{Ch: make(chan int)})
-	require.Error(t, err)
+	require.Panics(t, func() {
+		MustClone(ch)
+	})
+}
 
-	var unsupported *UnsupportedError
-	require.ErrorAs(t, err, &unsupported)
-	assert.Equal(t, "$.Ch", unsupported.Path)
-	assert.Equal(t, "channels cannot be cloned", unsupported.Reason)
+// partialCloner returns a partial copy along with its error.
+type partialCloner struct {
+	Name string
 }
 
-func TestCloneRejectsRuntimeState(t *testing.T) {
+func (p partialCloner) Clone() (partialCloner, error) {
+	return partialCloner{Name: p.Name + " (partial)"}, errors.New("clone failed")
+}
+
+func TestCloneOrDefault(t *testing.T) {
 	t.Parallel()
+	type entry struct {
+		Key    string
+		Values []int
+		Notify chan struct{}
+	}
 
-	t.Run("sync primitive", func(t *testing.T) {
+	t.Run("supported value is cloned", func(t *testing.T) {
 		t.Parallel()
-		type withMutex struct {
-			Mu sync.Mutex
-		}
+		original := &entry{Key: "k", Values: []int{1, 2}}
 
-		_, err := Clone(withMutex{})
-		require.Error(t, err)
+		cloned := CloneOrDefault(original)
 
-		var unsupported *UnsupportedError
-		require.ErrorAs(t, err, &unsupported)
-		assert.Equal(t, "$.Mu", unsupported.Path)
-		assert.Equal(t, "sync primitives cannot be cloned", unsupported.Reason)
+		require.NotNil(t, cloned)
+		assert.NotSame(t, original, cloned)
+		assert.Equal(t, original, cloned)
+		cloned.Values[0] = 10
+		assert.Equal(t, []int{1, 2}, original.Values)
 	})
 
-	t.Run("file handle", func(t *testing.T) {
+	t.Run("unclonable field returns zero", func(t *testing.T) {
 		t.Parallel()
-		file, err := os.CreateTemp(t.TempDir(), "deepclone-*")
-		require.NoError(t, err)
-		t.Cleanup(func() {
-			require.NoError(t, file.Close())
-		})
-
-		type withFile struct {
-			File *os.File
-		}
+		original := entry{Key: "k", Values: []int{1}, Notify: make(chan struct{})}
 
-		_, err = Clone(withFile{File: file})
-		require.Error(t, err)
+		assert.Zero(t, CloneOrDefault(original))
+		assert.Nil(t, CloneOrDefault(&original))
+	})
 
-		var unsupported *UnsupportedError
-		require.ErrorAs(t, err, &unsupported)
-		assert.Equal(t, "$.File", unsupported.Path)
-		assert.Equal(t, "files cannot be cloned", unsupported.Reason)
+	t.Run("partial result of a failed Clone method is dropped", func(t *testing.T) {
+		t.Parallel()
+		assert.Zero(t, CloneOrDefault(partialCloner{Name: "p"}))
+		assert.Nil(t, CloneOrDefault(map[string]partialCloner{"p": {Name: "p"}}))
 	})
 }
 
-func TestCloneUnsupportedErrorPathIncludesIndexAndMapKey(t *testing.T) {
+func TestCloneValidated(t *testing.T) {
 	t.Parallel()
+	type ledger struct {
+		Entries []int
+		Total   int
+	}
+	errUnbalanced := errors.New("ledger is unbalanced")
+	balanced := func(l *ledger) error {
+		sum := 0
+		for _, entry := range l.Entries {
+			sum += entry
+		}
+		if sum != l.Total {
+			return errUnbalanced
+		}
+		return nil
+	}
 
-	t.Run("slice index", func(t *testing.T) {
+	t.Run("validated clone is returned", func(t *testing.T) {
 		t.Parallel()
-		values := []any{"ok", make(chan int)}
+		original := &ledger{Entries: []int{1, 2}, Total: 3}
+		var validated *ledger
 
-		_, err := Clone(values)
-		require.Error(t, err)
+		cloned, err := CloneValidated(original, func(l *ledger) error {
+			validated = l
+			return balanced(l)
+		})
 
-		var unsupported *UnsupportedError
-		require.ErrorAs(t, err, &unsupported)
-		assert.Equal(t, "$[1]", unsupported.Path)
-		assert.Equal(t, "channels cannot be cloned", unsupported.Reason)
+		require.NoError(t, err)
+		assert.Same(t, validated, cloned, "the returned clone should be the validated one")
+		assert.NotSame(t, original, cloned)
+		assert.Equal(t, original, cloned)
+		cloned.Entries[0] = 10
+		assert.Equal(t, []int{1, 2}, original.Entries)
 	})
 
-	t.Run("string map key", func(t *testing.T) {
+	t.Run("validation failure discards the clone", func(t *testing.T) {
 		t.Parallel()
-		type worker struct {
-			Ch chan int
-		}
-		workers := map[string]worker{
-			"main": {Ch: make(chan int)},
-		}
+		original := &ledger{Entries: []int{1, 2}, Total: 4}
 
-		_, err := Clone(workers)
-		require.Error(t, err)
+		cloned, err := CloneValidated(original, balanced)
 
-		var unsupported *UnsupportedError
-		require.ErrorAs(t, err, &unsupported)
-		assert.Equal(t, `$["main"].Ch`, unsupported.Path)
-		assert.Equal(t, "channels cannot be cloned", unsupported.Reason)
+		require.ErrorIs(t, err, errUnbalanced)
+		assert.Nil(t, cloned)
 	})
-}
 
-func TestCloneRejectsUnsupportedUnexportedField(t *testing.T) {
-	t.Parallel()
-	type withHiddenFunc struct {
-		name   string
-		hidden func()
-	}
+	t.Run("validation runs on the clone", func(t *testing.T) {
+		t.Parallel()
+		original := &ledger{Entries: []int{1}, Total: 1}
 
-	_, err := Clone(withHiddenFunc{name: "x", hidden: func() {}})
-	require.Error(t, err)
+		_, err := CloneValidated(original, func(l *ledger) error {
+			l.Entries[0] = 5
+			return nil
+		})
 
-	var unsupported *UnsupportedError
-	require.ErrorAs(t, err, &unsupported)
-	assert.Equal(t, "$.hidden", unsupported.Path)
-	assert.Equal(t, "functions cannot be cloned", unsupported.Reason)
-}
+		require.NoError(t, err)
+		assert.Equal(t, []int{1}, original.Entries)
+	})
 
-func TestMustClonePanicsOnUnsupported(t *testing.T) {
-	t.Parallel()
-	ch := make(chan int)
+	t.Run("clone errors skip validation", func(t *testing.T) {
+		t.Parallel()
+		called := false
 
-	require.Panics(t, func() {
-		MustClone(ch)
+		cloned, err := CloneValidated(map[string]any{"ch": make(chan int)}, func(map[string]any) error {
+			called = true
+			return nil
+		})
+
+		var unsupported *UnsupportedError
+		require.ErrorAs(t, err, &unsupported)
+		assert.Nil(t, cloned)
+		assert.False(t, called)
+	})
+
+	t.Run("nil validate", func(t *testing.T) {
+		t.Parallel()
+		cloned, err := CloneValidated([]int{1, 2}, nil)
+
+		require.NoError(t, err)
+		assert.Equal(t, []int{1, 2}, cloned)
 	})
 }
 
@@ -2021,3 +4671,195 @@ func TestCloneUltraDeepNestedStruct(t *testing.T) {
 		assert.False(t, clonedLeaf.Child == original)
 	})
 }
+
+func TestCloneValueArrays(t *testing.T) {
+	t.Parallel()
+
+	t.Run("vector", func(t *testing.T) {
+		t.Parallel()
+		original := [3]float64{1, 2.5, -3}
+
+		cloned := MustClone(original)
+		cloned[0] = 9
+
+		assert.Equal(t, [3]float64{1, 2.5, -3}, original)
+		assert.Equal(t, [3]float64{9, 2.5, -3}, cloned)
+	})
+
+	t.Run("lookup table", func(t *testing.T) {
+		t.Parallel()
+		var original [256]byte
+		for i := range original {
+			original[i] = byte(255 - i)
+		}
+
+		cloned := MustClone(original)
+		cloned[0] = 0
+
+		assert.Equal(t, byte(255), original[0])
+		assert.Equal(t, original[1:], cloned[1:])
+	})
+}
+
+func TestCloneValueArraysZeroAlloc(t *testing.T) {
+	vector := [3]float64{1, 2, 3}
+	var table [256]byte
+
+	assert.Zero(t, testing.AllocsPerRun(100, func() { _, _ = Clone(vector) }))
+	assert.Zero(t, testing.AllocsPerRun(100, func() { _, _ = Clone(table) }))
+}
+
+func TestCloneStructPointerSlices(t *testing.T) {
+	t.Parallel()
+	type user struct {
+		ID      int
+		Name    string
+		Friends []*user
+	}
+
+	t.Run("shared nil and cyclic entries", func(t *testing.T) {
+		t.Parallel()
+		alice := &user{ID: 1, Name: "alice"}
+		bob := &user{ID: 2, Name: "bob", Friends: []*user{alice}}
+		alice.Friends = []*user{bob}
+		original := []*user{alice, nil, bob, alice}
+
+		cloned := MustClone(original)
+
+		require.Len(t, cloned, 4)
+		assert.Nil(t, cloned[1])
+		assert.True(t, cloned[0] == cloned[3], "shared entries should clone once")
+		assert.True(t, cloned[0].Friends[0] == cloned[2], "references between entries should resolve to the cloned entries")
+		assert.True(t, cloned[2].Friends[0] == cloned[0])
+		assert.False(t, cloned[0] == alice)
+		cloned[0].Name = "changed"
+		assert.Equal(t, "alice", alice.Name)
+	})
+
+	t.Run("error path", func(t *testing.T) {
+		t.Parallel()
+		type worker struct {
+			Name string
+			Done chan struct{}
+		}
+		original := []*worker{{Name: "a"}, {Name: "b", Done: make(chan struct{})}}
+
+		_, err := Clone(original)
+
+		var unsupported *UnsupportedError
+		require.ErrorAs(t, err, &unsupported)
+		assert.Equal(t, "$[1].Done", unsupported.Path)
+	})
+
+	t.Run("pointer receiver Clone methods", func(t *testing.T) {
+		t.Parallel()
+		original := []*rect{{Sides: []int{2, 3}}}
+
+		cloned := MustClone(original)
+
+		assert.Equal(t, 1, cloned[0].Clones, "Clone method should be used for each element")
+		assert.Equal(t, 6, cloned[0].Area())
+	})
+
+	t.Run("expanded shared pointers", func(t *testing.T) {
+		t.Parallel()
+		shared := &user{ID: 1}
+		original := []*user{shared, shared}
+
+		cloned := MustCloneWith(original, WithExpandSharedPointers())
+
+		assert.False(t, cloned[0] == cloned[1])
+	})
+}
+
+func TestCloneNamedByteSlices(t *testing.T) {
+	t.Parallel()
+	type blob []byte
+	type digest [4]byte
+	type document struct {
+		Body   blob
+		Raw    []byte
+		Hashes []digest
+	}
+
+	t.Run("named type", func(t *testing.T) {
+		t.Parallel()
+		original := make(blob, 3, 8)
+		copy(original, "abc")
+
+		cloned := MustClone(original)
+
+		assert.Equal(t, original, cloned)
+		assert.Equal(t, 8, cap(cloned))
+		cloned[0] = 'z'
+		assert.Equal(t, blob("abc"), original)
+		assert.Nil(t, MustClone(blob(nil)))
+	})
+
+	t.Run("inside structs", func(t *testing.T) {
+		t.Parallel()
+		original := &document{Body: blob("body"), Raw: []byte("raw"), Hashes: []digest{{1, 2, 3, 4}}}
+
+		cloned := MustClone(original)
+
+		assert.Equal(t, original, cloned)
+		cloned.Body[0] = 'B'
+		cloned.Raw[0] = 'R'
+		cloned.Hashes[0][0] = 9
+		assert.Equal(t, blob("body"), original.Body)
+		assert.Equal(t, []byte("raw"), original.Raw)
+		assert.Equal(t, digest{1, 2, 3, 4}, original.Hashes[0])
+	})
+
+	t.Run("held in interface", func(t *testing.T) {
+		t.Parallel()
+		var original any = blob("iface")
+
+		cloned := MustClone(original)
+
+		require.IsType(t, blob(nil), cloned)
+		cloned.(blob)[0] = 'I'
+		assert.Equal(t, blob("iface"), original)
+	})
+}
+
+// redactedSecrets is a named slice of plain elements whose Clone method must
+// win over the bulk copy of reference-free slices.
+type redactedSecrets []string
+
+func (s redactedSecrets) Clone() (redactedSecrets, error) {
+	cloned := make(redactedSecrets, len(s))
+	for i := range cloned {
+		cloned[i] = "redacted"
+	}
+	return cloned, nil
+}
+
+func TestCloneNamedSliceCloneMethodWithOptions(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		opts []Option
+	}{
+		{name: "default"},
+		{name: "recover", opts: []Option{WithRecover()}},
+		{name: "unrelated structural type", opts: []Option{WithStructuralTypes(reflect.TypeFor[benchSimple]())}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			cloned, err := CloneWith(redactedSecrets{"hunter2"}, tt.opts...)
+
+			require.NoError(t, err)
+			assert.Equal(t, redactedSecrets{"redacted"}, cloned)
+		})
+	}
+
+	t.Run("mapping", func(t *testing.T) {
+		t.Parallel()
+		cloned, _, err := CloneWithMapping(redactedSecrets{"hunter2"})
+
+		require.NoError(t, err)
+		assert.Equal(t, redactedSecrets{"redacted"}, cloned)
+	})
+}
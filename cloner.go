@@ -1,5 +1,13 @@
 package deepclone
 
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
 // Cloner lets a type define its own deep-cloning behavior.
 //
 // Clone must return a copy that can be used independently of the original.
@@ -8,3 +16,214 @@ type Cloner[T any] interface {
 	// Clone returns a copy that can be used independently of the original.
 	Clone() (T, error)
 }
+
+// AfterCloner lets a struct, slice, or map type restore invariants on its
+// clone.
+//
+// With WithAfterClone, AfterClone is called exactly once on each cloned struct
+// after its fields have been cloned, and on each cloned non-nil slice or map
+// after its elements have been cloned, so it can rebuild indexes or caches
+// derived from them. It is called through a pointer to the clone when the
+// clone is addressable, and always for slices and maps, which makes pointer
+// receivers work. Types with a custom Clone method are not called because
+// they clone themselves.
+type AfterCloner interface {
+	AfterClone()
+}
+
+// Immutable marks a type whose values never change after construction, so
+// clones share them instead of copying them.
+//
+// Clone and CloneWith share every value whose type implements Immutable, and
+// report the sharing to CloneDisjoint when the value holds references. A type
+// with a Clone method of its own is cloned by that method instead. Implement
+// Immutable on *T to share pointers to T while values of T are still cloned.
+type Immutable interface {
+	Immutable()
+}
+
+// immutableSlices holds the slice types registered by RegisterImmutableSlice,
+// or nil when there are none. The map is replaced, never modified, so cloning
+// reads it without locking.
+var (
+	immutableSlicesMutex sync.Mutex
+	immutableSlices      atomic.Pointer[map[reflect.Type]struct{}]
+)
+
+// RegisterImmutableSlice makes clones share every slice of type t, which must
+// be a slice type, instead of copying it. It suits lookup tables and static
+// configuration that are never modified, where copying the backing array for
+// each clone only wastes memory. CloneDisjoint reports the sharing.
+//
+// Registration is global and applies wherever t appears, so prefer a named
+// slice type used only for read-only data over a type such as []int; a named
+// type can also implement Immutable instead. A Clone method or WithCloneFunc
+// function for t still takes precedence. Register types during program
+// initialization. RegisterImmutableSlice panics if t is not a slice type.
+func RegisterImmutableSlice(t reflect.Type) {
+	if t == nil || t.Kind() != reflect.Slice {
+		panic("deepclone: RegisterImmutableSlice requires a slice type, got " + fmt.Sprint(t))
+	}
+	immutableSlicesMutex.Lock()
+	defer immutableSlicesMutex.Unlock()
+	registered := make(map[reflect.Type]struct{})
+	if current := immutableSlices.Load(); current != nil {
+		maps.Copy(registered, *current)
+	}
+	registered[t] = struct{}{}
+	immutableSlices.Store(&registered)
+}
+
+// isImmutableSlice reports whether t was registered by RegisterImmutableSlice.
+func isImmutableSlice(t reflect.Type) bool {
+	registered := immutableSlices.Load()
+	if registered == nil || t == nil || t.Kind() != reflect.Slice {
+		return false
+	}
+	_, ok := (*registered)[t]
+	return ok
+}
+
+// forcedStructural holds the types registered by ForceStructural, or nil when
+// there are none. Like immutableSlices, the map is replaced, never modified.
+var (
+	forcedStructuralMutex sync.Mutex
+	forcedStructural      atomic.Pointer[map[reflect.Type]struct{}]
+)
+
+// ForceStructural makes every clone copy values of type t, and pointers to
+// it, field by field with reflection, ignoring their Clone methods, as if each
+// call passed WithStructuralTypes(t). It suits types from other packages whose
+// Clone method changes the value or shares state.
+//
+// Registration is global and cannot be undone, so prefer WithStructuralTypes,
+// or SetDefaultOptions with it, when a program may need the Clone method
+// elsewhere. Register types during program initialization. ForceStructural
+// panics if t is nil.
+func ForceStructural(t reflect.Type) {
+	if t == nil {
+		panic("deepclone: ForceStructural requires a type")
+	}
+	forcedStructuralMutex.Lock()
+	defer forcedStructuralMutex.Unlock()
+	registered := make(map[reflect.Type]struct{})
+	if current := forcedStructural.Load(); current != nil {
+		maps.Copy(registered, *current)
+	}
+	registered[t] = struct{}{}
+	forcedStructural.Store(&registered)
+}
+
+// isForcedStructural reports whether t, or the type t points to, was
+// registered by ForceStructural.
+func isForcedStructural(t reflect.Type) bool {
+	registered := forcedStructural.Load()
+	if registered == nil || t == nil {
+		return false
+	}
+	if _, ok := (*registered)[t]; ok {
+		return true
+	}
+	if t.Kind() == reflect.Pointer {
+		_, ok := (*registered)[t.Elem()]
+		return ok
+	}
+	return false
+}
+
+// registeredCloners holds the functions registered by RegisterCloner, or nil
+// when there are none. Like immutableSlices, the map is replaced, never
+// modified, so options may hold it without copying.
+var (
+	registeredClonersMutex sync.Mutex
+	registeredCloners      atomic.Pointer[map[reflect.Type]func(reflect.Value) (reflect.Value, error)]
+)
+
+// RegisterCloner makes every clone copy values of exactly type T with fn, as
+// if each call passed WithCloneFunc(fn). It suits third-party types used
+// throughout a program that keep their state in unexported pointers, such as
+// a decimal type built on *big.Int, with one registration at startup instead
+// of an option at every call site.
+//
+// A Clone method on T and a WithCloneFunc function for T passed to a call
+// take precedence. Like WithCloneFunc, a registered function turns off the
+// typed fast paths, here for every clone. Functions that ignore the default
+// options, as listed on SetDefaultOptions, ignore registered functions too.
+//
+// Registration is global; register types during program initialization.
+// Registering T again replaces its function, and a nil fn removes it.
+func RegisterCloner[T any](fn func(T) (T, error)) {
+	registeredClonersMutex.Lock()
+	defer registeredClonersMutex.Unlock()
+	registered := make(map[reflect.Type]func(reflect.Value) (reflect.Value, error))
+	if current := registeredCloners.Load(); current != nil {
+		maps.Copy(registered, *current)
+	}
+	if fn == nil {
+		delete(registered, reflect.TypeFor[T]())
+	} else {
+		registered[reflect.TypeFor[T]()] = reflectCloneFunc(fn)
+	}
+	if len(registered) == 0 {
+		registeredCloners.Store(nil)
+		return
+	}
+	registeredCloners.Store(&registered)
+}
+
+// withRegisteredCloners adds the functions registered by RegisterCloner to
+// o, keeping o's own clone funcs where both cover a type.
+func withRegisteredCloners(o options) options {
+	registered := registeredCloners.Load()
+	if registered == nil {
+		return o
+	}
+	if len(o.cloneFuncs) == 0 {
+		o.cloneFuncs = *registered
+		return o
+	}
+	funcs := maps.Clone(*registered)
+	maps.Copy(funcs, o.cloneFuncs)
+	o.cloneFuncs = funcs
+	return o
+}
+
+// Fields collects the first error of a series of CloneField calls, so a Clone
+// method can clone several fields and check for failure once. The zero value
+// is ready to use. Values cloned through one Fields share a clone graph, so
+// references shared between fields stay shared. Use a Fields for a single
+// Clone call.
+type Fields struct {
+	ctx *cloneContext
+	err error
+}
+
+// CloneField returns a deep copy of v like Clone and records any error in f.
+// After a call on f has failed, CloneField returns the zero value without
+// cloning.
+func CloneField[T any](f *Fields, v T) T {
+	var zero T
+	if f.err != nil {
+		return zero
+	}
+	if f.ctx == nil {
+		f.ctx = newCloneContext(newOptions(nil))
+	}
+	if f.ctx.copiesPlain(reflect.TypeFor[T]()) {
+		return v
+	}
+	cloned, err := f.ctx.cloneValue(reflect.ValueOf(&v).Elem(), "$")
+	if err != nil {
+		f.err = err
+		return zero
+	}
+	if !cloned.IsValid() {
+		return v
+	}
+	return valueAs[T](cloned)
+}
+
+// Err returns the first error recorded by CloneField, or nil.
+func (f *Fields) Err() error {
+	return f.err
+}
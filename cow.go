@@ -0,0 +1,170 @@
+package deepclone
+
+import (
+	"iter"
+	"maps"
+	"reflect"
+	"sync/atomic"
+)
+
+var copyOnWriteType = reflect.TypeFor[interface{ copyOnWrite() }]()
+
+// containsCOWMap reports whether values of t hold a COWMap by value, directly
+// or in struct fields and array elements.
+func containsCOWMap(t reflect.Type) bool {
+	if t.Implements(copyOnWriteType) {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		for i := range t.NumField() {
+			if containsCOWMap(t.Field(i).Type) {
+				return true
+			}
+		}
+	case reflect.Array:
+		return t.Len() > 0 && containsCOWMap(t.Elem())
+	}
+	return false
+}
+
+// holdsCOWEntries reports whether v holds a COWMap with entries by value, which
+// a copy by assignment would share without recording the new owner.
+func holdsCOWEntries(v reflect.Value) bool {
+	if v.Type().Implements(copyOnWriteType) {
+		return !v.Field(0).IsNil()
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		for i := range v.NumField() {
+			if holdsCOWEntries(v.Field(i)) {
+				return true
+			}
+		}
+	case reflect.Array:
+		for i := range v.Len() {
+			if holdsCOWEntries(v.Index(i)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// COWMap is a map whose clones share its entries until one of them is written.
+// Clone makes a COWMap in constant time, and the first Set or Delete on either
+// side copies the entries, so large read-mostly maps cost nothing to clone
+// until they diverge. Clone and CloneWith use it for every COWMap they reach by
+// value in an exported field, an element, or an interface, so a copy-on-write
+// clone needs no entry point of its own: declare the maps to share as COWMap
+// fields and call Clone. A non-empty COWMap
+// in an unexported field returns an UnsupportedError, since the clone could
+// not be recorded as an owner of its entries, and a *COWMap is walked field by
+// field and rejected.
+//
+// Entries are copied by assignment, so values must be reference-free or never
+// modified in place; values read from a map that is still shared are shared
+// with its other clones. The zero value is an empty map ready to use. Copy a
+// COWMap with Clone rather than by assignment, and, as with a map, do not write
+// to one COWMap from several goroutines at once. Distinct clones may be used
+// concurrently.
+type COWMap[K comparable, V any] struct {
+	base *cowBase[K, V]
+}
+
+// copyOnWrite marks COWMap types, which reflection cloning must not copy by
+// assignment.
+func (COWMap[K, V]) copyOnWrite() {}
+
+// cowBase holds entries shared by the COWMaps that reference it.
+type cowBase[K comparable, V any] struct {
+	entries map[K]V
+	// owners counts the COWMaps that reference the base. A COWMap may only
+	// write in place while it is the sole owner.
+	owners atomic.Int64
+}
+
+// NewCOWMap returns a COWMap holding the entries of m. The COWMap takes
+// ownership of m, which must not be used afterwards.
+func NewCOWMap[K comparable, V any](m map[K]V) COWMap[K, V] {
+	if m == nil {
+		return COWMap[K, V]{}
+	}
+	base := &cowBase[K, V]{entries: m}
+	base.owners.Store(1)
+	return COWMap[K, V]{base: base}
+}
+
+// Clone returns a COWMap that shares the entries of m until either is written.
+func (m COWMap[K, V]) Clone() (COWMap[K, V], error) {
+	if m.base != nil {
+		m.base.owners.Add(1)
+	}
+	return m, nil
+}
+
+// Get returns the value stored under key and whether it was present.
+func (m COWMap[K, V]) Get(key K) (V, bool) {
+	if m.base == nil {
+		var zero V
+		return zero, false
+	}
+	value, ok := m.base.entries[key]
+	return value, ok
+}
+
+// Len returns the number of entries.
+func (m COWMap[K, V]) Len() int {
+	if m.base == nil {
+		return 0
+	}
+	return len(m.base.entries)
+}
+
+// All returns an iterator over the entries in unspecified order.
+func (m COWMap[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		if m.base == nil {
+			return
+		}
+		for key, value := range m.base.entries {
+			if !yield(key, value) {
+				return
+			}
+		}
+	}
+}
+
+// Set stores value under key, copying the entries first if they are shared.
+func (m *COWMap[K, V]) Set(key K, value V) {
+	m.own()
+	m.base.entries[key] = value
+}
+
+// Delete removes key, copying the entries first if they are shared.
+func (m *COWMap[K, V]) Delete(key K) {
+	if m.base == nil {
+		return
+	}
+	if _, ok := m.base.entries[key]; !ok {
+		return
+	}
+	m.own()
+	delete(m.base.entries, key)
+}
+
+// own makes m the sole owner of its entries. The copy is made before m lets go
+// of the shared base, so the other owners never see it with a single owner
+// while it is still being read.
+func (m *COWMap[K, V]) own() {
+	if m.base == nil {
+		*m = NewCOWMap(make(map[K]V))
+		return
+	}
+	if m.base.owners.Load() == 1 {
+		return
+	}
+	entries := maps.Clone(m.base.entries)
+	m.base.owners.Add(-1)
+	*m = NewCOWMap(entries)
+}
@@ -0,0 +1,124 @@
+package deepclone
+
+import (
+	"maps"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCOWMap(t *testing.T) {
+	t.Parallel()
+
+	t.Run("reads share the base", func(t *testing.T) {
+		t.Parallel()
+		original := NewCOWMap(map[string]int{"a": 1, "b": 2})
+
+		cloned, err := original.Clone()
+
+		require.NoError(t, err)
+		assert.Same(t, original.base, cloned.base, "a clone should not copy entries")
+		value, ok := cloned.Get("a")
+		assert.True(t, ok)
+		assert.Equal(t, 1, value)
+		assert.Equal(t, 2, cloned.Len())
+		assert.Equal(t, map[string]int{"a": 1, "b": 2}, maps.Collect(cloned.All()))
+	})
+
+	t.Run("first write copies", func(t *testing.T) {
+		t.Parallel()
+		original := NewCOWMap(map[string]int{"a": 1, "b": 2})
+		cloned := MustClone(original)
+
+		cloned.Set("a", 10)
+		cloned.Delete("b")
+
+		assert.NotSame(t, original.base, cloned.base)
+		assert.Equal(t, map[string]int{"a": 1, "b": 2}, maps.Collect(original.All()))
+		assert.Equal(t, map[string]int{"a": 10}, maps.Collect(cloned.All()))
+
+		base := original.base
+		original.Set("c", 3)
+		assert.Same(t, base, original.base, "the last owner should write in place")
+		_, ok := cloned.Get("c")
+		assert.False(t, ok)
+	})
+
+	t.Run("writes to the source do not reach clones", func(t *testing.T) {
+		t.Parallel()
+		original := NewCOWMap(map[int]string{1: "a"})
+		first := MustClone(original)
+		second := MustClone(original)
+
+		original.Set(1, "changed")
+
+		for _, cloned := range []COWMap[int, string]{first, second} {
+			value, _ := cloned.Get(1)
+			assert.Equal(t, "a", value)
+		}
+	})
+
+	t.Run("zero value", func(t *testing.T) {
+		t.Parallel()
+		var m COWMap[string, int]
+		assert.Zero(t, m.Len())
+		m.Delete("missing")
+		cloned := MustClone(m)
+
+		m.Set("a", 1)
+
+		assert.Equal(t, 1, m.Len())
+		assert.Zero(t, cloned.Len())
+	})
+
+	t.Run("fields of cloned structs", func(t *testing.T) {
+		t.Parallel()
+		type catalog struct {
+			Name   string
+			Prices COWMap[string, int]
+			Stock  []COWMap[string, int]
+		}
+		original := &catalog{
+			Name:   "fruit",
+			Prices: NewCOWMap(map[string]int{"apple": 3}),
+			Stock:  []COWMap[string, int]{NewCOWMap(map[string]int{"apple": 1})},
+		}
+
+		cloned := MustClone(original)
+
+		assert.Same(t, original.Prices.base, cloned.Prices.base)
+		cloned.Prices.Set("apple", 4)
+		cloned.Stock[0].Set("pear", 5)
+		value, _ := original.Prices.Get("apple")
+		assert.Equal(t, 3, value)
+		assert.Equal(t, 1, original.Stock[0].Len())
+	})
+
+	t.Run("unexported fields are rejected", func(t *testing.T) {
+		t.Parallel()
+		type prices struct {
+			Current COWMap[string, int]
+		}
+		type cache struct {
+			Name    string
+			entries COWMap[string, int]
+			nested  prices
+		}
+
+		_, err := Clone(&cache{Name: "c", entries: NewCOWMap(map[string]int{"x": 1})})
+
+		var unsupported *UnsupportedError
+		require.ErrorAs(t, err, &unsupported)
+		assert.Equal(t, "$.entries", unsupported.Path)
+
+		_, err = Clone(cache{nested: prices{Current: NewCOWMap(map[string]int{"x": 1})}})
+		require.ErrorAs(t, err, &unsupported)
+		assert.Equal(t, "$.nested", unsupported.Path)
+
+		cloned, err := Clone(cache{Name: "empty"})
+		require.NoError(t, err, "an empty COWMap shares no entries")
+		cloned.entries.Set("x", 2)
+		assert.Equal(t, 1, cloned.entries.Len())
+	})
+}
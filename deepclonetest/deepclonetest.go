@@ -0,0 +1,354 @@
+// Package deepclonetest checks that a value is a deep clone of another, for
+// use in the tests of types that implement their own Clone methods or rely on
+// deepclone options.
+//
+// A deep clone must satisfy three properties, which Check verifies in one
+// walk over both graphs:
+//
+//   - It is equal to the original, like reflect.DeepEqual except that NaN
+//     floats equal each other and functions are compared by identity.
+//   - It shares no mutable memory with the original: no pointer, map, or
+//     slice backing array of the clone is also reachable from the original.
+//   - It preserves aliasing: two references to one pointer target, map, or
+//     slice in the original reference one value in the clone, and references
+//     to different values stay different. Cycles are followed once.
+//
+// Values that deepclone.Clone shares by default, as reported by
+// deepclone.SharesByDefault, may be shared and are not reported: Immutable
+// types, slices registered by RegisterImmutableSlice, OS handles such as
+// *os.File, parsed templates, reflect.Type values, and errors and contexts
+// held in interfaces of type error or context.Context. They are still
+// compared for equality when the clone holds a copy. Channels, functions, and
+// unsafe pointers cannot be cloned, so they must be identical. Strings are immutable
+// and compared by value. Other sharing, such as from WithShareTypes or
+// clone:"share" tags, is reported.
+package deepclonetest
+
+import (
+	"fmt"
+	"maps"
+	"math"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/kaptinlin/deepclone"
+)
+
+// AssertDeepClone reports a test error through tb and returns false if clone
+// is not a deep clone of original, as defined by Check.
+func AssertDeepClone(tb testing.TB, original, clone any) bool {
+	tb.Helper()
+	if err := Check(original, clone); err != nil {
+		tb.Errorf("deepclonetest: %v", err)
+		return false
+	}
+	return true
+}
+
+// Check returns nil if clone is a deep clone of original, or an error naming
+// the path of the first difference, using the path syntax of deepclone
+// errors.
+//
+// Map entries are matched by key. Keys that hold references, such as
+// pointers, are matched by searching the clone for an equal key, which is
+// quadratic in the number of entries.
+func Check(original, clone any) error {
+	c := &checker{
+		toClone:    make(map[reference]reference),
+		toOriginal: make(map[reference]reference),
+	}
+	return c.check(reflect.ValueOf(original), reflect.ValueOf(clone), "$")
+}
+
+// reference identifies a pointer target, map, or slice. Slices are identified
+// by their whole header, since slices of one array with different lengths are
+// different values.
+type reference struct {
+	kind     reflect.Kind
+	addr     uintptr
+	typ      reflect.Type
+	len, cap int
+}
+
+// checker pairs the references of the original with those of the clone as
+// the walk finds them. The pairing must stay one to one for aliasing to be
+// preserved.
+type checker struct {
+	toClone    map[reference]reference
+	toOriginal map[reference]reference
+	// shared is set while the walk is inside a value that deepclone shares by
+	// design, where the clone may hold the original's references.
+	shared bool
+}
+
+// pair records that o in the original corresponds to k in the clone. It
+// reports whether the pair was already recorded, or o is shared by design,
+// so the walk does not follow it again.
+func (c *checker) pair(o, k reference, path string) (bool, error) {
+	if o == k {
+		if c.shared {
+			return true, nil
+		}
+		return false, fmt.Errorf("%s: the clone shares a %s with the original", path, o.typ)
+	}
+	if mapped, ok := c.toClone[o]; ok {
+		if mapped != k {
+			return false, fmt.Errorf("%s: the clone does not preserve a %s shared in the original", path, o.typ)
+		}
+		return true, nil
+	}
+	if _, ok := c.toOriginal[k]; ok {
+		return false, fmt.Errorf("%s: the clone shares a %s that is distinct in the original", path, k.typ)
+	}
+	c.toClone[o] = k
+	c.toOriginal[k] = o
+	return false, nil
+}
+
+func (c *checker) check(o, k reflect.Value, path string) error {
+	if !o.IsValid() || !k.IsValid() {
+		if o.IsValid() != k.IsValid() {
+			return fmt.Errorf("%s: one value is nil and the other is not", path)
+		}
+		return nil
+	}
+	if o.Type() != k.Type() {
+		return fmt.Errorf("%s: type %s differs from %s", path, k.Type(), o.Type())
+	}
+	if !c.shared && deepclone.SharesByDefault(o.Type(), nil) {
+		return c.checkShared(o, k, path)
+	}
+
+	switch o.Kind() {
+	case reflect.Pointer:
+		return c.checkPointer(o, k, path)
+	case reflect.Map:
+		return c.checkMap(o, k, path)
+	case reflect.Slice:
+		return c.checkSlice(o, k, path)
+	case reflect.Array:
+		for i := range o.Len() {
+			if err := c.check(o.Index(i), k.Index(i), indexPath(path, i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Struct:
+		for i := range o.NumField() {
+			if err := c.check(o.Field(i), k.Field(i), path+"."+o.Type().Field(i).Name); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Interface:
+		return c.checkInterface(o, k, path)
+	case reflect.Chan, reflect.Func, reflect.UnsafePointer:
+		if o.IsNil() != k.IsNil() || o.Pointer() != k.Pointer() {
+			return fmt.Errorf("%s: %s values cannot be cloned and should be identical", path, o.Type())
+		}
+		return nil
+	case reflect.Float32, reflect.Float64:
+		if a, b := o.Float(), k.Float(); !floatsEqual(a, b) {
+			return fmt.Errorf("%s: %v differs from %v", path, b, a)
+		}
+		return nil
+	case reflect.Complex64, reflect.Complex128:
+		a, b := o.Complex(), k.Complex()
+		if !floatsEqual(real(a), real(b)) || !floatsEqual(imag(a), imag(b)) {
+			return fmt.Errorf("%s: %v differs from %v", path, b, a)
+		}
+		return nil
+	case reflect.Bool:
+		return equal(o.Bool(), k.Bool(), path)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return equal(o.Int(), k.Int(), path)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return equal(o.Uint(), k.Uint(), path)
+	case reflect.String:
+		return equal(o.String(), k.String(), path)
+	case reflect.Invalid:
+	}
+	return nil
+}
+
+func (c *checker) checkPointer(o, k reflect.Value, path string) error {
+	if o.IsNil() || k.IsNil() {
+		if o.IsNil() != k.IsNil() {
+			return fmt.Errorf("%s: one pointer is nil and the other is not", path)
+		}
+		return nil
+	}
+	if o.Type().Elem().Size() != 0 {
+		// Zero-size values may all share one address, so their pointers
+		// carry no identity.
+		seen, err := c.pair(
+			reference{kind: reflect.Pointer, addr: o.Pointer(), typ: o.Type()},
+			reference{kind: reflect.Pointer, addr: k.Pointer(), typ: k.Type()},
+			path)
+		if seen || err != nil {
+			return err
+		}
+	}
+	return c.check(o.Elem(), k.Elem(), path)
+}
+
+func (c *checker) checkMap(o, k reflect.Value, path string) error {
+	if o.IsNil() || k.IsNil() {
+		if o.IsNil() != k.IsNil() {
+			return fmt.Errorf("%s: one map is nil and the other is not", path)
+		}
+		return nil
+	}
+	seen, err := c.pair(
+		reference{kind: reflect.Map, addr: o.Pointer(), typ: o.Type()},
+		reference{kind: reflect.Map, addr: k.Pointer(), typ: k.Type()},
+		path)
+	if seen || err != nil {
+		return err
+	}
+	if o.Len() != k.Len() {
+		return fmt.Errorf("%s: length %d differs from %d", path, k.Len(), o.Len())
+	}
+
+	searched := holdsReferences(o.Type().Key())
+	var unmatched []reflect.Value
+	if searched {
+		unmatched = k.MapKeys()
+	}
+	iter := o.MapRange()
+	for iter.Next() {
+		key := iter.Key()
+		entryPath := mapPath(path, key)
+		cloneKey := key
+		if searched {
+			i := c.matchKey(key, unmatched, entryPath)
+			if i < 0 {
+				return fmt.Errorf("%s: the clone has no matching key", entryPath)
+			}
+			cloneKey = unmatched[i]
+			unmatched = append(unmatched[:i], unmatched[i+1:]...)
+		}
+		value := k.MapIndex(cloneKey)
+		if !value.IsValid() {
+			return fmt.Errorf("%s: the clone has no matching key", entryPath)
+		}
+		if err := c.check(iter.Value(), value, entryPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// matchKey returns the index of the first of candidates that key is cloned
+// to, or -1. Each candidate is checked against a copy of the pairings, which
+// are kept only for the match.
+func (c *checker) matchKey(key reflect.Value, candidates []reflect.Value, path string) int {
+	for i, candidate := range candidates {
+		trial := &checker{toClone: maps.Clone(c.toClone), toOriginal: maps.Clone(c.toOriginal), shared: c.shared}
+		if trial.check(key, candidate, path) == nil {
+			*c = *trial
+			return i
+		}
+	}
+	return -1
+}
+
+func (c *checker) checkSlice(o, k reflect.Value, path string) error {
+	if o.IsNil() || k.IsNil() {
+		if o.IsNil() != k.IsNil() {
+			return fmt.Errorf("%s: one slice is nil and the other is not", path)
+		}
+		return nil
+	}
+	if o.Len() != k.Len() {
+		return fmt.Errorf("%s: length %d differs from %d", path, k.Len(), o.Len())
+	}
+	if size := o.Type().Elem().Size(); size != 0 && o.Cap() > 0 && k.Cap() > 0 {
+		if !c.shared && overlaps(o.Pointer(), o.Cap(), k.Pointer(), k.Cap(), size) {
+			return fmt.Errorf("%s: the clone shares a backing array with the original", path)
+		}
+		seen, err := c.pair(
+			reference{kind: reflect.Slice, addr: o.Pointer(), typ: o.Type(), len: o.Len(), cap: o.Cap()},
+			reference{kind: reflect.Slice, addr: k.Pointer(), typ: k.Type(), len: k.Len(), cap: k.Cap()},
+			path)
+		if seen || err != nil {
+			return err
+		}
+	}
+	for i := range o.Len() {
+		if err := c.check(o.Index(i), k.Index(i), indexPath(path, i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *checker) checkInterface(o, k reflect.Value, path string) error {
+	if o.IsNil() || k.IsNil() {
+		if o.IsNil() != k.IsNil() {
+			return fmt.Errorf("%s: one interface is nil and the other is not", path)
+		}
+		return nil
+	}
+	if !c.shared && deepclone.SharesByDefault(o.Elem().Type(), o.Type()) {
+		return c.checkShared(o.Elem(), k.Elem(), path)
+	}
+	return c.check(o.Elem(), k.Elem(), path)
+}
+
+// checkShared checks o and k, a value that deepclone shares by design, for
+// equality while allowing the clone to hold the original's references.
+func (c *checker) checkShared(o, k reflect.Value, path string) error {
+	c.shared = true
+	defer func() { c.shared = false }()
+	return c.check(o, k, path)
+}
+
+// holdsReferences reports whether values of t can refer to memory, in which
+// case map keys of type t are matched by content rather than looked up.
+func holdsReferences(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Interface, reflect.Chan, reflect.UnsafePointer:
+		return true
+	case reflect.Array:
+		return holdsReferences(t.Elem())
+	case reflect.Struct:
+		for i := range t.NumField() {
+			if holdsReferences(t.Field(i).Type) {
+				return true
+			}
+		}
+		return false
+	default:
+		return false
+	}
+}
+
+// overlaps reports whether the arrays of n and m elements of size bytes at a
+// and b share memory.
+func overlaps(a uintptr, n int, b uintptr, m int, size uintptr) bool {
+	return a < b+uintptr(m)*size && b < a+uintptr(n)*size
+}
+
+func floatsEqual(a, b float64) bool {
+	return a == b || math.IsNaN(a) && math.IsNaN(b)
+}
+
+func equal[T comparable](o, k T, path string) error {
+	if o != k {
+		return fmt.Errorf("%s: %v differs from %v", path, k, o)
+	}
+	return nil
+}
+
+func indexPath(path string, i int) string {
+	return path + "[" + strconv.Itoa(i) + "]"
+}
+
+func mapPath(path string, key reflect.Value) string {
+	if key.Kind() == reflect.String {
+		return path + "[" + strconv.Quote(key.String()) + "]"
+	}
+	return fmt.Sprintf("%s[%v]", path, key)
+}
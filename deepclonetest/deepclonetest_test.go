@@ -0,0 +1,252 @@
+package deepclonetest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"math"
+	"os"
+	"reflect"
+	"testing"
+	"text/template"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kaptinlin/deepclone"
+)
+
+// graphNode is a node of a graph with cycles and shared children.
+type graphNode struct {
+	Name     string
+	Weight   float64
+	Children []*graphNode
+	Parent   *graphNode
+	Labels   map[string]*graphNode
+	Extra    any
+}
+
+func newGraph() *graphNode {
+	root := &graphNode{Name: "root", Weight: math.NaN()}
+	shared := &graphNode{Name: "shared", Parent: root}
+	root.Children = []*graphNode{shared, {Name: "leaf", Parent: root, Children: []*graphNode{shared}}}
+	root.Labels = map[string]*graphNode{"first": shared, "self": root}
+	root.Extra = map[*graphNode]*graphNode{shared: root}
+	return root
+}
+
+// frozenConfig is shared by deepclone instead of cloned.
+type frozenConfig struct {
+	Values []string
+}
+
+func (frozenConfig) Immutable() {}
+
+// recordingTB records the errors AssertDeepClone reports.
+type recordingTB struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recordingTB) Helper() {}
+
+func (r *recordingTB) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestCheckAcceptsDeepClones(t *testing.T) {
+	t.Parallel()
+	self := make([]any, 1)
+	self[0] = self
+
+	tests := []struct {
+		name     string
+		original any
+	}{
+		{name: "cyclic graph", original: newGraph()},
+		{name: "self-referencing slice", original: self},
+		{name: "scalars", original: []any{1, "two", 3.5, math.NaN(), complex(math.NaN(), 1), true, nil}},
+		{name: "errors and contexts", original: struct {
+			Err error
+			Ctx context.Context
+		}{Err: errors.New("boom"), Ctx: context.Background()}},
+		{name: "types", original: map[string]reflect.Type{"int": reflect.TypeFor[int]()}},
+		{name: "immutable values", original: []frozenConfig{{Values: []string{"a"}}}},
+		{name: "nil and empty collections", original: struct {
+			Nil   []int
+			Empty []int
+			Map   map[string]int
+		}{Empty: []int{}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			cloned, err := deepclone.Clone(tt.original)
+			require.NoError(t, err)
+
+			assert.NoError(t, Check(tt.original, cloned))
+			assert.True(t, AssertDeepClone(t, tt.original, cloned))
+		})
+	}
+}
+
+// lookupTable is registered with deepclone.RegisterImmutableSlice in
+// TestCheckAcceptsDefaultSharing.
+type lookupTable []uint32
+
+func TestCheckAcceptsDefaultSharing(t *testing.T) {
+	t.Parallel()
+	// Registration is global, so the test registers its own type.
+	deepclone.RegisterImmutableSlice(reflect.TypeFor[lookupTable]())
+	type report struct {
+		Title string
+		Text  *template.Template
+		Page  *htmltemplate.Template
+		Out   *os.File
+		Table lookupTable
+		Rows  []string
+	}
+	original := &report{
+		Title: "daily",
+		Text:  template.Must(template.New("text").Parse("{{.}}")),
+		Page:  htmltemplate.Must(htmltemplate.New("page").Parse("<p>{{.}}</p>")),
+		Out:   os.Stdout,
+		Table: lookupTable{1, 2, 3},
+		Rows:  []string{"a"},
+	}
+
+	cloned, err := deepclone.Clone(original)
+	require.NoError(t, err)
+	require.Same(t, original.Text, cloned.Text)
+	require.Same(t, original.Out, cloned.Out)
+
+	assert.NoError(t, Check(original, cloned))
+
+	cloned.Rows = original.Rows
+	assert.ErrorContains(t, Check(original, cloned), "$.Rows: the clone shares a backing array",
+		"values outside the default sharing rules are still checked")
+}
+
+func TestCheckRejectsBrokenClones(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name  string
+		clone func(original *graphNode) *graphNode
+		want  string
+	}{
+		{
+			name: "different value",
+			clone: func(original *graphNode) *graphNode {
+				cloned := deepclone.MustClone(original)
+				cloned.Children[1].Name = "changed"
+				return cloned
+			},
+			want: `$.Children[1].Name: changed differs from leaf`,
+		},
+		{
+			name: "shared pointer",
+			clone: func(original *graphNode) *graphNode {
+				cloned := deepclone.MustClone(original)
+				cloned.Children[0] = original.Children[0]
+				return cloned
+			},
+			want: `$.Children[0]: the clone shares a *deepclonetest.graphNode with the original`,
+		},
+		{
+			name: "shared backing array",
+			clone: func(original *graphNode) *graphNode {
+				cloned := deepclone.MustClone(original)
+				cloned.Children = original.Children
+				return cloned
+			},
+			want: `$.Children: the clone shares a backing array with the original`,
+		},
+		{
+			name: "shallow copy",
+			clone: func(original *graphNode) *graphNode {
+				cloned := *original
+				return &cloned
+			},
+			want: `$.Children: the clone shares a backing array with the original`,
+		},
+		{
+			name: "shared map",
+			clone: func(original *graphNode) *graphNode {
+				cloned := deepclone.MustClone(original)
+				cloned.Labels = original.Labels
+				return cloned
+			},
+			want: `$.Labels: the clone shares a map[string]*deepclonetest.graphNode with the original`,
+		},
+		{
+			name: "lost aliasing",
+			clone: func(original *graphNode) *graphNode {
+				cloned := deepclone.MustClone(original)
+				cloned.Children[1].Children[0] = deepclone.MustClone(cloned.Children[1].Children[0])
+				return cloned
+			},
+			want: "does not preserve a *deepclonetest.graphNode shared in the original",
+		},
+		{
+			name: "introduced aliasing",
+			clone: func(original *graphNode) *graphNode {
+				cloned := deepclone.MustClone(original)
+				cloned.Children[1] = cloned.Children[0]
+				return cloned
+			},
+			want: "the clone shares a *deepclonetest.graphNode that is distinct in the original",
+		},
+		{
+			name: "empty instead of nil",
+			clone: func(original *graphNode) *graphNode {
+				cloned := deepclone.MustClone(original)
+				cloned.Children[0].Children = []*graphNode{}
+				return cloned
+			},
+			want: `$.Children[0].Children: one slice is nil and the other is not`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			original := newGraph()
+
+			err := Check(original, tt.clone(original))
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+
+	t.Run("different types", func(t *testing.T) {
+		t.Parallel()
+		err := Check(map[string]any{"n": 1}, map[string]any{"n": int64(1)})
+
+		require.Error(t, err)
+		assert.Equal(t, `$["n"]: type int64 differs from int`, err.Error())
+	})
+
+	t.Run("missing pointer key", func(t *testing.T) {
+		t.Parallel()
+		key := &graphNode{Name: "key"}
+		original := map[*graphNode]int{key: 1}
+
+		err := Check(original, map[*graphNode]int{{Name: "other"}: 1})
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "the clone has no matching key")
+	})
+}
+
+func TestAssertDeepCloneReportsFailures(t *testing.T) {
+	t.Parallel()
+	original := newGraph()
+	tb := &recordingTB{TB: t}
+
+	ok := AssertDeepClone(tb, original, original)
+
+	assert.False(t, ok)
+	require.Len(t, tb.errors, 1)
+	assert.Equal(t, "deepclonetest: $: the clone shares a *deepclonetest.graphNode with the original", tb.errors[0])
+}
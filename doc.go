@@ -3,6 +3,8 @@
 //
 // Clone returns a deep copy or an error when a value cannot be honestly cloned.
 // MustClone is the convenience form for values that are known to be supported.
+// CloneWith and MustCloneWith accept Option values that configure one call.
+// WithMaxCollectionLen reports oversized slices and maps as a LimitError.
 //
 // Reflection cloning preserves supported object graphs, including circular
 // references. Nil pointers, slices, maps, interfaces, channels, functions, and
@@ -10,9 +12,35 @@
 // unsafe pointers are rejected because they represent runtime identity or
 // execution capability rather than ordinary memory-owned data.
 //
+// Values held in error interfaces are shared rather than cloned. Errors are
+// treated as immutable, so sentinel comparisons and errors.Is keep working on
+// the clone. Error types that implement Cloner[T] are still cloned.
+// context.Context values are shared the same way, since a context is immutable
+// by contract and its cancellation wiring must not be duplicated. A
+// reflect.Type is always shared, wherever it is held, and so are OS handles
+// such as *os.File unless WithHandlePolicy says otherwise.
+//
 // The package does not use unsafe to read or write unexported fields. Reflection
 // cloning preserves value-like unexported fields by shallow-copying the struct
 // first, but rejects unexported reference-like state that it cannot safely
 // deep-clone. Types with private invariants or resource ownership should
-// implement Cloner[T] and define their own behavior.
+// implement Cloner[T] and define their own behavior. With WithJSONFallback,
+// structs whose pointer implements json.Marshaler and json.Unmarshaler are
+// cloned by a JSON round trip instead.
+//
+// The clone struct tag overrides how a field is cloned. Options are
+// comma-separated and unknown options are ignored:
+//
+//	Cache  map[string]int `clone:"shallow"`          // share the source value
+//	OnStop func()         `clone:"share"`            // same as shallow
+//	Secret []byte         `clone:"zero"`             // leave the zero value
+//	Token  string         `clone:"-"`                // same as zero
+//	Email  string         `clone:"transform=redact"` // apply a RegisterTransform function
+//
+// Shallow fields skip the unsupported-state checks for references because
+// sharing is explicit, so shared channels, functions, and private references
+// are allowed. Zeroing requires an exported field and is the way to give a
+// clone fresh sync primitives, such as an unlocked mutex. Exported sync.Pool
+// fields need no tag: the clone gets a new, empty pool with the same New
+// function.
 package deepclone
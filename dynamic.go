@@ -0,0 +1,169 @@
+package deepclone
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var (
+	dynamicMapType   = reflect.TypeFor[map[string]any]()
+	dynamicSliceType = reflect.TypeFor[[]any]()
+)
+
+// clonesDynamic reports whether map[string]any and []any values may take the
+// type-switch path, which only differs from the reflection path in speed.
+func (o *options) clonesDynamic() bool {
+	return o.allocator == nil && len(o.shareTypes) == 0 && o.sharePredicate == nil && len(o.cloneFuncs) == 0 &&
+		!o.forceReflection && !o.preserveBacking && !o.deterministicOrder && o.maxDepth == 0 &&
+		o.emptyCollections == keepEmpty
+}
+
+// dynamicPath is the path of a value on the type-switch path. It links to
+// the path of the enclosing container and is only built into a string for
+// errors and values that fall back to cloneValue, so deeply nested arrays do
+// not copy a growing path string at every level.
+type dynamicPath struct {
+	parent *dynamicPath
+	// base is the path of the outermost container, set when parent is nil.
+	base  string
+	key   string
+	index int
+	// keyed reports whether the value is the map entry for key rather than
+	// the slice element at index.
+	keyed bool
+}
+
+// String returns the path in the form built by indexPath and mapValuePath.
+func (p *dynamicPath) String() string {
+	var segments []*dynamicPath
+	for ; p.parent != nil; p = p.parent {
+		segments = append(segments, p)
+	}
+	var b strings.Builder
+	if p.base == "" {
+		b.WriteString("$")
+	} else {
+		b.WriteString(p.base)
+	}
+	for i := len(segments) - 1; i >= 0; i-- {
+		segment := segments[i]
+		b.WriteByte('[')
+		if segment.keyed {
+			b.WriteString(strconv.Quote(segment.key))
+		} else {
+			b.WriteString(strconv.Itoa(segment.index))
+		}
+		b.WriteByte(']')
+	}
+	return b.String()
+}
+
+// cloneDynamicMap clones a map[string]any such as decoded JSON or YAML. The
+// scalars decoders produce, including the int, int64, and uint64 numbers of
+// YAML and TOML, are copied with a type switch instead of being cloned and
+// boxed again through reflection, and paths are only built for errors and
+// values that fall back to cloneValue.
+// Maps and slices are tracked in visited like the reflection path, so shared
+// references and cycles are preserved the same way.
+func (c *cloneContext) cloneDynamicMap(m map[string]any, path *dynamicPath) (map[string]any, error) {
+	if m == nil {
+		return nil, nil
+	}
+	v := reflect.ValueOf(m)
+	key := visitKey{kind: visitMap, addr: v.Pointer(), typ: dynamicMapType}
+	if cloned, exists := c.visited[key]; exists {
+		c.revisit(key)
+		return cloned.Interface().(map[string]any), nil
+	}
+	if c.opts.maxCollectionLen > 0 && len(m) > c.opts.maxCollectionLen {
+		return nil, c.checkCollectionLen(v, path.String())
+	}
+
+	cloned := make(map[string]any, len(m))
+	c.count(visitMap)
+	c.enter(key, reflect.ValueOf(cloned))
+	defer c.leave(key)
+
+	for k, value := range m {
+		if c.copiesDynamic(value) {
+			cloned[k] = value
+			continue
+		}
+		clonedValue, err := c.cloneDynamic(value, &dynamicPath{parent: path, key: k, keyed: true})
+		if err != nil {
+			return nil, err
+		}
+		cloned[k] = clonedValue
+	}
+	return cloned, nil
+}
+
+// cloneDynamicSlice clones a []any with the same rules as cloneDynamicMap.
+func (c *cloneContext) cloneDynamicSlice(s []any, path *dynamicPath) ([]any, error) {
+	if s == nil {
+		return nil, nil
+	}
+	if cap(s) == 0 {
+		return []any{}, nil
+	}
+	if c.opts.maxCollectionLen > 0 && len(s) > c.opts.maxCollectionLen {
+		return nil, c.checkCollectionLen(reflect.ValueOf(s), path.String())
+	}
+
+	// The address of the first element is the slice's Pointer without boxing s.
+	key := visitKey{kind: visitSlice, addr: reflect.ValueOf(&s[:1][0]).Pointer(), typ: dynamicSliceType}
+	if cloned, exists := c.visited[key]; exists && cloned.Len() == len(s) && cloned.Cap() == cap(s) {
+		c.revisit(key)
+		return cloned.Interface().([]any), nil
+	}
+
+	cloned := make([]any, len(s), cap(s))
+	c.count(visitSlice)
+	c.enter(key, reflect.ValueOf(cloned))
+	defer c.leave(key)
+
+	for i, value := range s {
+		if c.copiesDynamic(value) {
+			cloned[i] = value
+			continue
+		}
+		clonedValue, err := c.cloneDynamic(value, &dynamicPath{parent: path, index: i})
+		if err != nil {
+			return nil, err
+		}
+		cloned[i] = clonedValue
+	}
+	return cloned, nil
+}
+
+// copiesDynamic reports whether value, held in a map[string]any or []any, is
+// copied as is. The scalars decoders produce are matched by a type switch;
+// other values of string kind, such as named string types, are immutable like
+// strings unless a Clone method or custom clone function replaces them.
+func (c *cloneContext) copiesDynamic(value any) bool {
+	switch value.(type) {
+	case nil, string, float64, bool, json.Number, int, int64, uint64:
+		return true
+	}
+	t := reflect.TypeOf(value)
+	return t.Kind() == reflect.String && c.copiesPlain(t)
+}
+
+// cloneDynamic clones a value held in a map[string]any or []any that is not a
+// scalar.
+func (c *cloneContext) cloneDynamic(value any, path *dynamicPath) (any, error) {
+	switch value := value.(type) {
+	case map[string]any:
+		return c.cloneDynamicMap(value, path)
+	case []any:
+		return c.cloneDynamicSlice(value, path)
+	}
+
+	cloned, err := c.cloneValue(reflect.ValueOf(value), path.String())
+	if err != nil || !cloned.IsValid() {
+		return value, err
+	}
+	return cloned.Interface(), nil
+}
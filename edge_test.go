@@ -110,6 +110,27 @@ func TestClonePreservesPointerToArrayElement(t *testing.T) {
 	assert.Equal(t, 9, original.Items[1].Value)
 }
 
+func TestClonePreservesPointerToNestedArrayElement(t *testing.T) {
+	t.Parallel()
+	type cell struct {
+		Tags []string
+	}
+	type grid struct {
+		Cells [2][2]cell
+		Ref   *cell
+	}
+
+	original := &grid{}
+	original.Cells[1][0].Tags = []string{"x"}
+	original.Ref = &original.Cells[1][0]
+
+	cloned := MustClone(original)
+
+	require.NotNil(t, cloned.Ref)
+	assert.True(t, cloned.Ref == &cloned.Cells[1][0], "pointer into a nested array should point at the cloned element")
+	assert.Equal(t, []string{"x"}, cloned.Ref.Tags)
+}
+
 func TestClonePreservesMapKeysPointingToValueFields(t *testing.T) {
 	t.Parallel()
 	type node struct {
@@ -233,3 +254,502 @@ func TestClonePreservesMapKeyValueSharedPointer(t *testing.T) {
 	assert.Equal(t, 11, clonedValue.Value)
 	assert.Equal(t, 7, shared.Value)
 }
+
+// routeKey and routeValue are the pointer keys and values of a routing map;
+// each can point at the other, so sharing crosses keys and values.
+type routeKey struct {
+	Name    string
+	Default *routeValue
+}
+
+// routeValue is a map value shared between routeKey entries.
+type routeValue struct {
+	Target string
+	Owner  *routeKey
+}
+
+func TestCloneMapPointerKeysAndValuesShareClones(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		opts []Option
+	}{
+		{name: "default"},
+		{name: "force reflection", opts: []Option{WithForceReflection()}},
+		{name: "deterministic order", opts: []Option{WithDeterministicOrder()}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			primary := &routeValue{Target: "primary"}
+			fallback := &routeValue{Target: "fallback"}
+			home := &routeKey{Name: "home", Default: primary}
+			work := &routeKey{Name: "work", Default: fallback}
+			guest := &routeKey{Name: "guest", Default: primary}
+			primary.Owner = home
+			fallback.Owner = work
+			original := map[*routeKey]*routeValue{home: primary, work: primary, guest: fallback}
+
+			// Map iteration order is randomized, so repeat to reach keys and
+			// values in different orders.
+			for range 20 {
+				cloned, err := CloneWith(original, tt.opts...)
+				require.NoError(t, err)
+				require.Len(t, cloned, 3)
+
+				keys := make(map[string]*routeKey, len(cloned))
+				for key := range cloned {
+					assert.NotSame(t, home, key)
+					assert.NotSame(t, work, key)
+					assert.NotSame(t, guest, key)
+					keys[key.Name] = key
+				}
+				clonedHome, clonedWork, clonedGuest := keys["home"], keys["work"], keys["guest"]
+				require.NotNil(t, clonedHome)
+				require.NotNil(t, clonedWork)
+				require.NotNil(t, clonedGuest)
+
+				clonedPrimary, clonedFallback := cloned[clonedHome], cloned[clonedGuest]
+				assert.Same(t, clonedPrimary, cloned[clonedWork], "a value shared by two entries should stay shared")
+				assert.NotSame(t, primary, clonedPrimary)
+				assert.NotSame(t, fallback, clonedFallback)
+				assert.Same(t, clonedPrimary, clonedHome.Default, "a key field should resolve to the cloned map value")
+				assert.Same(t, clonedPrimary, clonedGuest.Default)
+				assert.Same(t, clonedFallback, clonedWork.Default)
+				assert.Same(t, clonedHome, clonedPrimary.Owner, "a value field should resolve to the cloned map key")
+				assert.Same(t, clonedWork, clonedFallback.Owner)
+
+				clonedPrimary.Target = "changed"
+				clonedHome.Name = "changed"
+				assert.Equal(t, "primary", primary.Target)
+				assert.Equal(t, "home", home.Name)
+			}
+		})
+	}
+}
+
+func TestCloneMapSharingIsIndependentOfIterationOrder(t *testing.T) {
+	t.Parallel()
+	type owner struct {
+		Name string
+	}
+	type item struct {
+		Name  string
+		Owner *owner
+		Next  *item
+	}
+
+	team := &owner{Name: "team"}
+	first := &item{Name: "first", Owner: team}
+	second := &item{Name: "second", Owner: team, Next: first}
+	first.Next = second
+	original := map[string]*item{
+		"a": first, "b": first,
+		"c": second, "d": second,
+		"e": {Name: "solo", Owner: team, Next: first},
+	}
+
+	// Map iteration order is randomized, so repeat to cover different orders.
+	for range 100 {
+		cloned := MustClone(original)
+
+		require.Len(t, cloned, 5)
+		assert.True(t, cloned["a"] == cloned["b"])
+		assert.True(t, cloned["c"] == cloned["d"])
+		assert.True(t, cloned["a"].Next == cloned["c"], "values referencing each other should resolve to the cloned entries")
+		assert.True(t, cloned["c"].Next == cloned["a"])
+		assert.True(t, cloned["e"].Next == cloned["a"])
+		assert.True(t, cloned["a"].Owner == cloned["c"].Owner && cloned["c"].Owner == cloned["e"].Owner)
+		assert.False(t, cloned["a"] == first)
+		assert.False(t, cloned["a"].Owner == team)
+	}
+}
+
+// peerNode is a map value that points at another value of the same map.
+type peerNode struct {
+	Name string
+	Peer *peerNode
+}
+
+func TestCloneMutuallyReferentialMapValues(t *testing.T) {
+	t.Parallel()
+	newPeers := func() (a, b *peerNode) {
+		a = &peerNode{Name: "a"}
+		b = &peerNode{Name: "b", Peer: a}
+		a.Peer = b
+		return a, b
+	}
+	assertPeers := func(t *testing.T, a, b, clonedA, clonedB *peerNode) {
+		t.Helper()
+		require.NotNil(t, clonedA)
+		require.NotNil(t, clonedB)
+		assert.NotSame(t, a, clonedA)
+		assert.NotSame(t, b, clonedB)
+		assert.Same(t, clonedB, clonedA.Peer)
+		assert.Same(t, clonedA, clonedB.Peer)
+	}
+
+	tests := []struct {
+		name string
+		opts []Option
+	}{
+		{name: "default"},
+		{name: "force reflection", opts: []Option{WithForceReflection()}},
+		{name: "deterministic order", opts: []Option{WithDeterministicOrder()}},
+		{name: "preserve backing arrays", opts: []Option{WithPreserveBackingArrays()}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			a, b := newPeers()
+			original := map[string]*peerNode{"a": a, "b": b}
+
+			// Map iteration order is randomized, so repeat to cover both orders.
+			for range 20 {
+				cloned, err := CloneWith(original, tt.opts...)
+
+				require.NoError(t, err)
+				assertPeers(t, a, b, cloned["a"], cloned["b"])
+			}
+		})
+	}
+
+	t.Run("CloneMapInto", func(t *testing.T) {
+		t.Parallel()
+		a, b := newPeers()
+
+		cloned, err := CloneMapInto(map[string]*peerNode{"stale": a}, map[string]*peerNode{"a": a, "b": b})
+
+		require.NoError(t, err)
+		assert.Len(t, cloned, 2)
+		assertPeers(t, a, b, cloned["a"], cloned["b"])
+	})
+
+	t.Run("map[string]any", func(t *testing.T) {
+		t.Parallel()
+		a, b := newPeers()
+		original := map[string]any{"a": a, "nested": map[string]any{"b": b}}
+
+		for range 20 {
+			cloned := MustClone(original)
+
+			clonedA, ok := cloned["a"].(*peerNode)
+			require.True(t, ok)
+			clonedB, ok := cloned["nested"].(map[string]any)["b"].(*peerNode)
+			require.True(t, ok)
+			assertPeers(t, a, b, clonedA, clonedB)
+		}
+	})
+
+	t.Run("values held in a struct field map", func(t *testing.T) {
+		t.Parallel()
+		a, b := newPeers()
+		type cluster struct {
+			Leader *peerNode
+			Nodes  map[string]*peerNode
+		}
+		original := &cluster{Leader: b, Nodes: map[string]*peerNode{"a": a, "b": b}}
+
+		cloned := MustClone(original)
+
+		assertPeers(t, a, b, cloned.Nodes["a"], cloned.Nodes["b"])
+		assert.Same(t, cloned.Nodes["b"], cloned.Leader)
+	})
+}
+
+func TestClonePreservesCyclesThroughPointerArrays(t *testing.T) {
+	t.Parallel()
+	original := &arrayTree{Name: "root"}
+	left := &arrayNode{Value: 1}
+	right := &arrayNode{Value: 2, Sibling: left}
+	left.Sibling = right
+	original.Nodes = [3]*arrayNode{left, right, left}
+	left.Parent = original
+	right.Parent = original
+
+	cloned := MustClone(original)
+
+	require.NotNil(t, cloned.Nodes[0])
+	require.NotNil(t, cloned.Nodes[1])
+	assert.False(t, cloned == original)
+	assert.False(t, cloned.Nodes[0] == left)
+	assert.True(t, cloned.Nodes[0] == cloned.Nodes[2], "a pointer repeated in the array should be cloned once")
+	assert.True(t, cloned.Nodes[0].Parent == cloned, "a node's parent should be the cloned tree")
+	assert.True(t, cloned.Nodes[1].Parent == cloned)
+	assert.True(t, cloned.Nodes[0].Sibling == cloned.Nodes[1], "siblings should point at the cloned nodes")
+	assert.True(t, cloned.Nodes[1].Sibling == cloned.Nodes[0])
+
+	cloned.Nodes[2].Value = 10
+	assert.Equal(t, 10, cloned.Nodes[0].Value)
+	assert.Equal(t, 1, left.Value)
+
+	t.Run("array held by value", func(t *testing.T) {
+		t.Parallel()
+		nodes := [2]*arrayNode{left, right}
+
+		clonedNodes := MustClone(nodes)
+
+		assert.True(t, clonedNodes[0].Sibling == clonedNodes[1])
+		assert.True(t, clonedNodes[1].Sibling == clonedNodes[0])
+		assert.True(t, clonedNodes[0].Parent == clonedNodes[1].Parent)
+		assert.True(t, clonedNodes[0].Parent.Nodes[1] == clonedNodes[1], "the cycle back through the parent should reach the cloned nodes")
+		assert.False(t, clonedNodes[0].Parent == original)
+	})
+}
+
+// arrayTree holds its nodes in an array, and each node points back at it.
+type arrayTree struct {
+	Name  string
+	Nodes [3]*arrayNode
+}
+
+type arrayNode struct {
+	Value   int
+	Parent  *arrayTree
+	Sibling *arrayNode
+}
+
+// sparseRecord has nil reference fields between set ones, so a clone that
+// skipped or misplaced a zero field would show up next to its neighbours.
+type sparseRecord struct {
+	ID       int
+	Any      any
+	Err      error
+	Callback func() int
+	Labels   map[string]string
+	Next     *sparseRecord
+	Tags     []string
+	Done     chan struct{}
+	Name     string
+	Nested   struct {
+		Any    any
+		Labels map[string]string
+		Value  int
+	}
+}
+
+func newSparseRecord(id int) sparseRecord {
+	r := sparseRecord{ID: id, Name: "record"}
+	r.Nested.Value = id * 10
+	return r
+}
+
+func TestClonePreservesZeroFields(t *testing.T) {
+	t.Parallel()
+	type positions struct {
+		Value   sparseRecord
+		Pointer *sparseRecord
+		Slice   []sparseRecord
+		Array   [2]sparseRecord
+		Map     map[string]sparseRecord
+		Any     any
+	}
+	newPositions := func() positions {
+		pointer := newSparseRecord(2)
+		return positions{
+			Value:   newSparseRecord(1),
+			Pointer: &pointer,
+			Slice:   []sparseRecord{newSparseRecord(3), newSparseRecord(4)},
+			Array:   [2]sparseRecord{newSparseRecord(5), newSparseRecord(6)},
+			Map:     map[string]sparseRecord{"a": newSparseRecord(7)},
+			Any:     newSparseRecord(8),
+		}
+	}
+
+	tests := []struct {
+		name string
+		opts []Option
+	}{
+		{name: "default"},
+		{name: "force reflection", opts: []Option{WithForceReflection()}},
+		{name: "deterministic order", opts: []Option{WithDeterministicOrder()}},
+		{name: "preserve backing arrays", opts: []Option{WithPreserveBackingArrays()}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			original := newPositions()
+
+			cloned, err := CloneWith(original, tt.opts...)
+
+			require.NoError(t, err)
+			assert.Equal(t, newPositions(), cloned)
+			assert.NotSame(t, original.Pointer, cloned.Pointer)
+			for _, r := range []sparseRecord{
+				cloned.Value, *cloned.Pointer, cloned.Slice[0], cloned.Slice[1],
+				cloned.Array[0], cloned.Array[1], cloned.Map["a"], cloned.Any.(sparseRecord),
+			} {
+				assert.Nil(t, r.Any)
+				assert.Nil(t, r.Err)
+				assert.Nil(t, r.Callback)
+				assert.Nil(t, r.Labels)
+				assert.Nil(t, r.Next)
+				assert.Nil(t, r.Tags)
+				assert.Nil(t, r.Done)
+				assert.Nil(t, r.Nested.Any)
+				assert.Nil(t, r.Nested.Labels)
+				assert.Equal(t, "record", r.Name)
+				assert.Equal(t, r.ID*10, r.Nested.Value)
+			}
+		})
+	}
+
+	t.Run("reused destination elements are overwritten", func(t *testing.T) {
+		t.Parallel()
+		stale := sparseRecord{
+			ID:       -1,
+			Any:      "stale",
+			Callback: func() int { return -1 },
+			Labels:   map[string]string{"stale": "yes"},
+			Tags:     []string{"stale"},
+			Done:     make(chan struct{}),
+		}
+		stale.Next = &stale
+		stale.Nested.Any = 1
+		stale.Nested.Labels = map[string]string{}
+		dst := []sparseRecord{stale, stale}[:0]
+		src := []sparseRecord{newSparseRecord(1), newSparseRecord(2)}
+
+		cloned, err := CloneSliceInto(dst, src)
+
+		require.NoError(t, err)
+		assert.Same(t, &dst[:1][0], &cloned[0], "dst backing array should be reused")
+		assert.Equal(t, src, cloned)
+		for _, r := range cloned {
+			assert.Nil(t, r.Callback)
+			assert.Nil(t, r.Next)
+			assert.Nil(t, r.Done)
+		}
+	})
+}
+
+// genericTree is a recursive generic type; each instantiation is a distinct
+// reflect.Type.
+type genericTree[T any] struct {
+	Val      T
+	Parent   *genericTree[T]
+	Children []*genericTree[T]
+}
+
+// treeEntity is a genericTree payload held by pointer.
+type treeEntity struct {
+	Name string
+	Tags []string
+}
+
+func newGenericTree[T any](root T, children ...T) *genericTree[T] {
+	tree := &genericTree[T]{Val: root}
+	for _, child := range children {
+		tree.Children = append(tree.Children, &genericTree[T]{Val: child, Parent: tree})
+	}
+	return tree
+}
+
+func TestCloneRecursiveGenericTypes(t *testing.T) {
+	t.Parallel()
+
+	t.Run("value payload", func(t *testing.T) {
+		t.Parallel()
+		original := newGenericTree(1, 2, 3)
+		original.Children[0].Children = []*genericTree[int]{{Val: 4, Parent: original.Children[0]}}
+
+		cloned := MustClone(original)
+
+		require.Len(t, cloned.Children, 2)
+		assert.NotSame(t, original, cloned)
+		assert.Equal(t, []int{2, 3}, []int{cloned.Children[0].Val, cloned.Children[1].Val})
+		for _, child := range cloned.Children {
+			assert.Same(t, cloned, child.Parent, "parent pointers should point at the cloned root")
+		}
+		grandchild := cloned.Children[0].Children[0]
+		assert.Same(t, cloned.Children[0], grandchild.Parent)
+		grandchild.Val = 40
+		assert.Equal(t, 4, original.Children[0].Children[0].Val)
+	})
+
+	t.Run("pointer payload", func(t *testing.T) {
+		t.Parallel()
+		shared := &treeEntity{Name: "shared", Tags: []string{"a"}}
+		original := newGenericTree(&treeEntity{Name: "root"}, shared, shared)
+
+		cloned := MustClone(original)
+
+		require.Len(t, cloned.Children, 2)
+		assert.Same(t, cloned, cloned.Children[1].Parent)
+		assert.NotSame(t, shared, cloned.Children[0].Val)
+		assert.Same(t, cloned.Children[0].Val, cloned.Children[1].Val, "a shared payload should stay shared")
+		cloned.Children[0].Val.Tags[0] = "changed"
+		assert.Equal(t, "a", shared.Tags[0])
+	})
+
+	t.Run("root reachable from its own payload", func(t *testing.T) {
+		t.Parallel()
+		original := newGenericTree[any](nil, "leaf")
+		original.Val = original.Children[0]
+
+		cloned := MustClone(original)
+
+		assert.Same(t, cloned.Children[0], cloned.Val, "a cycle through an interface payload should be preserved")
+		assert.Same(t, cloned, cloned.Val.(*genericTree[any]).Parent)
+	})
+}
+
+func TestCloneAnonymousStructSlices(t *testing.T) {
+	t.Parallel()
+
+	t.Run("elements are independent", func(t *testing.T) {
+		t.Parallel()
+		original := []struct {
+			ID   int
+			Tags []string
+			Meta map[string]int
+		}{
+			{ID: 1, Tags: []string{"a"}, Meta: map[string]int{"x": 1}},
+			{ID: 2},
+		}
+
+		for _, opts := range [][]Option{nil, {WithForceReflection()}} {
+			cloned, err := CloneWith(original, opts...)
+
+			require.NoError(t, err)
+			assert.Equal(t, original, cloned)
+			assert.Nil(t, cloned[1].Tags)
+			cloned[0].Tags[0] = "changed"
+			cloned[0].Meta["x"] = 2
+			assert.Equal(t, "a", original[0].Tags[0])
+			assert.Equal(t, 1, original[0].Meta["x"])
+		}
+	})
+
+	t.Run("pointer to an element field resolves into the cloned slice", func(t *testing.T) {
+		t.Parallel()
+		type counter struct {
+			Hits int
+			Tags []string
+		}
+		type holder struct {
+			Counters []counter
+			Hot      *int
+		}
+		original := &holder{Counters: []counter{{Hits: 1, Tags: []string{"a"}}, {Hits: 2}}}
+		original.Hot = &original.Counters[1].Hits
+
+		cloned := MustClone(original)
+
+		assert.Same(t, &cloned.Counters[1].Hits, cloned.Hot)
+		*cloned.Hot = 20
+		assert.Equal(t, 2, original.Counters[1].Hits)
+	})
+
+	t.Run("arrays of structs in a slice", func(t *testing.T) {
+		t.Parallel()
+		type cell struct{ Notes []string }
+		original := [][2]cell{{{Notes: []string{"a"}}, {}}}
+
+		cloned := MustClone(original)
+
+		assert.Equal(t, original, cloned)
+		cloned[0][0].Notes[0] = "changed"
+		assert.Equal(t, "a", original[0][0].Notes[0])
+	})
+}
@@ -20,6 +20,71 @@ func (e *UnsupportedError) Error() string {
 	return fmt.Sprintf("deepclone: unsupported value at %s (%s): %s", e.Path, e.Type, e.Reason)
 }
 
+// LimitError reports a value that exceeds a limit configured by an Option.
+type LimitError struct {
+	Path   string
+	Type   reflect.Type
+	Limit  string
+	Max    int
+	Actual int
+}
+
+func (e *LimitError) Error() string {
+	if e == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("deepclone: %s %d at %s (%s) exceeds limit %d", e.Limit, e.Actual, e.Path, e.Type, e.Max)
+}
+
+// PanicError reports a panic in a Clone method, a WithCloneFunc function, or a
+// transform, recovered because the clone used WithRecover.
+type PanicError struct {
+	Path  string
+	Type  reflect.Type
+	Value any
+}
+
+func (e *PanicError) Error() string {
+	if e == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("deepclone: panic at %s (%s): %v", e.Path, e.Type, e.Value)
+}
+
+// Unwrap returns the panic value when it is an error, so errors.Is and
+// errors.As see through the PanicError.
+func (e *PanicError) Unwrap() error {
+	if e == nil {
+		return nil
+	}
+	err, _ := e.Value.(error)
+	return err
+}
+
+func panicError(path string, typ reflect.Type, value any) error {
+	if path == "" {
+		path = "$"
+	}
+	return &PanicError{
+		Path:  path,
+		Type:  typ,
+		Value: value,
+	}
+}
+
+func limitError(path string, typ reflect.Type, limit string, limitMax, actual int) error {
+	if path == "" {
+		path = "$"
+	}
+	return &LimitError{
+		Path:   path,
+		Type:   typ,
+		Limit:  limit,
+		Max:    limitMax,
+		Actual: actual,
+	}
+}
+
 func unsupportedError(path string, typ reflect.Type, reason string) error {
 	if path == "" {
 		path = "$"
@@ -0,0 +1,136 @@
+package deepclone
+
+import (
+	"math/bits"
+	"reflect"
+	"sync"
+)
+
+// mapHeaderBytes approximates the fixed cost of a map apart from its slots.
+const mapHeaderBytes = 48
+
+// EstimateCloneBytes returns an estimate of the bytes Clone would allocate
+// for the memory src references, without cloning it. Servers can use it to
+// reject oversized input before committing memory.
+//
+// The estimate walks the graph with the same rules as Clone: shared references
+// and cycles are counted once, slices count their full capacity, strings,
+// Immutable values, registered immutable slices, parsed templates, and handles
+// cost nothing because clones share them, and fields that Clone copies or
+// shares are not walked. Maps are estimated from their length, a *sync.Map
+// counts the clones of its entries but not its internal nodes, and values
+// stored in interfaces count the box Clone allocates for them. Custom Clone
+// methods are estimated as if the value were cloned by reflection, and engine
+// bookkeeping is not included.
+func EstimateCloneBytes[T any](src T) int64 {
+	e := estimator{seen: make(map[backingScanKey]struct{})}
+	e.walk(reflect.ValueOf(src))
+	return e.bytes
+}
+
+type estimator struct {
+	seen  map[backingScanKey]struct{}
+	bytes int64
+}
+
+// walk adds the bytes that cloning v allocates outside v itself.
+func (e *estimator) walk(v reflect.Value) {
+	if !v.IsValid() || isPlainType(v.Type()) {
+		return
+	}
+	if (isImmutableType(v.Type()) || isImmutableSlice(v.Type()) || isHandleType(v.Type())) && !hasCustomCloneType(v.Type()) {
+		return
+	}
+	if isTemplateType(v.Type()) {
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() || !e.visit(visitPointer, v, 0) {
+			return
+		}
+		e.bytes += int64(v.Type().Elem().Size())
+		if v.Type() == syncMapPointerType {
+			v.Interface().(*sync.Map).Range(func(key, value any) bool {
+				e.walk(reflect.ValueOf(&key).Elem())
+				e.walk(reflect.ValueOf(&value).Elem())
+				return true
+			})
+			return
+		}
+		e.walk(v.Elem())
+	case reflect.Interface:
+		if v.IsNil() {
+			return
+		}
+		elem := v.Elem()
+		if !isPointerShaped(elem.Kind()) {
+			e.bytes += int64(elem.Type().Size())
+		}
+		e.walk(elem)
+	case reflect.Struct:
+		info := structInfo(v.Type())
+		if info.atomicPointer && v.CanInterface() {
+			e.walk(loadAtomicPointer(v))
+			return
+		}
+		for _, field := range info.fields {
+			if field.action == cloneField {
+				e.walk(v.Field(field.index))
+			}
+		}
+	case reflect.Array:
+		for i := range v.Len() {
+			e.walk(v.Index(i))
+		}
+	case reflect.Slice:
+		if v.IsNil() || !e.visit(visitSlice, v, v.Len()) {
+			return
+		}
+		e.bytes += int64(v.Cap()) * int64(v.Type().Elem().Size())
+		for i := range v.Len() {
+			e.walk(v.Index(i))
+		}
+	case reflect.Map:
+		if v.IsNil() || !e.visit(visitMap, v, 0) {
+			return
+		}
+		e.bytes += mapBytes(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			e.walk(iter.Key())
+			e.walk(iter.Value())
+		}
+	default:
+	}
+}
+
+// visit reports whether v is reached for the first time.
+func (e *estimator) visit(kind visitKind, v reflect.Value, length int) bool {
+	key := backingScanKey{visitKey: visitKey{kind: kind, addr: v.Pointer(), typ: v.Type()}, len: length}
+	if _, ok := e.seen[key]; ok {
+		return false
+	}
+	e.seen[key] = struct{}{}
+	return true
+}
+
+// isPointerShaped reports whether values of kind are stored in an interface
+// without a separate allocation.
+func isPointerShaped(kind reflect.Kind) bool {
+	return kind == reflect.Pointer || kind == reflect.Map || kind == reflect.Chan ||
+		kind == reflect.Func || kind == reflect.UnsafePointer
+}
+
+// mapBytes estimates a map of type t presized for n entries: slots come in
+// groups of eight with a control word each, and the slot count is a power of
+// two kept at most 7/8 full.
+func mapBytes(t reflect.Type, n int) int64 {
+	if n == 0 {
+		return mapHeaderBytes
+	}
+	slots := max(8, 1<<bits.Len(uint((n*8+6)/7-1)))
+	slotBytes := int64(t.Key().Size() + t.Elem().Size())
+	return mapHeaderBytes + int64(slots/8)*(8+8*slotBytes)
+}
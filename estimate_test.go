@@ -0,0 +1,86 @@
+package deepclone
+
+import (
+	"reflect"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// measureCloneBytes returns the bytes allocated by one Clone of src.
+func measureCloneBytes[T any](t *testing.T, src T) int64 {
+	t.Helper()
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	_, err := Clone(src)
+	runtime.ReadMemStats(&after)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return int64(after.TotalAlloc - before.TotalAlloc)
+}
+
+func TestEstimateCloneBytes(t *testing.T) {
+	// Allocations are measured process-wide, so this test cannot run in parallel.
+	type record struct {
+		ID      int
+		Payload []byte
+	}
+	blobs := make([][]byte, 100)
+	for i := range blobs {
+		blobs[i] = make([]byte, 10<<10)
+	}
+	records := make([]*record, 200)
+	for i := range records {
+		records[i] = &record{ID: i, Payload: make([]byte, 4<<10)}
+	}
+	index := make(map[int][]int64, 1000)
+	for i := range 1000 {
+		index[i] = make([]int64, 128)
+	}
+
+	tests := []struct {
+		name     string
+		estimate int64
+		actual   int64
+	}{
+		{name: "blobs", estimate: EstimateCloneBytes(blobs), actual: measureCloneBytes(t, blobs)},
+		{name: "struct pointers", estimate: EstimateCloneBytes(records), actual: measureCloneBytes(t, records)},
+		{name: "map of slices", estimate: EstimateCloneBytes(index), actual: measureCloneBytes(t, index)},
+	}
+	for _, tt := range tests {
+		t.Logf("%s: estimate %d actual %d", tt.name, tt.estimate, tt.actual)
+		assert.InEpsilon(t, tt.actual, tt.estimate, 0.2, tt.name)
+	}
+}
+
+func TestEstimateCloneBytesFollowsCloneRules(t *testing.T) {
+	t.Parallel()
+	type node struct {
+		Name  string
+		Left  *node
+		Right *node
+		Data  []int64
+	}
+	nodeSize := int64(reflect.TypeFor[node]().Size())
+
+	shared := &node{Data: make([]int64, 4, 8)}
+	diamond := &node{Left: shared, Right: shared}
+	assert.Equal(t, 2*nodeSize+64, EstimateCloneBytes(diamond), "shared references count once")
+
+	cycle := &node{}
+	cycle.Left = cycle
+	assert.Equal(t, nodeSize, EstimateCloneBytes(cycle))
+
+	assert.Zero(t, EstimateCloneBytes("strings are shared"))
+	assert.Zero(t, EstimateCloneBytes(benchSimple{Name: "plain"}))
+	assert.Zero(t, EstimateCloneBytes[*node](nil))
+
+	type tagged struct {
+		Cache []byte `clone:"shallow"`
+		Skip  []byte `clone:"-"`
+	}
+	assert.Zero(t, EstimateCloneBytes(tagged{Cache: make([]byte, 64), Skip: make([]byte, 64)}))
+}
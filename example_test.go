@@ -112,3 +112,38 @@ func ExampleCloner() {
 	// original: [go clone]
 	// cloned:   [rust clone]
 }
+
+// Page clones several fields and checks for failure once.
+type Page struct {
+	Title    string
+	Sections []string
+	Links    map[string]string
+}
+
+func (p Page) Clone() (Page, error) {
+	var f deepclone.Fields
+	cloned := Page{
+		Title:    p.Title,
+		Sections: deepclone.CloneField(&f, p.Sections),
+		Links:    deepclone.CloneField(&f, p.Links),
+	}
+	return cloned, f.Err()
+}
+
+func ExampleCloneField() {
+	original := Page{
+		Title:    "Home",
+		Sections: []string{"intro", "usage"},
+		Links:    map[string]string{"docs": "/docs"},
+	}
+	cloned := deepclone.MustClone(original)
+
+	cloned.Sections[0] = "overview"
+	cloned.Links["docs"] = "/v2/docs"
+
+	fmt.Println("original:", original.Sections, original.Links["docs"])
+	fmt.Println("cloned:  ", cloned.Sections, cloned.Links["docs"])
+	// Output:
+	// original: [intro usage] /docs
+	// cloned:   [overview usage] /v2/docs
+}
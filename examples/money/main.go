@@ -0,0 +1,55 @@
+// Package main demonstrates cloning a third-party money type with WithCloneFunc.
+package main
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/kaptinlin/deepclone"
+)
+
+// decimal stands in for shopspring/decimal.Decimal, which keeps its value in
+// an unexported *big.Int that reflection cannot clone.
+type decimal struct {
+	value *big.Int
+	exp   int32
+}
+
+func newDecimal(value int64, exp int32) decimal {
+	return decimal{value: big.NewInt(value), exp: exp}
+}
+
+func (d decimal) String() string {
+	return fmt.Sprintf("%se%d", d.value, d.exp)
+}
+
+type order struct {
+	ID    string
+	Total decimal
+	Lines []decimal
+}
+
+func main() {
+	fmt.Println("=== Money Type Example ===")
+
+	original := order{
+		ID:    "order-1",
+		Total: newDecimal(1999, -2),
+		Lines: []decimal{newDecimal(999, -2), newDecimal(1000, -2)},
+	}
+
+	_, err := deepclone.Clone(original)
+	fmt.Printf("Without a clone func: %v\n", err)
+
+	cloneDecimal := deepclone.WithCloneFunc(func(d decimal) (decimal, error) {
+		if d.value == nil {
+			return d, nil
+		}
+		return decimal{value: new(big.Int).Set(d.value), exp: d.exp}, nil
+	})
+	cloned := deepclone.MustCloneWith(original, cloneDecimal)
+
+	cloned.Total.value.SetInt64(2999)
+	fmt.Printf("Original: Total=%s\n", original.Total)
+	fmt.Printf("Cloned:   Total=%s\n", cloned.Total)
+}
@@ -0,0 +1,73 @@
+package deepclone
+
+import (
+	"maps"
+	"reflect"
+)
+
+// CloneFlatSlice returns a copy of s made with a single allocation and copy,
+// without inspecting the elements.
+//
+// T must be reference-free: no pointers, slices, maps, interfaces, channels,
+// functions, or custom Clone methods at any depth. Numbers, strings, and
+// structs or arrays built only from them qualify. The element type is analyzed
+// on first use and cached; CloneFlatSlice panics with an UnsupportedError
+// whose reason says why T does not qualify, even for an empty s. A nil s
+// returns nil, and the copy keeps the capacity of s.
+func CloneFlatSlice[T any](s []T) []T {
+	if t := reflect.TypeFor[T](); !isPlainType(t) {
+		panic(unsupportedError("$", reflect.TypeFor[[]T](), "flat slice element type "+notPlainReason(t)))
+	}
+	return cloneSliceExact(s)
+}
+
+// notPlainReason reports why isPlainType rejects t, as a phrase completing
+// "element type".
+func notPlainReason(t reflect.Type) string {
+	if _, ok := unsupportedTypes[t]; ok {
+		return "holds unsupported state"
+	}
+	if hasCustomCloneType(t) {
+		return "has a Clone method"
+	}
+	if hasAfterCloneType(t) {
+		return "is an AfterCloner"
+	}
+	switch t.Kind() {
+	case reflect.Array:
+		return notPlainReason(t.Elem())
+	case reflect.Struct:
+		for _, field := range structInfo(t).walked {
+			switch {
+			case field.transform != "":
+				return "has field " + field.name + " with a transform"
+			case field.action == zeroField:
+				return "has field " + field.name + " tagged to be zeroed"
+			default:
+				return notPlainReason(t.Field(field.index).Type)
+			}
+		}
+	default:
+	}
+	return "holds references"
+}
+
+// CloneSet returns a copy of the set s. Set members are the keys and the empty
+// struct values carry nothing, so when K is reference-free like the element
+// type of CloneFlatSlice, the copy costs one map clone however large the set
+// is.
+//
+// Keys that hold references, such as pointers, are deep-cloned the way Clone
+// clones map keys, so the copy's members point at copies rather than at the
+// originals. CloneSet panics with the error Clone would return for keys that
+// cannot be cloned, such as channels. A nil s returns nil.
+func CloneSet[K comparable](s map[K]struct{}) map[K]struct{} {
+	if isPlainType(reflect.TypeFor[K]()) {
+		return maps.Clone(s)
+	}
+	cloned, err := cloneWith(s, options{})
+	if err != nil {
+		panic(err)
+	}
+	return cloned
+}
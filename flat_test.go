@@ -0,0 +1,150 @@
+package deepclone
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCloneFlatSlice(t *testing.T) {
+	t.Parallel()
+
+	t.Run("numbers", func(t *testing.T) {
+		t.Parallel()
+		original := make([]float64, 3, 5)
+		original[0] = 1.5
+
+		cloned := CloneFlatSlice(original)
+
+		assert.Equal(t, original, cloned)
+		assert.Equal(t, 5, cap(cloned))
+		cloned[0] = 2
+		assert.InDelta(t, 1.5, original[0], 0)
+	})
+
+	t.Run("flat structs", func(t *testing.T) {
+		t.Parallel()
+		type point struct {
+			X, Y  int
+			Label string
+			Pad   [2]uint8
+		}
+		original := []point{{X: 1, Y: 2, Label: "a"}, {X: 3}}
+
+		cloned := CloneFlatSlice(original)
+
+		assert.Equal(t, original, cloned)
+		assert.NotSame(t, &original[0], &cloned[0])
+	})
+
+	t.Run("nil and empty", func(t *testing.T) {
+		t.Parallel()
+		assert.Nil(t, CloneFlatSlice[int](nil))
+		assert.Equal(t, []int{}, CloneFlatSlice([]int{}))
+	})
+
+	t.Run("reference element panics", func(t *testing.T) {
+		t.Parallel()
+		type withTags struct {
+			Tags []string
+		}
+
+		defer func() {
+			unsupported, ok := recover().(*UnsupportedError)
+			require.True(t, ok)
+			assert.Equal(t, "$", unsupported.Path)
+			assert.Equal(t, "flat slice element type holds references", unsupported.Reason)
+		}()
+		CloneFlatSlice([]withTags{})
+	})
+
+	t.Run("panics name the reason", func(t *testing.T) {
+		t.Parallel()
+		type redacted struct {
+			ID   int
+			Name string `clone:"transform=redact"`
+		}
+		type scrubbed struct {
+			ID     int
+			Secret string `clone:"zero"`
+		}
+		reason := func(clone func()) string {
+			var unsupported *UnsupportedError
+			func() {
+				defer func() { unsupported, _ = recover().(*UnsupportedError) }()
+				clone()
+			}()
+			require.NotNil(t, unsupported)
+			return unsupported.Reason
+		}
+
+		assert.Equal(t, "flat slice element type has a Clone method", reason(func() { CloneFlatSlice([]CustomType{}) }))
+		assert.Equal(t, "flat slice element type is an AfterCloner", reason(func() { CloneFlatSlice([][2]checksum{}) }))
+		assert.Equal(t, "flat slice element type has field Name with a transform", reason(func() { CloneFlatSlice([]redacted{}) }))
+		assert.Equal(t, "flat slice element type has field Secret tagged to be zeroed", reason(func() { CloneFlatSlice([]scrubbed{}) }))
+		assert.Equal(t, "flat slice element type holds unsupported state", reason(func() { CloneFlatSlice([]sync.Mutex{}) }))
+	})
+}
+
+func TestCloneSet(t *testing.T) {
+	t.Parallel()
+
+	t.Run("independent copy", func(t *testing.T) {
+		t.Parallel()
+		type member struct {
+			Org  string
+			User int
+		}
+		original := map[member]struct{}{{Org: "a", User: 1}: {}, {Org: "b", User: 2}: {}}
+
+		cloned := CloneSet(original)
+
+		assert.Equal(t, original, cloned)
+		cloned[member{Org: "c"}] = struct{}{}
+		assert.Len(t, original, 2)
+	})
+
+	t.Run("nil and empty", func(t *testing.T) {
+		t.Parallel()
+		assert.Nil(t, CloneSet[string](nil))
+		assert.Equal(t, map[string]struct{}{}, CloneSet(map[string]struct{}{}))
+	})
+
+	t.Run("reference keys are cloned", func(t *testing.T) {
+		t.Parallel()
+		one := 1
+		original := map[*int]struct{}{&one: {}}
+
+		cloned := CloneSet(original)
+
+		require.Len(t, cloned, 1)
+		for key := range cloned {
+			assert.NotSame(t, &one, key)
+			assert.Equal(t, 1, *key)
+		}
+		assert.Nil(t, CloneSet[*int](nil))
+	})
+
+	t.Run("unsupported key panics", func(t *testing.T) {
+		t.Parallel()
+		defer func() {
+			unsupported, ok := recover().(*UnsupportedError)
+			require.True(t, ok)
+			assert.Equal(t, "channels cannot be cloned", unsupported.Reason)
+		}()
+		CloneSet(map[chan int]struct{}{make(chan int): {}})
+	})
+}
+
+func TestCloneSetDoesNotAllocatePerMember(t *testing.T) {
+	original := make(map[int]struct{}, 10_000)
+	for i := range 10_000 {
+		original[i] = struct{}{}
+	}
+
+	allocs := testing.AllocsPerRun(10, func() { _ = CloneSet(original) })
+
+	assert.Less(t, allocs, float64(len(original))/100)
+}
@@ -0,0 +1,72 @@
+package deepclone
+
+import (
+	"maps"
+	"reflect"
+)
+
+// CloneSliceInto deep-clones src into dst's backing array and returns the
+// result, allocating a new array only when cap(dst) < len(src).
+//
+// The result has the length of src but not necessarily its capacity, and a nil
+// src yields an empty slice that keeps dst's array. A new array has the
+// capacity of src. Elements of src that refer to src itself refer to the
+// result, limited to the capacity of src, unless dst is reused with less
+// capacity than src, in which case they get their own copy. dst must not
+// alias src. On error the returned slice is empty and the elements written so
+// far are cleared.
+func CloneSliceInto[T any](dst, src []T) ([]T, error) {
+	if cap(dst) < len(src) {
+		dst = make([]T, 0, cap(src))
+	}
+	dst = append(dst[:0], src...)
+	if len(src) == 0 || isPlainType(reflect.TypeFor[T]()) {
+		return dst, nil
+	}
+
+	srcValue := reflect.ValueOf(src)
+	dstValue := reflect.ValueOf(dst)
+	ctx := newCloneContext(options{})
+	if sliceCanContainCycles(srcValue.Type().Elem().Kind()) {
+		// cloneSlice reuses a visited slice only with the capacity of src.
+		ctx.visited[visitKey{kind: visitSlice, addr: srcValue.Pointer(), typ: srcValue.Type()}] = dstValue.Slice3(0, len(src), min(cap(dst), cap(src)))
+	}
+
+	for i := range srcValue.Len() {
+		if err := ctx.cloneElementInto(srcValue.Index(i), dstValue.Index(i), indexPath("$", i)); err != nil {
+			clear(dst)
+			return dst[:0], err
+		}
+	}
+	return dst, nil
+}
+
+// CloneMapInto clears dst, deep-clones the entries of src into it, and returns
+// it. A nil dst is allocated when src has entries.
+//
+// Reusing dst keeps its buckets, so repeated calls with similarly sized maps
+// avoid growing a new map each time. dst must not alias src. On error the
+// returned map is cleared.
+func CloneMapInto[K comparable, V any](dst, src map[K]V) (map[K]V, error) {
+	clear(dst)
+	if len(src) == 0 {
+		return dst, nil
+	}
+	if dst == nil {
+		dst = make(map[K]V, len(src))
+	}
+	if isPlainType(reflect.TypeFor[K]()) && isPlainType(reflect.TypeFor[V]()) {
+		maps.Copy(dst, src)
+		return dst, nil
+	}
+
+	srcValue := reflect.ValueOf(src)
+	dstValue := reflect.ValueOf(dst)
+	ctx := newCloneContext(options{})
+	ctx.visited[visitKey{kind: visitMap, addr: srcValue.Pointer(), typ: srcValue.Type()}] = dstValue
+	if err := ctx.cloneMapInto(srcValue, dstValue, "$"); err != nil {
+		clear(dst)
+		return dst, err
+	}
+	return dst, nil
+}
@@ -0,0 +1,211 @@
+package deepclone
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCloneSliceInto(t *testing.T) {
+	t.Parallel()
+
+	t.Run("reuses dst", func(t *testing.T) {
+		t.Parallel()
+		dst := make([]int, 0, 8)
+		src := []int{1, 2, 3}
+
+		cloned, err := CloneSliceInto(dst, src)
+
+		require.NoError(t, err)
+		assert.Equal(t, src, cloned)
+		assert.Same(t, &dst[:1][0], &cloned[0], "dst backing array should be reused")
+		src[0] = 99
+		assert.Equal(t, 1, cloned[0])
+	})
+
+	t.Run("grows small dst", func(t *testing.T) {
+		t.Parallel()
+		cloned, err := CloneSliceInto(make([]string, 0, 1), []string{"a", "b"})
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a", "b"}, cloned)
+	})
+
+	t.Run("nil and empty src", func(t *testing.T) {
+		t.Parallel()
+		dst := []int{1, 2}
+
+		cloned, err := CloneSliceInto(dst, nil)
+		require.NoError(t, err)
+		assert.Empty(t, cloned)
+		assert.Equal(t, cap(dst), cap(cloned))
+
+		cloned, err = CloneSliceInto[int](nil, nil)
+		require.NoError(t, err)
+		assert.Nil(t, cloned)
+	})
+
+	t.Run("deep clones elements", func(t *testing.T) {
+		t.Parallel()
+		type item struct {
+			Name string
+			Tags []string
+			Ref  *int
+		}
+		shared := 7
+		src := []item{
+			{Name: "a", Tags: []string{"x"}, Ref: &shared},
+			{Name: "b", Ref: &shared},
+		}
+
+		cloned, err := CloneSliceInto(make([]item, 0, 4), src)
+
+		require.NoError(t, err)
+		assert.Equal(t, src, cloned)
+		assert.NotSame(t, &src[0].Tags[0], &cloned[0].Tags[0])
+		assert.False(t, cloned[0].Ref == &shared)
+		assert.True(t, cloned[0].Ref == cloned[1].Ref, "shared pointers across elements should clone once")
+	})
+
+	t.Run("self-referencing slice", func(t *testing.T) {
+		t.Parallel()
+		src := make([]any, 2)
+		src[0] = "value"
+		src[1] = src
+
+		cloned, err := CloneSliceInto(make([]any, 0, 2), src)
+
+		require.NoError(t, err)
+		inner, ok := cloned[1].([]any)
+		require.True(t, ok)
+		assert.Same(t, &cloned[0], &inner[0])
+	})
+
+	t.Run("self-referencing slice with a different capacity", func(t *testing.T) {
+		t.Parallel()
+		src := make([]any, 2, 4)
+		src[0] = "value"
+		src[1] = src
+
+		for _, dst := range [][]any{make([]any, 0, 8), make([]any, 0, 1)} {
+			cloned, err := CloneSliceInto(dst, src)
+
+			require.NoError(t, err)
+			inner, ok := cloned[1].([]any)
+			require.True(t, ok)
+			assert.Same(t, &cloned[0], &inner[0], "self reference should point at dst")
+			assert.Len(t, inner, 2)
+			assert.Equal(t, 4, cap(inner))
+		}
+	})
+
+	t.Run("unsupported element", func(t *testing.T) {
+		t.Parallel()
+		type worker struct {
+			Tags []string
+			Ch   chan int
+		}
+		src := []worker{{Tags: []string{"ok"}}, {Ch: make(chan int)}}
+
+		cloned, err := CloneSliceInto(make([]worker, 0, 2), src)
+
+		var unsupported *UnsupportedError
+		require.ErrorAs(t, err, &unsupported)
+		assert.Equal(t, "$[1].Ch", unsupported.Path)
+		assert.Empty(t, cloned)
+		assert.Nil(t, cloned[:1][0].Tags, "partially written elements should be cleared")
+	})
+}
+
+func TestCloneMapInto(t *testing.T) {
+	t.Parallel()
+
+	t.Run("clears and repopulates dst", func(t *testing.T) {
+		t.Parallel()
+		dst := map[string]int{"stale": 1}
+		src := map[string]int{"a": 1, "b": 2}
+
+		cloned, err := CloneMapInto(dst, src)
+
+		require.NoError(t, err)
+		assert.Equal(t, src, cloned)
+		cloned["c"] = 3
+		assert.Equal(t, 3, dst["c"], "dst should be reused")
+		assert.NotContains(t, src, "c")
+	})
+
+	t.Run("nil dst and src", func(t *testing.T) {
+		t.Parallel()
+		cloned, err := CloneMapInto(nil, map[string]int{"a": 1})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]int{"a": 1}, cloned)
+
+		cloned, err = CloneMapInto[string, int](nil, nil)
+		require.NoError(t, err)
+		assert.Nil(t, cloned)
+
+		dst := map[string]int{"stale": 1}
+		cloned, err = CloneMapInto(dst, nil)
+		require.NoError(t, err)
+		assert.Empty(t, cloned)
+	})
+
+	t.Run("deep clones values", func(t *testing.T) {
+		t.Parallel()
+		shared := []string{"x"}
+		src := map[string][]string{"a": shared, "b": {"y"}}
+
+		cloned, err := CloneMapInto(make(map[string][]string), src)
+
+		require.NoError(t, err)
+		assert.Equal(t, src, cloned)
+		cloned["a"][0] = "changed"
+		assert.Equal(t, "x", shared[0])
+	})
+
+	t.Run("self-referencing map", func(t *testing.T) {
+		t.Parallel()
+		src := map[string]any{"key": "value"}
+		src["self"] = src
+		dst := make(map[string]any)
+
+		cloned, err := CloneMapInto(dst, src)
+
+		require.NoError(t, err)
+		inner, ok := cloned["self"].(map[string]any)
+		require.True(t, ok)
+		inner["new"] = true
+		assert.Equal(t, true, dst["new"], "self reference should point at dst")
+		assert.NotContains(t, src, "new")
+	})
+
+	t.Run("unsupported value", func(t *testing.T) {
+		t.Parallel()
+		dst := map[string]any{"stale": 1}
+
+		cloned, err := CloneMapInto(dst, map[string]any{"ch": make(chan int)})
+
+		var unsupported *UnsupportedError
+		require.ErrorAs(t, err, &unsupported)
+		assert.Equal(t, `$["ch"]`, unsupported.Path)
+		assert.Empty(t, cloned)
+	})
+}
+
+func TestCloneIntoZeroSteadyStateAlloc(t *testing.T) {
+	srcSlice := []benchSimple{{ID: 1, Name: "a"}, {ID: 2, Name: "b"}}
+	dstSlice := make([]benchSimple, 0, len(srcSlice))
+	srcMap := map[int]benchSimple{1: {ID: 1}, 2: {ID: 2}}
+	dstMap := make(map[int]benchSimple, len(srcMap))
+
+	sliceAllocs := testing.AllocsPerRun(100, func() {
+		dstSlice, _ = CloneSliceInto(dstSlice, srcSlice)
+	})
+	mapAllocs := testing.AllocsPerRun(100, func() {
+		dstMap, _ = CloneMapInto(dstMap, srcMap)
+	})
+
+	assert.Zero(t, sliceAllocs)
+	assert.Zero(t, mapAllocs)
+}
@@ -0,0 +1,145 @@
+package deepclone
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+)
+
+// layouts holds the copiers registered by RegisterLayout, or nil when there
+// are none. The map is replaced, never modified, so cloning reads it without
+// locking.
+var (
+	layoutsMutex sync.Mutex
+	layouts      atomic.Pointer[map[reflect.Type]func(dst, src unsafe.Pointer)]
+)
+
+// RegisterLayout makes clones copy every value of type t with copier instead
+// of walking its fields with reflection. It is an escape hatch for hot types
+// on nanosecond-sensitive paths, where a hand-written or generated routine
+// that knows the field offsets of t is faster than any reflection.
+//
+// copier receives dst, a pointer to a zero value of t, and src, a pointer to
+// the value being cloned. The package cannot check the contract; a copier that
+// breaks it corrupts memory or the source:
+//
+//   - Both pointers point to a value of exactly type t; convert them to *t and
+//     nothing else, and do not write beyond the value.
+//   - Write the whole clone to *dst, allocating new memory for every pointer,
+//     slice, and map that the clone must not share with the source.
+//   - Only read through src. Other goroutines may be reading the source.
+//   - Do not keep either pointer after copier returns.
+//
+// The copier replaces the whole clone of a value, so cycles and references
+// shared within it are not tracked, as with Clone methods. A Clone method or
+// WithCloneFunc function for t takes precedence. Values of a type that holds
+// no references are already copied by assignment inside structs, slices,
+// arrays, and maps, where copier is not called. Under WithRecover a panic in
+// copier is returned as a PanicError.
+//
+// Registration is global; register types during program initialization. A
+// nil copier removes the registration for t. RegisterLayout panics if t is
+// nil or an interface type.
+func RegisterLayout(t reflect.Type, copier func(dst, src unsafe.Pointer)) {
+	if t == nil || t.Kind() == reflect.Interface {
+		panic("deepclone: RegisterLayout requires a concrete type, got " + fmt.Sprint(t))
+	}
+	layoutsMutex.Lock()
+	defer layoutsMutex.Unlock()
+	registered := make(map[reflect.Type]func(dst, src unsafe.Pointer))
+	if current := layouts.Load(); current != nil {
+		maps.Copy(registered, *current)
+	}
+	if copier == nil {
+		delete(registered, t)
+	} else {
+		registered[t] = copier
+	}
+	if len(registered) == 0 {
+		layouts.Store(nil)
+		return
+	}
+	layouts.Store(&registered)
+}
+
+// layoutCopier returns the copier registered for t by RegisterLayout.
+func layoutCopier(t reflect.Type) (func(dst, src unsafe.Pointer), bool) {
+	registered := layouts.Load()
+	if registered == nil {
+		return nil, false
+	}
+	copier, ok := (*registered)[t]
+	return copier, ok
+}
+
+// hasLayout reports whether RegisterLayout registered a copier for t.
+func hasLayout(t reflect.Type) bool {
+	_, ok := layoutCopier(t)
+	return ok
+}
+
+// layoutFor returns the copier that clones values of t, unless a Clone method
+// or WithCloneFunc function for t takes precedence.
+func (c *cloneContext) layoutFor(t reflect.Type) (func(dst, src unsafe.Pointer), bool) {
+	copier, ok := layoutCopier(t)
+	if !ok {
+		return nil, false
+	}
+	if _, fn := c.opts.cloneFuncs[t]; fn || hasCustomCloneType(t) && !c.forcesStructural(t) {
+		return nil, false
+	}
+	return copier, true
+}
+
+// cloneLayout clones src with the copier registered for T, or for the type T
+// points to, without entering the engine. A Clone method on T or its pointee
+// takes precedence. The copier owns the whole clone, so no visited tracking
+// is needed.
+func cloneLayout[T any](src T) (T, bool) {
+	var cloned T
+	t := reflect.TypeFor[T]()
+	if t.Kind() == reflect.Pointer {
+		copier, ok := layoutCopier(t.Elem())
+		if !ok || hasCustomCloneType(t) || hasCustomCloneType(t.Elem()) {
+			return cloned, false
+		}
+		v := reflect.ValueOf(src)
+		if v.IsNil() {
+			return src, true
+		}
+		clonedPtr := reflect.New(t.Elem())
+		copier(clonedPtr.UnsafePointer(), v.UnsafePointer())
+		return clonedPtr.Interface().(T), true
+	}
+
+	copier, ok := layoutCopier(t)
+	if !ok || hasCustomCloneType(t) {
+		return cloned, false
+	}
+	copier(unsafe.Pointer(&cloned), unsafe.Pointer(&src))
+	return cloned, true
+}
+
+// copyLayoutInto clones src, which must be interfaceable, into dst, an
+// addressable value of the same type, with copier. dst is zeroed first, and a
+// src that is not addressable is copied so copier can read it through a
+// pointer.
+func (c *cloneContext) copyLayoutInto(dst, src reflect.Value, copier func(dst, src unsafe.Pointer), path string) error {
+	if !src.CanAddr() {
+		addressable := reflect.New(src.Type()).Elem()
+		addressable.Set(src)
+		src = addressable
+	}
+	dst.SetZero()
+	if !c.opts.recoverPanics {
+		copier(dst.Addr().UnsafePointer(), src.Addr().UnsafePointer())
+		return nil
+	}
+	return c.guard(path, src.Type(), func() error {
+		copier(dst.Addr().UnsafePointer(), src.Addr().UnsafePointer())
+		return nil
+	})
+}
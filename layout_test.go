@@ -0,0 +1,143 @@
+package deepclone
+
+import (
+	"reflect"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// layoutPoint is cloned by a registered layout copier in TestRegisterLayout.
+type layoutPoint struct {
+	X, Y int
+	Tags []string
+}
+
+// layoutPointCopies counts the calls to copyLayoutPoint.
+var layoutPointCopies atomic.Int64
+
+func copyLayoutPoint(dst, src unsafe.Pointer) {
+	layoutPointCopies.Add(1)
+	s, d := (*layoutPoint)(src), (*layoutPoint)(dst)
+	*d = *s
+	d.Tags = slices.Clone(s.Tags)
+}
+
+// TestRegisterLayout is not parallel because it registers a global copier and
+// counts its calls.
+func TestRegisterLayout(t *testing.T) {
+	type scene struct {
+		Origin  layoutPoint
+		Corners [2]layoutPoint
+		Path    []layoutPoint
+		Focus   *layoutPoint
+		Named   map[string]layoutPoint
+		Marker  any
+	}
+	newScene := func() *scene {
+		return &scene{
+			Origin:  layoutPoint{X: 1, Tags: []string{"origin"}},
+			Corners: [2]layoutPoint{{X: 2}, {Y: 3, Tags: []string{"corner"}}},
+			Path:    []layoutPoint{{X: 4, Tags: []string{"a", "b"}}, {Y: 5}},
+			Focus:   &layoutPoint{X: 6, Tags: []string{"focus"}},
+			Named:   map[string]layoutPoint{"home": {Y: 7, Tags: []string{"home"}}},
+			Marker:  layoutPoint{X: 8, Tags: []string{"marker"}},
+		}
+	}
+	original := newScene()
+	reflected := MustClone(original)
+	point := layoutPoint{X: 1, Tags: []string{"a"}}
+	reflectedAllocs := testing.AllocsPerRun(100, func() { _, _ = Clone(point) })
+
+	RegisterLayout(reflect.TypeFor[layoutPoint](), copyLayoutPoint)
+	t.Cleanup(func() { RegisterLayout(reflect.TypeFor[layoutPoint](), nil) })
+
+	t.Run("matches the reflection path", func(t *testing.T) {
+		layoutPointCopies.Store(0)
+
+		cloned := MustClone(original)
+
+		assert.Equal(t, reflected, cloned)
+		assert.Equal(t, int64(8), layoutPointCopies.Load(), "every point should go through the copier")
+		cloned.Origin.Tags[0] = "changed"
+		cloned.Path[0].Tags[0] = "changed"
+		cloned.Focus.Tags[0] = "changed"
+		cloned.Named["home"].Tags[0] = "changed"
+		cloned.Marker.(layoutPoint).Tags[0] = "changed"
+		assert.Equal(t, newScene(), original)
+	})
+
+	t.Run("top-level value", func(t *testing.T) {
+		layoutPointCopies.Store(0)
+
+		cloned := MustClone(point)
+
+		assert.Equal(t, point, cloned)
+		assert.Equal(t, int64(1), layoutPointCopies.Load())
+		cloned.Tags[0] = "b"
+		assert.Equal(t, "a", point.Tags[0])
+		assert.Less(t, testing.AllocsPerRun(100, func() { _, _ = Clone(point) }), reflectedAllocs,
+			"the copier should skip the reflection engine")
+	})
+
+	t.Run("top-level pointer", func(t *testing.T) {
+		layoutPointCopies.Store(0)
+		var nilPoint *layoutPoint
+
+		cloned := MustClone(&point)
+
+		assert.Equal(t, &point, cloned)
+		assert.NotSame(t, &point, cloned)
+		assert.Equal(t, int64(1), layoutPointCopies.Load())
+		assert.Nil(t, MustClone(nilPoint))
+	})
+
+	t.Run("clone func takes precedence", func(t *testing.T) {
+		layoutPointCopies.Store(0)
+
+		cloned := MustCloneWith([]layoutPoint{{X: 1}}, WithCloneFunc(func(p layoutPoint) (layoutPoint, error) {
+			p.X = -p.X
+			return p, nil
+		}))
+
+		assert.Equal(t, -1, cloned[0].X)
+		assert.Zero(t, layoutPointCopies.Load())
+	})
+
+	t.Run("copier panics are recovered", func(t *testing.T) {
+		type fragile struct {
+			Data []int
+		}
+		RegisterLayout(reflect.TypeFor[fragile](), func(dst, src unsafe.Pointer) {
+			panic("bad layout")
+		})
+		t.Cleanup(func() { RegisterLayout(reflect.TypeFor[fragile](), nil) })
+
+		_, err := CloneWith(map[string]fragile{"f": {Data: []int{1}}}, WithRecover())
+
+		var panicked *PanicError
+		require.ErrorAs(t, err, &panicked)
+		assert.Equal(t, `$["f"]`, panicked.Path)
+		assert.Equal(t, "bad layout", panicked.Value)
+	})
+
+	t.Run("unregistered types use reflection", func(t *testing.T) {
+		RegisterLayout(reflect.TypeFor[layoutPoint](), nil)
+		RegisterLayout(reflect.TypeFor[layoutPoint](), copyLayoutPoint)
+		RegisterLayout(reflect.TypeFor[benchSimple](), nil)
+		layoutPointCopies.Store(0)
+
+		_ = MustClone(&benchSimple{ID: 1})
+
+		assert.Zero(t, layoutPointCopies.Load())
+	})
+
+	t.Run("rejects interface and nil types", func(t *testing.T) {
+		assert.Panics(t, func() { RegisterLayout(nil, copyLayoutPoint) })
+		assert.Panics(t, func() { RegisterLayout(reflect.TypeFor[any](), copyLayoutPoint) })
+	})
+}
@@ -0,0 +1,33 @@
+package deepclone
+
+import "container/list"
+
+// CloneList returns a copy of l with every element value deep-cloned, in the
+// same order, along with a map from each element of l to its copy.
+//
+// Reflection cannot clone a list.List, whose elements link to each other and
+// to the list through unexported pointers. Types that keep a list next to an
+// index of its elements, such as LRU caches and ordered maps, call CloneList
+// from their Clone method and use the element map to point the cloned index at
+// the cloned list. The values share one clone graph, so references shared
+// between them stay shared. A nil l returns a nil list and map. On error the
+// partial copy is discarded.
+func CloneList(l *list.List) (*list.List, map[*list.Element]*list.Element, error) {
+	if l == nil {
+		return nil, nil, nil
+	}
+
+	cloned := list.New()
+	elements := make(map[*list.Element]*list.Element, l.Len())
+	ctx := newCloneContext(newOptions(nil))
+	i := 0
+	for e := l.Front(); e != nil; e = e.Next() {
+		value, err := ctx.cloneAny(e.Value, indexPath("$", i))
+		if err != nil {
+			return nil, nil, err
+		}
+		elements[e] = cloned.PushBack(value)
+		i++
+	}
+	return cloned, elements, nil
+}
@@ -0,0 +1,159 @@
+package deepclone
+
+import (
+	"container/list"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// lruEntry is the value stored in each lruCache list element.
+type lruEntry struct {
+	Key   string
+	Value []int
+}
+
+// lruCache keeps entries in recency order with an index into the list, the
+// composite CloneList exists for.
+type lruCache struct {
+	capacity int
+	order    *list.List
+	index    map[string]*list.Element
+}
+
+func newLRUCache(capacity int) *lruCache {
+	return &lruCache{capacity: capacity, order: list.New(), index: make(map[string]*list.Element)}
+}
+
+func (c *lruCache) Put(key string, value []int) {
+	if elem, ok := c.index[key]; ok {
+		elem.Value.(*lruEntry).Value = value
+		c.order.MoveToFront(elem)
+		return
+	}
+	c.index[key] = c.order.PushFront(&lruEntry{Key: key, Value: value})
+	if c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.index, oldest.Value.(*lruEntry).Key)
+	}
+}
+
+func (c *lruCache) Get(key string) ([]int, bool) {
+	elem, ok := c.index[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(elem)
+	return elem.Value.(*lruEntry).Value, true
+}
+
+func (c *lruCache) Keys() []string {
+	keys := make([]string, 0, c.order.Len())
+	for e := c.order.Front(); e != nil; e = e.Next() {
+		keys = append(keys, e.Value.(*lruEntry).Key)
+	}
+	return keys
+}
+
+func (c *lruCache) Clone() (*lruCache, error) {
+	order, elements, err := CloneList(c.order)
+	if err != nil {
+		return nil, err
+	}
+	index := make(map[string]*list.Element, len(c.index))
+	for key, elem := range c.index {
+		index[key] = elements[elem]
+	}
+	return &lruCache{capacity: c.capacity, order: order, index: index}, nil
+}
+
+func TestCloneList(t *testing.T) {
+	t.Parallel()
+
+	t.Run("values are cloned in order", func(t *testing.T) {
+		t.Parallel()
+		shared := &lruEntry{Key: "a", Value: []int{1, 2}}
+		original := list.New()
+		first := original.PushBack(shared)
+		second := original.PushBack(shared)
+		original.PushBack(nil)
+
+		cloned, elements, err := CloneList(original)
+
+		require.NoError(t, err)
+		require.Equal(t, 3, cloned.Len())
+		require.Len(t, elements, 3)
+		assert.Same(t, cloned.Front(), elements[first])
+		assert.Same(t, cloned.Front().Next(), elements[second])
+		assert.Nil(t, cloned.Back().Value)
+
+		entry := cloned.Front().Value.(*lruEntry)
+		assert.NotSame(t, shared, entry)
+		assert.Same(t, entry, elements[second].Value, "references shared between values should stay shared")
+		entry.Value[0] = 100
+		assert.Equal(t, []int{1, 2}, shared.Value)
+	})
+
+	t.Run("nil list", func(t *testing.T) {
+		t.Parallel()
+		cloned, elements, err := CloneList(nil)
+
+		require.NoError(t, err)
+		assert.Nil(t, cloned)
+		assert.Nil(t, elements)
+	})
+
+	t.Run("unsupported value", func(t *testing.T) {
+		t.Parallel()
+		original := list.New()
+		original.PushBack(1)
+		original.PushBack(struct{ ch *int }{ch: new(int)})
+
+		cloned, elements, err := CloneList(original)
+
+		var unsupported *UnsupportedError
+		require.True(t, errors.As(err, &unsupported))
+		assert.Equal(t, "$[1].ch", unsupported.Path)
+		assert.Nil(t, cloned)
+		assert.Nil(t, elements)
+	})
+
+	t.Run("LRU cache index points into the cloned list", func(t *testing.T) {
+		t.Parallel()
+		type server struct {
+			Name  string
+			Cache *lruCache
+		}
+		cache := newLRUCache(3)
+		cache.Put("a", []int{1})
+		cache.Put("b", []int{2})
+		cache.Put("c", []int{3})
+		original := &server{Name: "api", Cache: cache}
+
+		cloned, err := Clone(original)
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"c", "b", "a"}, cloned.Cache.Keys())
+		for key, elem := range cloned.Cache.index {
+			assert.Equal(t, key, elem.Value.(*lruEntry).Key)
+			assert.NotSame(t, cache.index[key], elem)
+			found := false
+			for e := cloned.Cache.order.Front(); e != nil; e = e.Next() {
+				found = found || e == elem
+			}
+			assert.True(t, found, "index entry %q should belong to the cloned list", key)
+		}
+
+		value, ok := cloned.Cache.Get("a")
+		require.True(t, ok)
+		value[0] = 10
+		cloned.Cache.Put("d", []int{4})
+		assert.Equal(t, []string{"d", "a", "c"}, cloned.Cache.Keys())
+		assert.Equal(t, []string{"c", "b", "a"}, cache.Keys())
+		originalValue, _ := cache.Get("a")
+		assert.Equal(t, []int{1}, originalValue)
+	})
+}
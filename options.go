@@ -0,0 +1,556 @@
+package deepclone
+
+import (
+	"math/bits"
+	"reflect"
+	"slices"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// stackBytesPerLevel is a conservative estimate of the goroutine stack used
+	// by one level of nested values; measured use is well under 1 KiB.
+	stackBytesPerLevel = 2 << 10
+	// stackMarginDivisor reserves part of the maximum stack for the frames
+	// of the caller and of custom Clone methods.
+	stackMarginDivisor = 2
+	// defaultMaxStack is the maximum goroutine stack size the runtime starts
+	// with: 1 GB on 64-bit platforms and 250 MB on 32-bit ones.
+	defaultMaxStack = 250_000_000 + 750_000_000*(bits.UintSize/64)
+)
+
+// Option configures a single CloneWith call.
+type Option func(*options)
+
+type options struct {
+	expandShared       bool
+	maxCollectionLen   int
+	cycleHook          func(reflect.Type, uintptr)
+	allocator          func(reflect.Type) reflect.Value
+	afterClone         bool
+	maxDepth           int
+	shareTypes         map[reflect.Type]struct{}
+	sharePredicate     func(reflect.Type) bool
+	beforeClone        func(reflect.Type)
+	cloneDone          func(reflect.Type, time.Duration, Stats)
+	forceReflection    bool
+	jsonFallback       bool
+	cloneFuncs         map[reflect.Type]func(reflect.Value) (reflect.Value, error)
+	rejectOpaque       bool
+	preserveBacking    bool
+	structuralTypes    map[reflect.Type]struct{}
+	unsupportedHook    func(string, reflect.Kind)
+	shareIO            bool
+	deterministicOrder bool
+	emptyCollections   emptyCollections
+	recoverPanics      bool
+	handlePolicy       HandlePolicy
+	bufferPolicy       BufferPolicy
+	// shallowType and deepFields select the struct type whose exported fields
+	// are shared unless named in deepFields.
+	shallowType reflect.Type
+	deepFields  map[string]struct{}
+
+	// shared, when set, reports whether the clone shares references with the
+	// source.
+	shared *bool
+	// stats, when set, collects the work done for WithCloneDone.
+	stats *Stats
+	// mapping, when set, collects the source pointers reached by the clone and
+	// their clones for CloneWithMapping.
+	mapping map[any]any
+}
+
+// defaults holds the options set by SetDefaultOptions, or nil when none are.
+var defaults atomic.Pointer[defaultOptions]
+
+type defaultOptions struct {
+	opts []Option
+	// resolved is opts applied to zero options. Calls without options of their
+	// own use it as is, since cloning never writes to the maps it holds.
+	resolved options
+}
+
+// SetDefaultOptions sets options that Clone, CloneWith, and the other clone
+// functions apply before their own options, so process-wide policy does not
+// have to be passed to every call. Options given to a call are applied after
+// the defaults and override them. Calling SetDefaultOptions again replaces the
+// defaults, and calling it with no options clears them.
+//
+// SetDefaultOptions is safe to call concurrently with cloning; calls that have
+// already started keep the defaults they began with. CloneSliceInto,
+// CloneMapInto, CloneFlatSlice, CloneSet, EstimateCloneBytes, and
+// SharesByDefault ignore the defaults; every other function that clones,
+// including CloneList, CloneMapSeq, and CloneField, applies them.
+func SetDefaultOptions(opts ...Option) {
+	if len(opts) == 0 {
+		defaults.Store(nil)
+		return
+	}
+	opts = slices.Clone(opts)
+	defaults.Store(&defaultOptions{opts: opts, resolved: applyOptions(options{}, opts)})
+}
+
+// newOptions applies the default options and then opts, and adds the
+// functions registered by RegisterCloner.
+func newOptions(opts []Option) options {
+	return withRegisteredCloners(resolveOptions(opts))
+}
+
+func resolveOptions(opts []Option) options {
+	d := defaults.Load()
+	if len(opts) == 0 {
+		// Return without applying anything, which keeps Clone allocation-free.
+		if d == nil {
+			return options{}
+		}
+		return d.resolved
+	}
+	if d == nil {
+		return applyOptions(options{}, opts)
+	}
+	// Reapply the defaults so opts cannot write into maps the defaults share.
+	return applyOptions(applyOptions(options{}, d.opts), opts)
+}
+
+func applyOptions(o options, opts []Option) options {
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&o)
+		}
+	}
+	return o
+}
+
+// WithExpandSharedPointers clones every reference to a shared pointer, map, or
+// slice into its own independent copy instead of preserving the sharing.
+//
+// Cycles are still preserved: a reference back to a value that is currently
+// being cloned resolves to that value's clone so cloning terminates. Pointers
+// to struct fields and array elements are expanded as well.
+func WithExpandSharedPointers() Option {
+	return func(o *options) {
+		o.expandShared = true
+	}
+}
+
+// WithMaxCollectionLen rejects any slice or map longer than n with a
+// LimitError before allocating its clone. It guards against implausible
+// allocations when lengths come from untrusted input. The limit applies to
+// each collection on its own, not to their total, and the error names the
+// path of the first oversized collection reached, so it is also the per-node
+// width cap and no separate width option exists. A non-positive n disables
+// the limit.
+func WithMaxCollectionLen(n int) Option {
+	return func(o *options) {
+		o.maxCollectionLen = max(n, 0)
+	}
+}
+
+// WithCycleHook calls hook each time cloning reaches a pointer, slice, or map
+// that is still being cloned, which is a back edge in a circular graph. hook
+// receives the type and address of the source value the edge points to. A
+// pointer to a field of a struct that is still being cloned through a pointer
+// is a back edge too.
+//
+// References that are shared without forming a cycle do not call hook. A nil
+// hook is ignored.
+func WithCycleHook(hook func(t reflect.Type, addr uintptr)) Option {
+	return func(o *options) {
+		o.cycleHook = hook
+	}
+}
+
+// WithUnsupportedHook calls hook for each non-nil channel, function, or unsafe
+// pointer instead of failing the clone. hook receives the value's path and
+// kind. The clone gets a nil value in its place, except in unexported fields,
+// which cannot be written and keep sharing the source value. It lets callers
+// log or count such values while hardening a type, and a nil hook is ignored.
+//
+// Sync primitives, files, and other unsupported state are still rejected.
+func WithUnsupportedHook(hook func(path string, kind reflect.Kind)) Option {
+	return func(o *options) {
+		o.unsupportedHook = hook
+	}
+}
+
+// WithAllocator allocates the memory that clones point into with alloc instead
+// of the Go runtime, so clones of request-scoped data can live in an arena that
+// is released all at once.
+//
+// alloc receives a type t and must return a non-nil pointer of type *t to a
+// zero value; a different result fails the clone with an UnsupportedError. It
+// is called for every cloned pointer target and, with an array type, for the
+// backing array of every non-empty cloned slice. Maps, interface boxes, and
+// scratch values are still allocated by the runtime because Go cannot place
+// them in caller-owned memory.
+//
+// A clone is only valid for the lifetime of the memory alloc hands out: once an
+// arena is reset or freed, the clone and everything reachable from it must no
+// longer be used. A nil alloc is ignored.
+func WithAllocator(alloc func(t reflect.Type) reflect.Value) Option {
+	return func(o *options) {
+		o.allocator = alloc
+	}
+}
+
+// WithAfterClone calls AfterClone on every cloned struct, slice, or map that
+// implements AfterCloner, once its fields or elements have been cloned.
+func WithAfterClone() Option {
+	return func(o *options) {
+		o.afterClone = true
+	}
+}
+
+// WithStackSafetyMargin fails the clone with a LimitError once values are
+// nested deeper than the goroutine stack can safely hold, instead of crashing
+// the program with a stack overflow.
+//
+// The depth limit is derived from the runtime's default maximum goroutine
+// stack size. Only half of that stack is budgeted, leaving the rest for the
+// caller's frames. Programs that change the limit with
+// runtime/debug.SetMaxStack should pass the same size to WithStackBudget
+// instead, since the runtime cannot report it without changing it. Custom
+// Clone methods that call Clone start a new, separately limited clone.
+func WithStackSafetyMargin() Option {
+	return WithStackBudget(defaultMaxStack)
+}
+
+// WithStackBudget is WithStackSafetyMargin for a maximum goroutine stack of
+// maxStack bytes, such as the size passed to runtime/debug.SetMaxStack.
+func WithStackBudget(maxStack int) Option {
+	return func(o *options) {
+		o.maxDepth = stackSafeDepth(maxStack)
+	}
+}
+
+// stackSafeDepth estimates how many nested values fit in a stack of maxStack
+// bytes with the safety margin applied.
+func stackSafeDepth(maxStack int) int {
+	return max(maxStack/stackMarginDivisor/stackBytesPerLevel, 1)
+}
+
+// WithShareTypes shares every value whose type is exactly one of types instead
+// of cloning it, wherever it appears in the graph. A shared value is used as
+// is, without cloning or checking anything it references, which suits
+// loggers, clients, and other handles that clones should keep using. Sync
+// primitives held by value are still rejected. Nil types are ignored.
+func WithShareTypes(types ...reflect.Type) Option {
+	return func(o *options) {
+		for _, t := range types {
+			if t == nil {
+				continue
+			}
+			if o.shareTypes == nil {
+				o.shareTypes = make(map[reflect.Type]struct{}, len(types))
+			}
+			o.shareTypes[t] = struct{}{}
+		}
+	}
+}
+
+// WithDefaultSharePredicate shares every value whose type share reports true
+// for, like WithShareTypes but decided by a function, such as one matching
+// every type from a package path. share is called with the type of each value
+// cloning reaches, including interface types and the dynamic types they hold,
+// and with the target type of each pointer, before any other work on the
+// value, so it should be cheap. A pointer is shared when its target type is,
+// so a predicate need not match pointer types. A shared type
+// wins over its Clone method, and sync primitives held by value are still
+// rejected. A nil share removes an earlier predicate.
+func WithDefaultSharePredicate(share func(reflect.Type) bool) Option {
+	return func(o *options) {
+		o.sharePredicate = share
+	}
+}
+
+// WithShareIOInterfaces shares every value whose type implements io.Closer,
+// such as files, response bodies, and connections, instead of cloning it. A
+// structural copy of a stream duplicates its buffers and offsets while the
+// underlying resource stays the same, so the clone and the source would
+// interfere; sharing keeps one stream that both use.
+//
+// Values held in interfaces are checked by their dynamic type, and types with a
+// Clone method or a WithCloneFunc function are still cloned by it.
+func WithShareIOInterfaces() Option {
+	return func(o *options) {
+		o.shareIO = true
+	}
+}
+
+// WithStructuralTypes clones values whose type is one of types field by field
+// with reflection, ignoring their Clone methods. Listing a type also covers
+// pointers to it, so pointer receiver Clone methods are ignored too.
+// It suits call sites that need a faithful copy of a type whose Clone method
+// changes the value or shares state. Nil types are ignored.
+func WithStructuralTypes(types ...reflect.Type) Option {
+	return func(o *options) {
+		for _, t := range types {
+			if t == nil {
+				continue
+			}
+			if o.structuralTypes == nil {
+				o.structuralTypes = make(map[reflect.Type]struct{}, len(types))
+			}
+			o.structuralTypes[t] = struct{}{}
+		}
+	}
+}
+
+// WithForceReflection turns off the typed fast paths and bulk slice copies so
+// every value, including primitives and scalar slices, goes through the
+// reflection engine. The result is the same; only speed and allocation counts
+// change, which keeps allocation counts in tests stable regardless of which
+// fast path a value would otherwise take.
+func WithForceReflection() Option {
+	return func(o *options) {
+		o.forceReflection = true
+	}
+}
+
+// WithCloneFunc clones every value of exactly type T with fn, as if T had a
+// Clone method. It lets callers supply cloning for types they cannot add
+// methods to, such as third-party values that keep their state in unexported
+// pointers. A Clone method on T still takes precedence. fn runs for every
+// element of a slice, array, or map of T, including arrays nested in them,
+// but struct fields whose type holds no references are copied by assignment
+// with the struct, without calling fn. A nil fn is ignored.
+func WithCloneFunc[T any](fn func(T) (T, error)) Option {
+	return func(o *options) {
+		if fn == nil {
+			return
+		}
+		if o.cloneFuncs == nil {
+			o.cloneFuncs = make(map[reflect.Type]func(reflect.Value) (reflect.Value, error))
+		}
+		o.cloneFuncs[reflect.TypeFor[T]()] = reflectCloneFunc(fn)
+	}
+}
+
+// reflectCloneFunc adapts fn to the reflect.Value form the engine calls.
+func reflectCloneFunc[T any](fn func(T) (T, error)) func(reflect.Value) (reflect.Value, error) {
+	return func(v reflect.Value) (reflect.Value, error) {
+		cloned, err := fn(v.Interface().(T))
+		return reflect.ValueOf(&cloned).Elem(), err
+	}
+}
+
+// WithJSONFallback clones struct types that can describe their own state as
+// JSON by marshaling and unmarshaling them instead of walking their fields.
+// It applies to structs whose pointer implements both json.Marshaler and
+// json.Unmarshaler, which lets types with private state be cloned without
+// implementing Cloner[T].
+//
+// The clone is only as faithful as the type's JSON round trip, so enable it
+// only for types that round-trip losslessly. Fallbacks apply in this order:
+// WithShareTypes, a Clone method or WithCloneFunc, the JSON round trip, and
+// finally reflection. Structs that are copied exactly by assignment are never
+// round-tripped.
+func WithJSONFallback() Option {
+	return func(o *options) {
+		o.jsonFallback = true
+	}
+}
+
+// WithRejectOpaqueStructs fails the clone with an UnsupportedError for any
+// struct that has no exported fields but holds references, such as pointers or
+// maps, in its unexported ones, even when they are nil. Such structs are
+// usually library handles whose copy is rarely what the caller wants, so the
+// error asks for a Clone method or WithCloneFunc instead. Types with either
+// are not affected, nor are Immutable types, structs cloned by WithJSONFallback,
+// and standard library value types such as time.Time and netip.Addr.
+func WithRejectOpaqueStructs() Option {
+	return func(o *options) {
+		o.rejectOpaque = true
+	}
+}
+
+// WithPreserveBackingArrays keeps slices that share a backing array sharing
+// one in the clone, even when they overlap without starting at the same
+// element. Given a and b := a[2:5], the clone of b is a window into the clone
+// of a, so writes through either are visible through the other.
+//
+// Before cloning, the graph is scanned once to find the widest view of each
+// backing array, and every array is then cloned once up to the last element
+// any slice uses. Without the option, only slices with the same start,
+// length, and capacity share a clone.
+func WithPreserveBackingArrays() Option {
+	return func(o *options) {
+		o.preserveBacking = true
+	}
+}
+
+// WithDeterministicOrder clones the entries of maps whose keys are integers,
+// floats, or strings in ascending key order, so Clone methods and hooks with
+// side effects run in the same order on every call. Entries that hold no
+// references are copied, which has no observable order, and other key types
+// keep Go's random iteration order. Sorting costs time, so order is random by
+// default.
+func WithDeterministicOrder() Option {
+	return func(o *options) {
+		o.deterministicOrder = true
+	}
+}
+
+// emptyCollections selects what WithNormalizeEmpty does with empty slices and
+// maps.
+type emptyCollections uint8
+
+const (
+	// keepEmpty preserves whether each slice and map is nil, the default.
+	keepEmpty emptyCollections = iota
+	// emptyToNil clones slices and maps of length zero to nil.
+	emptyToNil
+	// nilToEmpty clones nil slices and maps to empty ones.
+	nilToEmpty
+)
+
+// WithNormalizeEmpty makes the clone use one representation for empty slices
+// and maps, so code comparing clones does not have to tell nil and empty
+// apart. With toNil, every slice and map of length zero, including a slice
+// with spare capacity, is cloned to nil; otherwise every nil slice and map is
+// cloned to an empty one. Without the option each keeps its exact nil-ness.
+//
+// Only collections the clone walks are normalized. Results of Clone methods
+// and WithCloneFunc functions, shared values, and unexported fields are kept
+// as they are.
+func WithNormalizeEmpty(toNil bool) Option {
+	return func(o *options) {
+		if toNil {
+			o.emptyCollections = emptyToNil
+		} else {
+			o.emptyCollections = nilToEmpty
+		}
+	}
+}
+
+// WithRecover returns a panic in a Clone method, a WithCloneFunc function, or
+// a transform as a *PanicError holding the panic value, instead of letting it
+// unwind through the caller. Panics propagate by default, which keeps the
+// stack trace for debugging. AfterClone methods and hooks are not covered.
+func WithRecover() Option {
+	return func(o *options) {
+		o.recoverPanics = true
+	}
+}
+
+// HandlePolicy chooses what cloning does with OS handles: values whose type
+// has an Fd() uintptr method, such as *os.File. A handle names a resource
+// owned by the operating system, so no copy of its memory is a second handle.
+type HandlePolicy int
+
+const (
+	// ShareHandles gives the clone the same handle as the source, the default.
+	// Closing or seeking through either affects both, and CloneDisjoint
+	// reports the sharing.
+	ShareHandles HandlePolicy = iota
+	// ZeroHandles leaves exported handle fields and elements nil in the clone.
+	// Unexported fields keep the source handle, as with WithUnsupportedHook.
+	ZeroHandles
+	// RejectHandles returns an UnsupportedError for every non-nil handle.
+	RejectHandles
+)
+
+// WithHandlePolicy sets what cloning does with OS handles such as *os.File.
+// Handle types with a Clone method or a WithCloneFunc function are cloned by
+// it under every policy. An os.File held by value is always rejected, since no
+// copy of it can be shared.
+func WithHandlePolicy(policy HandlePolicy) Option {
+	return func(o *options) {
+		o.handlePolicy = policy
+	}
+}
+
+// BufferPolicy chooses what cloning does with a *bufio.Reader or
+// *bufio.Writer. Their buffers hold bytes read ahead of or not yet written to
+// a stream the clone cannot reach, so no copy of their memory is a second
+// reader or writer.
+type BufferPolicy int
+
+const (
+	// RejectBuffers returns an UnsupportedError for every non-nil reader and
+	// writer, the default.
+	RejectBuffers BufferPolicy = iota
+	// RewrapBuffers gives the clone a new reader or writer with an empty
+	// buffer of the same size that reads from or writes to the source one.
+	// Bytes buffered in the source are not copied: a source reader hands
+	// them to whichever of the two reads first, and bytes flushed from a
+	// cloned writer reach the stream when the source writer is flushed.
+	RewrapBuffers
+	// ZeroBuffers leaves exported reader and writer fields and elements nil
+	// in the clone. Unexported fields keep the source reader or writer, as
+	// with WithUnsupportedHook.
+	ZeroBuffers
+)
+
+// WithBufferPolicy sets what cloning does with a *bufio.Reader or
+// *bufio.Writer. A WithCloneFunc function for either type wins under every
+// policy. A bufio.Reader or bufio.Writer held by value is always rejected.
+func WithBufferPolicy(policy BufferPolicy) Option {
+	return func(o *options) {
+		o.bufferPolicy = policy
+	}
+}
+
+// skipsFastPaths reports whether an option must see every value, which rules
+// out the typed fast paths.
+func (o options) skipsFastPaths() bool {
+	return o.maxCollectionLen > 0 || o.allocator != nil || len(o.shareTypes) > 0 || o.sharePredicate != nil || o.shareIO || o.stats != nil ||
+		o.forceReflection || o.shallowType != nil || len(o.cloneFuncs) > 0 || o.preserveBacking ||
+		o.emptyCollections != keepEmpty
+}
+
+// CloneWith returns a deep copy of src configured by opts.
+//
+// CloneWith with no options behaves exactly like Clone.
+func CloneWith[T any](src T, opts ...Option) (T, error) {
+	return cloneWith(src, newOptions(opts))
+}
+
+// CloneExcept returns a deep copy of src that shares every value whose type is
+// one of share. It is shorthand for CloneWith(src, WithShareTypes(share...)).
+func CloneExcept[T any](src T, share ...reflect.Type) (T, error) {
+	return CloneWith(src, WithShareTypes(share...))
+}
+
+// CloneShallowFields returns a copy of the struct src, or of the struct src
+// points to, that deep-clones only the exported fields named in deep and shares
+// the values of every other exported reference field with src. Wherever the
+// same struct type appears in the graph it is treated the same way. It suits
+// third-party structs that cannot carry clone tags.
+//
+// A src that is not a struct or a pointer to one, or a name in deep that is not
+// an exported field, fails the clone with an UnsupportedError.
+func CloneShallowFields[T any](src T, deep ...string) (T, error) {
+	t := reflect.TypeFor[T]()
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		var zero T
+		return zero, unsupportedError("$", reflect.TypeFor[T](), "shallow fields require a struct or a pointer to a struct")
+	}
+
+	deepFields := make(map[string]struct{}, len(deep))
+	for _, name := range deep {
+		if field, ok := t.FieldByName(name); !ok || !field.IsExported() || len(field.Index) != 1 {
+			var zero T
+			return zero, unsupportedError(fieldPath("$", name), t, "no exported field with this name")
+		}
+		deepFields[name] = struct{}{}
+	}
+	opts := newOptions(nil)
+	opts.shallowType, opts.deepFields = t, deepFields
+	return cloneWith(src, opts)
+}
+
+// MustCloneWith returns a deep copy of src configured by opts or panics if src
+// cannot be cloned.
+func MustCloneWith[T any](src T, opts ...Option) T {
+	cloned, err := CloneWith(src, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return cloned
+}
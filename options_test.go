@@ -0,0 +1,1927 @@
+package deepclone
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"math/big"
+	"net/netip"
+	"net/url"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type diamondNode struct {
+	Name  string
+	Left  *diamondNode
+	Right *diamondNode
+	Next  *diamondNode
+}
+
+func newDiamond() *diamondNode {
+	bottom := &diamondNode{Name: "bottom"}
+	return &diamondNode{
+		Name:  "top",
+		Left:  &diamondNode{Name: "left", Next: bottom},
+		Right: &diamondNode{Name: "right", Next: bottom},
+	}
+}
+
+func TestCloneWithNoOptionsMatchesClone(t *testing.T) {
+	t.Parallel()
+	original := newDiamond()
+
+	cloned, err := CloneWith(original)
+	require.NoError(t, err)
+
+	assert.Equal(t, original, cloned)
+	assert.True(t, cloned.Left.Next == cloned.Right.Next)
+	assert.Equal(t, []int{1, 2}, MustCloneWith([]int{1, 2}, nil))
+}
+
+// TestSetDefaultOptions runs serially because it changes the package-global
+// defaults.
+func TestSetDefaultOptions(t *testing.T) {
+	t.Cleanup(func() { SetDefaultOptions() })
+	type job struct {
+		Log   *testLogger
+		Ref   *visitCounter
+		Steps []int
+	}
+	original := job{Log: &testLogger{}, Ref: &visitCounter{}, Steps: []int{1, 2, 3}}
+
+	SetDefaultOptions(WithMaxCollectionLen(2), WithShareTypes(reflect.TypeFor[*testLogger]()))
+
+	_, err := Clone(original.Steps)
+	var limit *LimitError
+	require.ErrorAs(t, err, &limit, "Clone should honor the defaults")
+	_, err = Clone(original)
+	require.ErrorAs(t, err, &limit)
+
+	cloned, err := CloneWith(original, WithMaxCollectionLen(0), WithShareTypes(reflect.TypeFor[*visitCounter]()))
+	require.NoError(t, err, "call options should override the defaults")
+	assert.Same(t, original.Log, cloned.Log, "defaults should still apply")
+	assert.Same(t, original.Ref, cloned.Ref)
+
+	_, disjoint, err := CloneDisjoint(job{Log: original.Log})
+	require.NoError(t, err)
+	assert.False(t, disjoint, "CloneDisjoint should honor the defaults")
+
+	SetDefaultOptions(WithShareTypes(reflect.TypeFor[*testLogger]()))
+	cloned, err = Clone(original)
+	require.NoError(t, err)
+	assert.Same(t, original.Log, cloned.Log)
+	assert.NotSame(t, original.Ref, cloned.Ref, "call options should not leak into the defaults")
+
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Go(func() {
+			for range 100 {
+				_, _ = Clone(original)
+			}
+		})
+	}
+	SetDefaultOptions(WithMaxCollectionLen(10))
+	wg.Wait()
+
+	SetDefaultOptions()
+	_, err = Clone(original)
+	var unsupported *UnsupportedError
+	require.ErrorAs(t, err, &unsupported, "no options should clear the defaults, so the logger's mutex is rejected")
+}
+
+func TestCloneWithExpandSharedPointers(t *testing.T) {
+	t.Parallel()
+
+	t.Run("diamond", func(t *testing.T) {
+		t.Parallel()
+		original := newDiamond()
+
+		shared := MustClone(original)
+		expanded := MustCloneWith(original, WithExpandSharedPointers())
+
+		assert.True(t, shared.Left.Next == shared.Right.Next, "default clone should keep one shared copy")
+		assert.Equal(t, original, expanded)
+		assert.False(t, expanded.Left.Next == expanded.Right.Next, "expanded clone should have two copies")
+		assert.False(t, expanded.Left.Next == original.Left.Next)
+
+		expanded.Left.Next.Name = "changed"
+		assert.Equal(t, "bottom", expanded.Right.Next.Name)
+	})
+
+	t.Run("shared map and slice", func(t *testing.T) {
+		t.Parallel()
+		type holder struct {
+			A map[string]any
+			B map[string]any
+			C []any
+			D []any
+		}
+		sharedMap := map[string]any{"k": "v"}
+		sharedSlice := []any{"x"}
+		original := holder{A: sharedMap, B: sharedMap, C: sharedSlice, D: sharedSlice}
+
+		expanded := MustCloneWith(original, WithExpandSharedPointers())
+
+		expanded.A["k"] = "changed"
+		expanded.C[0] = "changed"
+		assert.Equal(t, "v", expanded.B["k"])
+		assert.Equal(t, "x", expanded.D[0])
+	})
+
+	t.Run("pointer to field", func(t *testing.T) {
+		t.Parallel()
+		type node struct {
+			Value int
+		}
+		type holder struct {
+			Node node
+			Ref  *node
+		}
+		original := &holder{Node: node{Value: 7}}
+		original.Ref = &original.Node
+
+		expanded := MustCloneWith(original, WithExpandSharedPointers())
+
+		require.NotNil(t, expanded.Ref)
+		assert.Equal(t, 7, expanded.Ref.Value)
+		assert.False(t, expanded.Ref == &expanded.Node)
+		assert.False(t, expanded.Ref == &original.Node)
+	})
+
+	t.Run("cycles are preserved", func(t *testing.T) {
+		t.Parallel()
+		original := newDiamond()
+		original.Left.Next.Next = original
+
+		expanded := MustCloneWith(original, WithExpandSharedPointers())
+
+		assert.True(t, expanded.Left.Next.Next == expanded, "back edge should point at the cloned root")
+		assert.True(t, expanded.Right.Next.Next == expanded, "back edge should point at the cloned root")
+		assert.False(t, expanded.Left.Next == expanded.Right.Next)
+	})
+
+	t.Run("map cycles are preserved", func(t *testing.T) {
+		t.Parallel()
+		original := map[string]any{"key": "value"}
+		original["self"] = original
+
+		expanded := MustCloneWith(original, WithExpandSharedPointers())
+
+		inner, ok := expanded["self"].(map[string]any)
+		require.True(t, ok)
+		inner["new"] = true
+		assert.Equal(t, true, expanded["new"])
+		assert.NotContains(t, original, "new")
+	})
+}
+
+func TestCloneWithMaxCollectionLen(t *testing.T) {
+	t.Parallel()
+
+	t.Run("slice over limit", func(t *testing.T) {
+		t.Parallel()
+		_, err := CloneWith([]int{1, 2, 3}, WithMaxCollectionLen(2))
+
+		var limit *LimitError
+		require.ErrorAs(t, err, &limit)
+		assert.Equal(t, "$", limit.Path)
+		assert.Equal(t, 2, limit.Max)
+		assert.Equal(t, 3, limit.Actual)
+		assert.Equal(t, "deepclone: collection length 3 at $ ([]int) exceeds limit 2", err.Error())
+	})
+
+	t.Run("map over limit", func(t *testing.T) {
+		t.Parallel()
+		_, err := CloneWith(map[string]int{"a": 1, "b": 2}, WithMaxCollectionLen(1))
+
+		var limit *LimitError
+		require.ErrorAs(t, err, &limit)
+		assert.Equal(t, reflect.TypeFor[map[string]int](), limit.Type)
+		assert.Equal(t, 2, limit.Actual)
+	})
+
+	t.Run("nested collection reports its path", func(t *testing.T) {
+		t.Parallel()
+		type payload struct {
+			Items map[string][]string
+		}
+		src := payload{Items: map[string][]string{"k": {"a", "b", "c"}}}
+
+		_, err := CloneWith(src, WithMaxCollectionLen(2))
+
+		var limit *LimitError
+		require.ErrorAs(t, err, &limit)
+		assert.Equal(t, `$.Items["k"]`, limit.Path)
+	})
+
+	t.Run("oversized field among small ones", func(t *testing.T) {
+		t.Parallel()
+		type request struct {
+			Name    string
+			Headers []string
+			Body    []byte
+			Parts   [][]byte
+		}
+		src := &request{
+			Name:    "upload",
+			Headers: []string{"a", "b"},
+			Body:    make([]byte, 1<<20),
+			Parts:   [][]byte{make([]byte, 1<<20)},
+		}
+
+		_, err := CloneWith(src, WithMaxCollectionLen(1024))
+
+		var limit *LimitError
+		require.ErrorAs(t, err, &limit)
+		assert.Equal(t, "$.Body", limit.Path, "the first oversized collection should be reported")
+		assert.Equal(t, reflect.TypeFor[[]byte](), limit.Type)
+		assert.Equal(t, 1<<20, limit.Actual)
+	})
+
+	t.Run("at limit succeeds", func(t *testing.T) {
+		t.Parallel()
+		src := map[string][]int{"a": {1, 2}, "b": {3}}
+
+		cloned, err := CloneWith(src, WithMaxCollectionLen(2))
+
+		require.NoError(t, err)
+		assert.Equal(t, src, cloned)
+	})
+
+	t.Run("non-positive limit is ignored", func(t *testing.T) {
+		t.Parallel()
+		cloned, err := CloneWith([]int{1, 2, 3}, WithMaxCollectionLen(0))
+
+		require.NoError(t, err)
+		assert.Equal(t, []int{1, 2, 3}, cloned)
+	})
+}
+
+func TestCloneWithCycleHook(t *testing.T) {
+	t.Parallel()
+
+	type cycle struct {
+		typ  reflect.Type
+		addr uintptr
+	}
+	record := func(cycles *[]cycle) Option {
+		return WithCycleHook(func(typ reflect.Type, addr uintptr) {
+			*cycles = append(*cycles, cycle{typ: typ, addr: addr})
+		})
+	}
+
+	t.Run("pointer back edge", func(t *testing.T) {
+		t.Parallel()
+		type Node struct {
+			Value int
+			Next  *Node
+		}
+		node1 := &Node{Value: 1}
+		node2 := &Node{Value: 2, Next: node1}
+		node1.Next = node2
+		var cycles []cycle
+
+		cloned, err := CloneWith(node1, record(&cycles))
+
+		require.NoError(t, err)
+		assert.True(t, cloned.Next.Next == cloned)
+		assert.Equal(t, []cycle{{typ: reflect.TypeFor[*Node](), addr: reflect.ValueOf(node1).Pointer()}}, cycles)
+	})
+
+	t.Run("map and slice back edges", func(t *testing.T) {
+		t.Parallel()
+		m := map[string]any{"key": "value"}
+		m["self"] = m
+		s := make([]any, 1)
+		s[0] = s
+		var cycles []cycle
+
+		_, err := CloneWith([]any{m, s}, record(&cycles))
+
+		require.NoError(t, err)
+		assert.Equal(t, []cycle{
+			{typ: reflect.TypeFor[map[string]any](), addr: reflect.ValueOf(m).Pointer()},
+			{typ: reflect.TypeFor[[]any](), addr: reflect.ValueOf(s).Pointer()},
+		}, cycles)
+	})
+
+	t.Run("pointer to a field back edge", func(t *testing.T) {
+		t.Parallel()
+		type header struct {
+			Name string
+		}
+		type section struct {
+			Owner *header
+		}
+		type document struct {
+			Header   header
+			Sections []section
+		}
+		doc := &document{Header: header{Name: "spec"}}
+		doc.Sections = []section{{Owner: &doc.Header}}
+		var cycles []cycle
+
+		cloned, err := CloneWith(doc, record(&cycles))
+
+		require.NoError(t, err)
+		assert.Same(t, &cloned.Header, cloned.Sections[0].Owner)
+		assert.Equal(t, []cycle{{typ: reflect.TypeFor[*header](), addr: reflect.ValueOf(&doc.Header).Pointer()}}, cycles)
+	})
+
+	t.Run("shared references are not cycles", func(t *testing.T) {
+		t.Parallel()
+		var cycles []cycle
+
+		cloned, err := CloneWith(newDiamond(), record(&cycles))
+
+		require.NoError(t, err)
+		assert.True(t, cloned.Left.Next == cloned.Right.Next)
+		assert.Empty(t, cycles)
+	})
+
+	t.Run("nil hook", func(t *testing.T) {
+		t.Parallel()
+		original := newDiamond()
+		original.Left.Next.Next = original
+
+		cloned, err := CloneWith(original, WithCycleHook(nil))
+
+		require.NoError(t, err)
+		assert.True(t, cloned.Left.Next.Next == cloned)
+	})
+}
+
+func TestCloneWithAllocator(t *testing.T) {
+	t.Parallel()
+
+	counting := func(counts map[reflect.Type]int) Option {
+		return WithAllocator(func(typ reflect.Type) reflect.Value {
+			counts[typ]++
+			return reflect.New(typ)
+		})
+	}
+
+	t.Run("called for each node", func(t *testing.T) {
+		t.Parallel()
+		type node struct {
+			Value int
+			Next  *node
+		}
+		original := &node{Value: 1, Next: &node{Value: 2, Next: &node{Value: 3}}}
+		original.Next.Next.Next = original
+		counts := make(map[reflect.Type]int)
+
+		cloned, err := CloneWith(original, counting(counts))
+
+		require.NoError(t, err)
+		assert.Equal(t, map[reflect.Type]int{reflect.TypeFor[node](): 3}, counts)
+		assert.True(t, cloned.Next.Next.Next == cloned)
+	})
+
+	t.Run("slice backing arrays", func(t *testing.T) {
+		t.Parallel()
+		type labels []string
+		original := struct {
+			Values []int
+			Names  labels
+			Empty  []int
+		}{Values: make([]int, 2, 4), Names: labels{"a"}, Empty: []int{}}
+		counts := make(map[reflect.Type]int)
+
+		cloned, err := CloneWith(original, counting(counts))
+
+		require.NoError(t, err)
+		assert.Equal(t, original, cloned)
+		assert.Equal(t, 4, cap(cloned.Values))
+		assert.Equal(t, map[reflect.Type]int{
+			reflect.TypeFor[[4]int]():    1,
+			reflect.TypeFor[[1]string](): 1,
+		}, counts)
+	})
+
+	t.Run("clones point into allocated memory", func(t *testing.T) {
+		t.Parallel()
+		var arena []reflect.Value
+		alloc := WithAllocator(func(typ reflect.Type) reflect.Value {
+			ptr := reflect.New(typ)
+			arena = append(arena, ptr)
+			return ptr
+		})
+
+		cloned, err := CloneWith(&benchSimple{ID: 1}, alloc)
+
+		require.NoError(t, err)
+		require.Len(t, arena, 1)
+		assert.True(t, arena[0].Interface().(*benchSimple) == cloned)
+	})
+
+	t.Run("wrong result type", func(t *testing.T) {
+		t.Parallel()
+		alloc := WithAllocator(func(reflect.Type) reflect.Value {
+			return reflect.ValueOf(new(string))
+		})
+
+		_, err := CloneWith(struct{ Ref *int }{Ref: new(int)}, alloc)
+
+		var unsupported *UnsupportedError
+		require.ErrorAs(t, err, &unsupported)
+		assert.Equal(t, "$.Ref", unsupported.Path)
+		assert.Equal(t, "allocator must return a non-nil pointer to the requested type", unsupported.Reason)
+	})
+
+	t.Run("nil allocator", func(t *testing.T) {
+		t.Parallel()
+		cloned, err := CloneWith([]int{1, 2}, WithAllocator(nil))
+
+		require.NoError(t, err)
+		assert.Equal(t, []int{1, 2}, cloned)
+	})
+}
+
+type indexedList struct {
+	Items []string
+	index map[string]int `clone:"shallow"`
+	calls int
+}
+
+func newIndexedList(items ...string) *indexedList {
+	l := &indexedList{Items: items}
+	l.AfterClone()
+	l.calls = 0
+	return l
+}
+
+// AfterClone rebuilds the index so the clone does not share it.
+func (l *indexedList) AfterClone() {
+	l.calls++
+	l.index = make(map[string]int, len(l.Items))
+	for i, item := range l.Items {
+		l.index[item] = i
+	}
+}
+
+type checksum struct {
+	A, B  int
+	Total int
+}
+
+func (c *checksum) AfterClone() {
+	c.Total = c.A + c.B
+}
+
+// clonedIDs marks each clone by appending -1, through a pointer receiver that
+// replaces the slice header.
+type clonedIDs []int
+
+func (ids *clonedIDs) AfterClone() {
+	*ids = append(*ids, -1)
+}
+
+// tally counts its clones in the "clones" entry.
+type tally map[string]int
+
+func (t tally) AfterClone() {
+	t["clones"]++
+}
+
+func TestCloneWithAfterClone(t *testing.T) {
+	t.Parallel()
+
+	t.Run("rebuilds index once", func(t *testing.T) {
+		t.Parallel()
+		original := newIndexedList("a", "b")
+
+		cloned := MustCloneWith(original, WithAfterClone())
+
+		assert.Equal(t, 1, cloned.calls)
+		assert.Zero(t, original.calls)
+		cloned.index["c"] = 2
+		assert.NotContains(t, original.index, "c")
+		assert.Equal(t, map[string]int{"a": 0, "b": 1}, original.index)
+	})
+
+	t.Run("values inside containers", func(t *testing.T) {
+		t.Parallel()
+		type holder struct {
+			Lists  []indexedList
+			ByName map[string]*indexedList
+			Fixed  [1]indexedList
+		}
+		shared := newIndexedList("x")
+		original := holder{
+			Lists:  []indexedList{*newIndexedList("a"), *newIndexedList("b")},
+			ByName: map[string]*indexedList{"one": shared, "two": shared},
+			Fixed:  [1]indexedList{*newIndexedList("f")},
+		}
+
+		cloned := MustCloneWith(original, WithAfterClone())
+
+		assert.Equal(t, 1, cloned.Lists[0].calls)
+		assert.Equal(t, 1, cloned.Lists[1].calls)
+		assert.Equal(t, 1, cloned.Fixed[0].calls)
+		assert.True(t, cloned.ByName["one"] == cloned.ByName["two"])
+		assert.Equal(t, 1, cloned.ByName["one"].calls, "shared values should be called once")
+	})
+
+	t.Run("inside otherwise plain struct", func(t *testing.T) {
+		t.Parallel()
+		type stamp struct {
+			ID  int
+			Sum checksum
+		}
+		original := stamp{ID: 1, Sum: checksum{A: 2, B: 3}}
+
+		cloned := MustCloneWith(original, WithAfterClone())
+
+		assert.Equal(t, 5, cloned.Sum.Total)
+		assert.Zero(t, original.Sum.Total)
+	})
+
+	t.Run("named slices and maps", func(t *testing.T) {
+		t.Parallel()
+		ids := clonedIDs{1, 2}
+		counts := tally{"a": 1}
+
+		assert.Equal(t, clonedIDs{1, 2, -1}, MustCloneWith(ids, WithAfterClone()))
+		assert.Equal(t, tally{"a": 1, "clones": 1}, MustCloneWith(counts, WithAfterClone()))
+		assert.Equal(t, clonedIDs{1, 2}, ids)
+		assert.Equal(t, tally{"a": 1}, counts)
+	})
+
+	t.Run("named slice and map fields", func(t *testing.T) {
+		t.Parallel()
+		type report struct {
+			IDs    clonedIDs
+			Counts tally
+			Nested []clonedIDs
+		}
+		ids := clonedIDs{1}
+		original := &report{IDs: ids, Counts: tally{}, Nested: []clonedIDs{ids, {3}}}
+
+		for _, opts := range [][]Option{{WithAfterClone()}, {WithAfterClone(), WithPreserveBackingArrays()}} {
+			cloned := MustCloneWith(original, opts...)
+
+			assert.Equal(t, clonedIDs{1, -1}, cloned.IDs)
+			assert.Equal(t, tally{"clones": 1}, cloned.Counts)
+			assert.Equal(t, []clonedIDs{{1, -1}, {3, -1}}, cloned.Nested)
+		}
+		assert.Equal(t, clonedIDs{1}, original.IDs)
+		assert.Empty(t, original.Counts)
+	})
+
+	t.Run("not called without the option", func(t *testing.T) {
+		t.Parallel()
+		original := newIndexedList("a")
+
+		cloned := MustClone(original)
+
+		assert.Zero(t, cloned.calls)
+		assert.Equal(t, clonedIDs{1}, MustClone(clonedIDs{1}))
+		assert.Equal(t, tally{}, MustClone(tally{}))
+	})
+}
+
+func TestStackSafeDepth(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, 262144, stackSafeDepth(1<<30))
+	assert.Equal(t, 1, stackSafeDepth(0))
+}
+
+func TestCloneWithStackBudget(t *testing.T) {
+	t.Parallel()
+	const maxStack = 8 << 20
+	limit := stackSafeDepth(maxStack)
+
+	type node struct {
+		Value int
+		Next  *node
+	}
+	chain := func(length int) *node {
+		var root *node
+		for i := range length {
+			root = &node{Value: i, Next: root}
+		}
+		return root
+	}
+
+	// Far deeper than an 8 MiB stack can clone without the guard.
+	_, err := CloneWith(chain(50_000), WithStackBudget(maxStack))
+
+	var depthErr *LimitError
+	require.ErrorAs(t, err, &depthErr)
+	assert.Equal(t, "depth", depthErr.Limit)
+	assert.Equal(t, limit, depthErr.Max)
+	assert.Equal(t, limit+1, depthErr.Actual)
+
+	cloned, err := CloneWith(chain(limit/4), WithStackBudget(maxStack))
+	require.NoError(t, err)
+	assert.Equal(t, limit/4-1, cloned.Value)
+}
+
+func TestCloneWithStackSafetyMargin(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, stackSafeDepth(defaultMaxStack), newOptions([]Option{WithStackSafetyMargin()}).maxDepth)
+
+	cloned, err := CloneWith([]int{1, 2}, WithStackSafetyMargin())
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, cloned)
+}
+
+type testLogger struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+func (l *testLogger) Log(line string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.lines = append(l.lines, line)
+}
+
+func TestCloneExcept(t *testing.T) {
+	t.Parallel()
+	type job struct {
+		Name   string
+		Steps  []string
+		Log    *testLogger
+		Logged any
+	}
+	loggerType := reflect.TypeFor[*testLogger]()
+
+	t.Run("shares listed types", func(t *testing.T) {
+		t.Parallel()
+		logger := &testLogger{}
+		original := &job{Name: "build", Steps: []string{"compile"}, Log: logger, Logged: logger}
+
+		cloned, err := CloneExcept(original, loggerType)
+
+		require.NoError(t, err)
+		assert.Same(t, logger, cloned.Log)
+		assert.Same(t, logger, cloned.Logged)
+		assert.NotSame(t, &original.Steps[0], &cloned.Steps[0])
+		cloned.Log.Log("from clone")
+		assert.Equal(t, []string{"from clone"}, logger.lines)
+	})
+
+	t.Run("listed types inside collections", func(t *testing.T) {
+		t.Parallel()
+		logger := &testLogger{}
+		original := map[string][]*testLogger{"a": {logger, nil}}
+
+		cloned, err := CloneExcept(original, loggerType)
+
+		require.NoError(t, err)
+		assert.Same(t, logger, cloned["a"][0])
+		assert.Nil(t, cloned["a"][1])
+	})
+
+	t.Run("pointers to listed struct types", func(t *testing.T) {
+		t.Parallel()
+		type settings struct {
+			Level int
+			Tags  []string
+		}
+		type pipeline struct {
+			Current *settings
+			History []*settings
+		}
+		first, second := &settings{Level: 1, Tags: []string{"a"}}, &settings{Level: 2}
+		original := &pipeline{Current: first, History: []*settings{first, second, nil}}
+
+		cloned, err := CloneExcept(original, reflect.TypeFor[settings]())
+
+		require.NoError(t, err)
+		assert.NotSame(t, original, cloned)
+		assert.Same(t, first, cloned.Current)
+		assert.Same(t, first, cloned.History[0], "the batched []*T path should share too")
+		assert.Same(t, second, cloned.History[1])
+		assert.Nil(t, cloned.History[2])
+		assert.NotSame(t, &original.History[0], &cloned.History[0])
+
+		target := &url.URL{Host: "example.com"}
+		shared, err := CloneExcept(target, reflect.TypeFor[url.URL]())
+		require.NoError(t, err)
+		assert.Same(t, target, shared, "a shared target type should win over the pointer's Clone method")
+	})
+
+	t.Run("unlisted types are still checked", func(t *testing.T) {
+		t.Parallel()
+		_, err := CloneExcept(&job{Log: &testLogger{}})
+
+		var unsupported *UnsupportedError
+		require.ErrorAs(t, err, &unsupported)
+		assert.Equal(t, "$.Log.mu", unsupported.Path)
+	})
+
+	t.Run("records sharing", func(t *testing.T) {
+		t.Parallel()
+		var shared bool
+		_, err := CloneWith(&job{Log: &testLogger{}}, WithShareTypes(loggerType), func(o *options) { o.shared = &shared })
+
+		require.NoError(t, err)
+		assert.True(t, shared)
+	})
+
+	t.Run("top-level scalar slice type", func(t *testing.T) {
+		t.Parallel()
+		original := []int{1, 2}
+
+		cloned, err := CloneExcept(original, reflect.TypeFor[[]int]())
+
+		require.NoError(t, err)
+		assert.Same(t, &original[0], &cloned[0])
+	})
+}
+
+func TestCloneWithDefaultSharePredicate(t *testing.T) {
+	t.Parallel()
+	type endpoint struct {
+		Name    string
+		Target  *url.URL
+		Query   url.Values
+		Aliases []*url.URL
+		Meta    map[string]any
+		Tags    []string
+	}
+	// Only named types have a package path, so the predicate matches url.URL
+	// but not *url.URL. Pointers to a URL are shared because their target is.
+	fromURLPackage := WithDefaultSharePredicate(func(t reflect.Type) bool {
+		return t.PkgPath() == "net/url"
+	})
+	newEndpoint := func() *endpoint {
+		target := &url.URL{Scheme: "https", Host: "example.com", Path: "/v1"}
+		return &endpoint{
+			Name:    "api",
+			Target:  target,
+			Query:   url.Values{"page": {"1"}},
+			Aliases: []*url.URL{target, {Host: "mirror.example.com"}},
+			Meta:    map[string]any{"base": target, "retries": []int{1, 2}},
+			Tags:    []string{"public"},
+		}
+	}
+
+	t.Run("shares matching subtrees", func(t *testing.T) {
+		t.Parallel()
+		original := newEndpoint()
+
+		cloned, err := CloneWith(original, fromURLPackage)
+
+		require.NoError(t, err)
+		assert.Equal(t, original, cloned)
+		assert.Same(t, original.Target, cloned.Target)
+		assert.Same(t, original.Aliases[1], cloned.Aliases[1])
+		assert.Same(t, original.Target, cloned.Meta["base"], "types held in interfaces should be shared too")
+		cloned.Query.Set("page", "2")
+		assert.Equal(t, "2", original.Query.Get("page"), "a shared map should stay shared")
+
+		cloned.Tags[0] = "changed"
+		cloned.Aliases[0] = nil
+		cloned.Meta["retries"].([]int)[0] = 9
+		assert.Equal(t, "public", original.Tags[0])
+		assert.Same(t, original.Target, original.Aliases[0])
+		assert.Equal(t, []int{1, 2}, original.Meta["retries"])
+	})
+
+	t.Run("top-level value", func(t *testing.T) {
+		t.Parallel()
+		target := &url.URL{Host: "example.com"}
+
+		cloned, err := CloneWith(target, fromURLPackage)
+
+		require.NoError(t, err)
+		assert.Same(t, target, cloned)
+	})
+
+	t.Run("records sharing", func(t *testing.T) {
+		t.Parallel()
+		var shared bool
+		_, err := CloneWith(newEndpoint(), fromURLPackage, func(o *options) { o.shared = &shared })
+
+		require.NoError(t, err)
+		assert.True(t, shared)
+	})
+
+	t.Run("nil predicate clones everything", func(t *testing.T) {
+		t.Parallel()
+		original := newEndpoint()
+
+		cloned, err := CloneWith(original, fromURLPackage, WithDefaultSharePredicate(nil))
+
+		require.NoError(t, err)
+		assert.Equal(t, original, cloned)
+		assert.NotSame(t, original.Target, cloned.Target)
+		assert.Same(t, cloned.Target, cloned.Aliases[0], "sharing within the source is still preserved")
+	})
+}
+
+func TestCloneSharesCallbacksOnlyWhenAsked(t *testing.T) {
+	t.Parallel()
+	type commandSet struct {
+		Name     string
+		Handlers map[string]func()
+		Args     []string
+		Groups   map[string][]string
+	}
+	var calls int
+	original := commandSet{
+		Name:     "tool",
+		Handlers: map[string]func(){"run": func() { calls++ }},
+		Args:     []string{"-v"},
+		Groups:   map[string][]string{"build": {"compile", "link"}},
+	}
+
+	_, err := Clone(original)
+	var unsupported *UnsupportedError
+	require.ErrorAs(t, err, &unsupported)
+	assert.Equal(t, `$.Handlers["run"]`, unsupported.Path)
+
+	cloned, err := CloneExcept(original, reflect.TypeFor[func()]())
+	require.NoError(t, err)
+
+	assert.Equal(t, reflect.ValueOf(original.Handlers["run"]).Pointer(), reflect.ValueOf(cloned.Handlers["run"]).Pointer())
+	cloned.Handlers["run"]()
+	assert.Equal(t, 1, calls)
+
+	cloned.Handlers["stop"] = func() {}
+	cloned.Args[0] = "-q"
+	cloned.Groups["build"][0] = "vet"
+	assert.NotContains(t, original.Handlers, "stop")
+	assert.Equal(t, []string{"-v"}, original.Args)
+	assert.Equal(t, []string{"compile", "link"}, original.Groups["build"])
+}
+
+func TestCloneWithForceReflection(t *testing.T) {
+	original := []int{1, 2, 3}
+	force := WithForceReflection()
+
+	cloned, err := CloneWith(original, force)
+
+	require.NoError(t, err)
+	assert.Equal(t, original, cloned)
+	cloned[0] = 9
+	assert.Equal(t, 1, original[0])
+	assert.Equal(t, 42, MustCloneWith(42, force))
+	assert.Equal(t, [3]float64{1, 2, 3}, MustCloneWith([3]float64{1, 2, 3}, force))
+
+	fast := testing.AllocsPerRun(100, func() { _, _ = Clone(original) })
+	reflected := testing.AllocsPerRun(100, func() { _, _ = CloneWith(original, force) })
+	assert.InDelta(t, 1, fast, 0)
+	assert.Greater(t, reflected, fast, "forced clone should take the reflection path")
+}
+
+// tagSet keeps its tags private and exposes them only through JSON.
+type tagSet struct {
+	tags []string
+}
+
+func (s *tagSet) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.tags)
+}
+
+func (s *tagSet) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &s.tags)
+}
+
+func TestCloneWithJSONFallback(t *testing.T) {
+	t.Parallel()
+	type document struct {
+		Title string
+		Tags  tagSet
+		Extra *tagSet
+	}
+	original := document{
+		Title: "report",
+		Tags:  tagSet{tags: []string{"a", "b"}},
+		Extra: &tagSet{tags: []string{"c"}},
+	}
+
+	_, err := Clone(original)
+	var unsupported *UnsupportedError
+	require.ErrorAs(t, err, &unsupported)
+	assert.Equal(t, "$.Tags.tags", unsupported.Path)
+
+	cloned, err := CloneWith(original, WithJSONFallback())
+
+	require.NoError(t, err)
+	assert.Equal(t, original, cloned)
+	assert.NotSame(t, original.Extra, cloned.Extra)
+	cloned.Tags.tags[0] = "changed"
+	cloned.Extra.tags[0] = "changed"
+	assert.Equal(t, []string{"a", "b"}, original.Tags.tags)
+	assert.Equal(t, []string{"c"}, original.Extra.tags)
+
+	elements, err := CloneWith([]tagSet{{tags: []string{"x"}}}, WithJSONFallback())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"x"}, elements[0].tags)
+}
+
+func TestCloneShallowFields(t *testing.T) {
+	t.Parallel()
+	original := &benchNested{
+		ID:       1,
+		Name:     "user",
+		Profile:  &benchProfile{Email: "user@example.com"},
+		Tags:     []string{"a", "b"},
+		Settings: map[string]any{"theme": "dark"},
+	}
+
+	cloned, err := CloneShallowFields(original, "Tags")
+
+	require.NoError(t, err)
+	assert.Equal(t, original, cloned)
+	assert.NotSame(t, original, cloned)
+	assert.Same(t, original.Profile, cloned.Profile)
+	cloned.Settings["theme"] = "light"
+	assert.Equal(t, "light", original.Settings["theme"], "Settings should be shared")
+	cloned.Tags[0] = "changed"
+	assert.Equal(t, []string{"a", "b"}, original.Tags, "Tags should be deep-cloned")
+
+	t.Run("rejects unknown fields", func(t *testing.T) {
+		t.Parallel()
+		_, err := CloneShallowFields(*original, "Missing")
+
+		var unsupported *UnsupportedError
+		require.ErrorAs(t, err, &unsupported)
+		assert.Equal(t, "$.Missing", unsupported.Path)
+	})
+
+	t.Run("rejects non-struct values", func(t *testing.T) {
+		t.Parallel()
+		_, err := CloneShallowFields([]string{"a"})
+
+		var unsupported *UnsupportedError
+		require.ErrorAs(t, err, &unsupported)
+	})
+}
+
+// decimal mimics the layout of shopspring/decimal.Decimal: an unexported
+// big.Int pointer that reflection cannot clone, plus an exponent.
+type decimal struct {
+	value *big.Int
+	exp   int32
+}
+
+func (d decimal) String() string {
+	return fmt.Sprintf("%se%d", d.value, d.exp)
+}
+
+func cloneDecimal(d decimal) (decimal, error) {
+	if d.value == nil {
+		return d, nil
+	}
+	return decimal{value: new(big.Int).Set(d.value), exp: d.exp}, nil
+}
+
+func TestCloneWithCloneFunc(t *testing.T) {
+	t.Parallel()
+	type invoice struct {
+		Total decimal
+		Lines []decimal
+		Tax   *decimal
+		Notes map[string]decimal
+	}
+	original := invoice{
+		Total: decimal{value: big.NewInt(12345), exp: -2},
+		Lines: []decimal{{value: big.NewInt(100), exp: -2}},
+		Tax:   &decimal{value: big.NewInt(7), exp: -1},
+		Notes: map[string]decimal{"fee": {value: big.NewInt(5), exp: 0}},
+	}
+
+	_, err := Clone(original)
+	var unsupported *UnsupportedError
+	require.ErrorAs(t, err, &unsupported)
+	assert.Equal(t, "$.Total.value", unsupported.Path)
+
+	cloned, err := CloneWith(original, WithCloneFunc(cloneDecimal))
+
+	require.NoError(t, err)
+	assert.Equal(t, original.Total.String(), cloned.Total.String())
+	assert.Equal(t, original.Lines[0].String(), cloned.Lines[0].String())
+	assert.Equal(t, original.Tax.String(), cloned.Tax.String())
+	assert.Equal(t, original.Notes["fee"].String(), cloned.Notes["fee"].String())
+	assert.NotSame(t, original.Total.value, cloned.Total.value)
+	assert.NotSame(t, original.Lines[0].value, cloned.Lines[0].value)
+	assert.NotSame(t, original.Tax, cloned.Tax)
+	assert.NotSame(t, original.Tax.value, cloned.Tax.value)
+	assert.NotSame(t, original.Notes["fee"].value, cloned.Notes["fee"].value)
+
+	cloned.Total.value.SetInt64(1)
+	assert.Equal(t, "12345e-2", original.Total.String())
+}
+
+// TestRegisterCloner is not parallel because it registers a global cloner,
+// which turns off the fast paths that other tests count allocations on.
+func TestRegisterCloner(t *testing.T) {
+	RegisterCloner(cloneDecimal)
+	t.Cleanup(func() { RegisterCloner[decimal](nil) })
+	type invoice struct {
+		Total decimal
+		Lines []decimal
+		Tax   *decimal
+	}
+	original := invoice{
+		Total: decimal{value: big.NewInt(12345), exp: -2},
+		Lines: []decimal{{value: big.NewInt(100), exp: -2}},
+		Tax:   &decimal{value: big.NewInt(7), exp: -1},
+	}
+
+	t.Run("every clone uses it", func(t *testing.T) {
+		cloned, err := Clone(original)
+
+		require.NoError(t, err)
+		assert.Equal(t, original.Total.String(), cloned.Total.String())
+		assert.Equal(t, original.Tax.String(), cloned.Tax.String())
+		assert.NotSame(t, original.Total.value, cloned.Total.value)
+		assert.NotSame(t, original.Lines[0].value, cloned.Lines[0].value)
+		assert.NotSame(t, original.Tax.value, cloned.Tax.value)
+	})
+
+	t.Run("clone funcs win", func(t *testing.T) {
+		cloned, err := CloneWith(original.Total, WithCloneFunc(func(d decimal) (decimal, error) {
+			return decimal{value: big.NewInt(0), exp: d.exp}, nil
+		}))
+
+		require.NoError(t, err)
+		assert.Equal(t, "0e-2", cloned.String())
+	})
+
+	t.Run("CloneField and CloneMapSeq", func(t *testing.T) {
+		var f Fields
+		field := CloneField(&f, original.Total)
+		require.NoError(t, f.Err())
+		assert.NotSame(t, original.Total.value, field.value)
+
+		seq, errFn := CloneMapSeq(map[string]decimal{"fee": original.Total})
+		for _, value := range seq {
+			assert.NotSame(t, original.Total.value, value.value)
+		}
+		require.NoError(t, errFn())
+	})
+
+	t.Run("plain types", func(t *testing.T) {
+		type cents int64
+		RegisterCloner(func(c cents) (cents, error) { return c + 1, nil })
+		t.Cleanup(func() { RegisterCloner[cents](nil) })
+
+		assert.Equal(t, cents(2), MustClone(cents(1)))
+		var f Fields
+		assert.Equal(t, cents(2), CloneField(&f, cents(1)))
+		seq, errFn := CloneMapSeq(map[cents]cents{1: 10})
+		for key, value := range seq {
+			assert.Equal(t, cents(2), key)
+			assert.Equal(t, cents(11), value)
+		}
+		require.NoError(t, errFn())
+	})
+
+	RegisterCloner[decimal](nil)
+	_, err := Clone(original)
+	var unsupported *UnsupportedError
+	require.ErrorAs(t, err, &unsupported, "a nil function should remove the registration")
+}
+
+func TestCloneWithCloneFuncTopLevelValues(t *testing.T) {
+	t.Parallel()
+	type celsius int
+	type digest [16]byte
+
+	assert.Equal(t, 2, MustCloneWith(1, WithCloneFunc(func(n int) (int, error) {
+		return n + 1, nil
+	})))
+	assert.Equal(t, celsius(21), MustCloneWith(celsius(20), WithCloneFunc(func(c celsius) (celsius, error) {
+		return c + 1, nil
+	})))
+	assert.Equal(t, [16]byte{1}, MustCloneWith([16]byte{}, WithCloneFunc(func(b [16]byte) ([16]byte, error) {
+		b[0] = 1
+		return b, nil
+	})))
+	assert.Equal(t, digest{1}, MustCloneWith(digest{}, WithCloneFunc(func(d digest) (digest, error) {
+		d[0] = 1
+		return d, nil
+	})))
+}
+
+// money is a plain struct that a clone func rounds, so its calls are visible.
+type money struct {
+	Cents int64
+}
+
+func TestCloneFuncAppliesToSliceElements(t *testing.T) {
+	t.Parallel()
+	newRounding := func() (*atomic.Int32, Option) {
+		var calls atomic.Int32
+		return &calls, WithCloneFunc(func(m money) (money, error) {
+			calls.Add(1)
+			return money{Cents: m.Cents / 100 * 100}, nil
+		})
+	}
+
+	t.Run("slice", func(t *testing.T) {
+		t.Parallel()
+		calls, rounding := newRounding()
+		original := []money{{Cents: 199}, {Cents: 250}, {Cents: 301}}
+
+		cloned, err := CloneWith(original, rounding)
+
+		require.NoError(t, err)
+		assert.Equal(t, int32(3), calls.Load(), "each element should go through the clone func")
+		assert.Equal(t, []money{{Cents: 100}, {Cents: 200}, {Cents: 300}}, cloned)
+		cloned[0].Cents = 0
+		assert.Equal(t, int64(199), original[0].Cents)
+	})
+
+	t.Run("containers", func(t *testing.T) {
+		t.Parallel()
+		type ledger struct {
+			Entries []money
+			Batches [][]money
+			Grid    map[string][2][1]money
+		}
+		calls, rounding := newRounding()
+		original := &ledger{
+			Entries: []money{{Cents: 150}},
+			Batches: [][]money{{{Cents: 250}}, {{Cents: 350}}},
+			Grid:    map[string][2][1]money{"a": {{{Cents: 650}}, {{Cents: 750}}}},
+		}
+
+		cloned, err := CloneWith(original, rounding)
+
+		require.NoError(t, err)
+		assert.Equal(t, int32(5), calls.Load())
+		assert.Equal(t, []money{{Cents: 100}}, cloned.Entries)
+		assert.Equal(t, [][]money{{{Cents: 200}}, {{Cents: 300}}}, cloned.Batches)
+		assert.Equal(t, [2][1]money{{{Cents: 600}}, {{Cents: 700}}}, cloned.Grid["a"])
+	})
+
+	t.Run("array", func(t *testing.T) {
+		t.Parallel()
+		calls, rounding := newRounding()
+
+		cloned, err := CloneWith([2]money{{Cents: 450}, {Cents: 550}}, rounding)
+
+		require.NoError(t, err)
+		assert.Equal(t, int32(2), calls.Load())
+		assert.Equal(t, [2]money{{Cents: 400}, {Cents: 500}}, cloned)
+	})
+
+	t.Run("scalar elements", func(t *testing.T) {
+		t.Parallel()
+		cloned, err := CloneWith([]int{1, 2}, WithCloneFunc(func(n int) (int, error) { return -n, nil }))
+
+		require.NoError(t, err)
+		assert.Equal(t, []int{-1, -2}, cloned)
+	})
+
+	t.Run("preserved backing array", func(t *testing.T) {
+		t.Parallel()
+		calls, rounding := newRounding()
+		all := []money{{Cents: 199}, {Cents: 299}}
+		original := [][]money{all, all[1:]}
+
+		cloned, err := CloneWith(original, rounding, WithPreserveBackingArrays())
+
+		require.NoError(t, err)
+		assert.Equal(t, int32(2), calls.Load())
+		assert.Equal(t, []money{{Cents: 100}, {Cents: 200}}, cloned[0])
+		assert.Same(t, &cloned[0][1], &cloned[1][0])
+	})
+
+	t.Run("plain struct fields are copied by assignment", func(t *testing.T) {
+		t.Parallel()
+		type account struct {
+			Balance money
+			Limits  [2]money
+			History []money
+		}
+		calls, rounding := newRounding()
+		original := []account{{Balance: money{Cents: 199}, Limits: [2]money{{Cents: 299}}, History: []money{{Cents: 399}}}}
+
+		cloned, err := CloneWith(original, rounding)
+
+		require.NoError(t, err)
+		assert.Equal(t, int32(1), calls.Load(), "only the slice field should reach the clone func")
+		assert.Equal(t, int64(199), cloned[0].Balance.Cents)
+		assert.Equal(t, int64(299), cloned[0].Limits[0].Cents)
+		assert.Equal(t, int64(300), cloned[0].History[0].Cents)
+	})
+}
+
+func TestCloneWithRejectOpaqueStructs(t *testing.T) {
+	t.Parallel()
+	type handle struct {
+		conn   *tagSet
+		labels map[string]string
+		id     int
+	}
+	type service struct {
+		Name   string
+		Handle handle
+	}
+	original := service{Name: "api", Handle: handle{id: 1}}
+
+	cloned, err := Clone(original)
+	require.NoError(t, err, "nil references are copied by default")
+	assert.Equal(t, original, cloned)
+
+	_, err = CloneWith(original, WithRejectOpaqueStructs())
+
+	var unsupported *UnsupportedError
+	require.ErrorAs(t, err, &unsupported)
+	assert.Equal(t, "$.Handle", unsupported.Path)
+	assert.Equal(t, reflect.TypeFor[handle](), unsupported.Type)
+	assert.Contains(t, unsupported.Reason, "implement Cloner[T] or use WithCloneFunc")
+
+	cloned, err = CloneWith(original, WithRejectOpaqueStructs(), WithCloneFunc(func(h handle) (handle, error) {
+		return handle{id: h.id}, nil
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, 1, cloned.Handle.id)
+
+	type counter struct {
+		n int
+	}
+	_, err = CloneWith(&counter{n: 1}, WithRejectOpaqueStructs())
+	require.NoError(t, err, "opaque structs without references are plain")
+}
+
+// sealedPalette is Immutable through its pointer and keeps its colors private.
+type sealedPalette struct {
+	colors []string
+}
+
+func (*sealedPalette) Immutable() {}
+
+func TestCloneWithRejectOpaqueStructsExemptions(t *testing.T) {
+	t.Parallel()
+
+	t.Run("JSON fallback", func(t *testing.T) {
+		t.Parallel()
+		type document struct {
+			Tags tagSet
+		}
+
+		cloned, err := CloneWith(document{Tags: tagSet{tags: []string{"a"}}}, WithRejectOpaqueStructs(), WithJSONFallback())
+
+		require.NoError(t, err, "the JSON round trip clones opaque structs")
+		assert.Equal(t, []string{"a"}, cloned.Tags.tags)
+	})
+
+	t.Run("Immutable", func(t *testing.T) {
+		t.Parallel()
+		type theme struct {
+			Palette sealedPalette
+		}
+
+		_, err := CloneWith(theme{}, WithRejectOpaqueStructs())
+
+		require.NoError(t, err)
+	})
+
+	t.Run("value types", func(t *testing.T) {
+		t.Parallel()
+		type event struct {
+			At   time.Time
+			Peer netip.AddrPort
+		}
+		original := event{
+			At:   time.Date(2026, 10, 14, 9, 30, 0, 0, time.FixedZone("CEST", 2*60*60)),
+			Peer: netip.MustParseAddrPort("[fe80::1%eth0]:443"),
+		}
+
+		cloned, err := CloneWith(original, WithRejectOpaqueStructs())
+		require.NoError(t, err)
+		assert.True(t, original.At.Equal(cloned.At))
+		assert.Equal(t, original.At.Location(), cloned.At.Location())
+		assert.Equal(t, original.Peer, cloned.Peer)
+
+		cloned, err = Clone(original)
+		require.NoError(t, err, "a time zone is shared, not rejected as a private reference")
+		assert.Equal(t, original, cloned)
+	})
+}
+
+func TestCloneWithPreserveBackingArrays(t *testing.T) {
+	t.Parallel()
+
+	t.Run("overlapping windows", func(t *testing.T) {
+		t.Parallel()
+		type views struct {
+			Window []int
+			All    []int
+			Tail   []int
+		}
+		all := make([]int, 10)
+		for i := range all {
+			all[i] = i
+		}
+		original := views{Window: all[2:5], All: all, Tail: all[4:6:8]}
+
+		cloned, err := CloneWith(original, WithPreserveBackingArrays())
+
+		require.NoError(t, err)
+		assert.Equal(t, original, cloned)
+		assert.Equal(t, 8, cap(cloned.Window))
+		assert.Equal(t, 4, cap(cloned.Tail))
+		cloned.Window[0] = 20
+		cloned.Tail[0] = 40
+		assert.Equal(t, 20, cloned.All[2], "window should alias the cloned array")
+		assert.Equal(t, 40, cloned.Window[2])
+		assert.Equal(t, 2, original.All[2])
+		assert.Equal(t, 4, original.All[4])
+
+		expanded, err := Clone(original)
+		require.NoError(t, err)
+		expanded.Window[0] = 20
+		assert.Equal(t, 2, expanded.All[2], "windows are independent without the option")
+	})
+
+	t.Run("reference elements", func(t *testing.T) {
+		t.Parallel()
+		shared := &benchSimple{ID: 1}
+		items := []*benchSimple{shared, {ID: 2}, shared}
+		original := map[string][]*benchSimple{"head": items[:2], "rest": items[1:]}
+
+		cloned, err := CloneWith(original, WithPreserveBackingArrays())
+
+		require.NoError(t, err)
+		assert.Same(t, &cloned["head"][1], &cloned["rest"][0])
+		assert.Same(t, cloned["head"][0], cloned["rest"][1])
+		assert.NotSame(t, shared, cloned["head"][0])
+	})
+
+	t.Run("self-referencing window", func(t *testing.T) {
+		t.Parallel()
+		items := make([]any, 3)
+		items[0] = "value"
+		items[1] = items[:1]
+		items[2] = items
+
+		cloned, err := CloneWith(items, WithPreserveBackingArrays())
+
+		require.NoError(t, err)
+		head, ok := cloned[1].([]any)
+		require.True(t, ok)
+		self, ok := cloned[2].([]any)
+		require.True(t, ok)
+		assert.Same(t, &cloned[0], &head[0])
+		assert.Same(t, &cloned[0], &self[0])
+	})
+}
+
+// visitCounter counts its clones, so a structural clone is easy to tell apart.
+type visitCounter struct {
+	Value int
+	Tags  []string
+}
+
+func (c visitCounter) Clone() (visitCounter, error) {
+	return visitCounter{Value: c.Value + 1, Tags: c.Tags}, nil
+}
+
+func TestCloneWithStructuralTypes(t *testing.T) {
+	t.Parallel()
+	type page struct {
+		Counter visitCounter
+		Ref     *visitCounter
+		Items   []visitCounter
+	}
+	original := page{
+		Counter: visitCounter{Value: 1, Tags: []string{"a"}},
+		Ref:     &visitCounter{Value: 2},
+		Items:   []visitCounter{{Value: 3}},
+	}
+
+	counted := MustClone(original)
+	assert.Equal(t, 2, counted.Counter.Value, "Clone method should run by default")
+
+	cloned, err := CloneWith(original, WithStructuralTypes(reflect.TypeFor[visitCounter]()))
+
+	require.NoError(t, err)
+	assert.Equal(t, original, cloned)
+	assert.NotSame(t, original.Ref, cloned.Ref)
+	cloned.Counter.Tags[0] = "changed"
+	assert.Equal(t, []string{"a"}, original.Counter.Tags, "fields should be deep-cloned")
+
+	top, err := CloneWith(visitCounter{Value: 5}, WithStructuralTypes(reflect.TypeFor[visitCounter]()))
+	require.NoError(t, err)
+	assert.Equal(t, 5, top.Value)
+
+	shape, err := CloneWith(&rect{Sides: []int{2, 3}}, WithStructuralTypes(reflect.TypeFor[rect]()))
+	require.NoError(t, err)
+	assert.Zero(t, shape.Clones, "pointer receiver Clone should be ignored")
+	assert.Equal(t, []int{2, 3}, shape.Sides)
+}
+
+// forcedCounter is registered by TestForceStructural.
+type forcedCounter struct {
+	Value int
+	Tags  []string
+}
+
+func (c *forcedCounter) Clone() (*forcedCounter, error) {
+	return &forcedCounter{Value: c.Value + 1, Tags: c.Tags}, nil
+}
+
+func TestForceStructural(t *testing.T) {
+	t.Parallel()
+	// Registration is global, so the test registers its own type.
+	ForceStructural(reflect.TypeFor[forcedCounter]())
+	type page struct {
+		Counter *forcedCounter
+		Items   []*forcedCounter
+	}
+	original := page{
+		Counter: &forcedCounter{Value: 1, Tags: []string{"a"}},
+		Items:   []*forcedCounter{{Value: 2}},
+	}
+
+	cloned, err := Clone(original)
+
+	require.NoError(t, err)
+	assert.Equal(t, original, cloned, "Clone method should be ignored")
+	assert.NotSame(t, original.Counter, cloned.Counter)
+	cloned.Counter.Tags[0] = "changed"
+	assert.Equal(t, []string{"a"}, original.Counter.Tags, "fields should be deep-cloned")
+
+	top, err := Clone(&forcedCounter{Value: 5})
+	require.NoError(t, err)
+	assert.Equal(t, 5, top.Value)
+
+	assert.Panics(t, func() { ForceStructural(nil) })
+}
+
+// cloningCloser is a closer with its own Clone method.
+type cloningCloser struct {
+	Generation int
+}
+
+func (c *cloningCloser) Close() error { return nil }
+
+func (c *cloningCloser) Clone() (*cloningCloser, error) {
+	return &cloningCloser{Generation: c.Generation + 1}, nil
+}
+
+func TestCloneWithShareIOInterfaces(t *testing.T) {
+	t.Parallel()
+	type upload struct {
+		Name    string
+		Body    io.ReadCloser
+		Out     *os.File
+		Closers []io.Closer
+		Tags    []string
+	}
+	newUpload := func() upload {
+		return upload{
+			Name:    "report",
+			Body:    io.NopCloser(strings.NewReader("payload")),
+			Out:     os.Stdout,
+			Closers: []io.Closer{&cloningCloser{}},
+			Tags:    []string{"a"},
+		}
+	}
+
+	_, err := CloneWith(newUpload(), WithHandlePolicy(RejectHandles))
+	var unsupported *UnsupportedError
+	require.ErrorAs(t, err, &unsupported, "files are rejected without the option")
+
+	original := newUpload()
+	cloned, err := CloneWith(original, WithShareIOInterfaces())
+
+	require.NoError(t, err)
+	assert.Same(t, os.Stdout, cloned.Out)
+	head := make([]byte, 3)
+	_, err = cloned.Body.Read(head)
+	require.NoError(t, err)
+	rest, err := io.ReadAll(original.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "load", string(rest), "both should read from one stream")
+
+	assert.Equal(t, 1, cloned.Closers[0].(*cloningCloser).Generation, "Clone methods still run")
+	cloned.Tags[0] = "changed"
+	assert.Equal(t, "a", original.Tags[0])
+
+	body := io.NopCloser(strings.NewReader("payload"))
+	structural, err := Clone(upload{Body: body})
+	require.NoError(t, err)
+	_, err = io.ReadAll(structural.Body)
+	require.NoError(t, err)
+	rest, err = io.ReadAll(body)
+	require.NoError(t, err)
+	assert.Equal(t, "payload", string(rest), "streams are copied without the option")
+}
+
+// recordingCloner records the order its Clone method is called in.
+type recordingCloner struct {
+	Name  string
+	calls *[]string
+}
+
+func (r recordingCloner) Clone() (recordingCloner, error) {
+	*r.calls = append(*r.calls, r.Name)
+	return r, nil
+}
+
+func TestCloneWithDeterministicOrder(t *testing.T) {
+	t.Parallel()
+	var calls []string
+	byName := make(map[string]recordingCloner)
+	byID := make(map[int]any)
+	for i := range 20 {
+		name := fmt.Sprintf("n%02d", i)
+		byName[name] = recordingCloner{Name: name, calls: &calls}
+		byID[-i] = recordingCloner{Name: fmt.Sprint(-i), calls: &calls}
+	}
+	names := slices.Sorted(maps.Keys(byName))
+	ids := make([]string, 0, len(byID))
+	for i := -19; i <= 0; i++ {
+		ids = append(ids, fmt.Sprint(i))
+	}
+
+	for range 5 {
+		calls = nil
+		_, err := CloneWith(byName, WithDeterministicOrder())
+		require.NoError(t, err)
+		assert.Equal(t, names, calls)
+
+		calls = nil
+		_, err = CloneWith(map[string]any{"ids": byID}, WithDeterministicOrder())
+		require.NoError(t, err)
+		assert.Equal(t, ids, calls, "nested maps should be ordered too")
+	}
+
+	calls = nil
+	cloned, err := CloneWith(map[float64]recordingCloner{
+		math.NaN(): {Name: "nan", calls: &calls},
+		1:          {Name: "one", calls: &calls},
+		-1:         {Name: "minus", calls: &calls},
+	}, WithDeterministicOrder())
+	require.NoError(t, err)
+	assert.Len(t, cloned, 3, "NaN keys should be kept")
+	assert.Equal(t, []string{"nan", "minus", "one"}, calls)
+}
+
+func TestCloneWithNormalizeEmpty(t *testing.T) {
+	t.Parallel()
+	type document struct {
+		IDs    []int
+		Labels map[string]int
+		Rows   [][]int
+		Extra  any
+	}
+
+	t.Run("empty to nil", func(t *testing.T) {
+		t.Parallel()
+		ids, err := CloneWith([]int{}, WithNormalizeEmpty(true))
+		require.NoError(t, err)
+		assert.Nil(t, ids)
+
+		labels, err := CloneWith(map[string]int{}, WithNormalizeEmpty(true))
+		require.NoError(t, err)
+		assert.Nil(t, labels)
+
+		spare, err := CloneWith(make([]int, 0, 8), WithNormalizeEmpty(true))
+		require.NoError(t, err)
+		assert.Nil(t, spare, "spare capacity does not make a slice non-empty")
+
+		cloned, err := CloneWith(&document{
+			IDs:    []int{},
+			Labels: map[string]int{},
+			Rows:   [][]int{{}, {1}},
+			Extra:  map[string]any{"tags": []any{}},
+		}, WithNormalizeEmpty(true))
+		require.NoError(t, err)
+		assert.Nil(t, cloned.IDs)
+		assert.Nil(t, cloned.Labels)
+		assert.Equal(t, [][]int{nil, {1}}, cloned.Rows)
+		assert.Equal(t, map[string]any{"tags": []any(nil)}, cloned.Extra)
+	})
+
+	t.Run("nil to empty", func(t *testing.T) {
+		t.Parallel()
+		ids, err := CloneWith([]int(nil), WithNormalizeEmpty(false))
+		require.NoError(t, err)
+		assert.NotNil(t, ids)
+		assert.Empty(t, ids)
+
+		labels, err := CloneWith(map[string]int(nil), WithNormalizeEmpty(false))
+		require.NoError(t, err)
+		assert.NotNil(t, labels)
+		assert.Empty(t, labels)
+
+		cloned, err := CloneWith(&document{Rows: [][]int{nil}, Extra: []int(nil)}, WithNormalizeEmpty(false))
+		require.NoError(t, err)
+		assert.Equal(t, []int{}, cloned.IDs)
+		assert.Equal(t, map[string]int{}, cloned.Labels)
+		assert.Equal(t, [][]int{{}}, cloned.Rows)
+		assert.Equal(t, []int{}, cloned.Extra)
+	})
+
+	t.Run("default keeps nil-ness", func(t *testing.T) {
+		t.Parallel()
+		cloned := MustClone(&document{IDs: []int{}, Rows: [][]int{nil, {}}})
+		assert.NotNil(t, cloned.IDs)
+		assert.Nil(t, cloned.Labels)
+		assert.Nil(t, cloned.Rows[0])
+		assert.NotNil(t, cloned.Rows[1])
+	})
+}
+
+func TestCloneWithUnsupportedHook(t *testing.T) {
+	t.Parallel()
+	type worker struct {
+		Name    string
+		Jobs    chan int
+		Run     func()
+		Workers []func()
+		done    chan struct{}
+	}
+	original := worker{
+		Name:    "w",
+		Jobs:    make(chan int),
+		Run:     func() {},
+		Workers: []func(){func() {}},
+		done:    make(chan struct{}),
+	}
+	type report struct {
+		path string
+		kind reflect.Kind
+	}
+	var reports []report
+
+	cloned, err := CloneWith(original, WithUnsupportedHook(func(path string, kind reflect.Kind) {
+		reports = append(reports, report{path: path, kind: kind})
+	}))
+
+	require.NoError(t, err)
+	assert.Equal(t, []report{
+		{path: "$.Jobs", kind: reflect.Chan},
+		{path: "$.Run", kind: reflect.Func},
+		{path: "$.Workers[0]", kind: reflect.Func},
+		{path: "$.done", kind: reflect.Chan},
+	}, reports)
+	assert.Equal(t, "w", cloned.Name)
+	assert.Nil(t, cloned.Jobs)
+	assert.Nil(t, cloned.Run)
+	assert.Nil(t, cloned.Workers[0])
+	assert.Equal(t, original.done, cloned.done, "unexported fields keep the source value")
+
+	_, err = CloneWith(struct{ Mu *sync.Mutex }{Mu: &sync.Mutex{}}, WithUnsupportedHook(func(string, reflect.Kind) {}))
+	var unsupported *UnsupportedError
+	require.ErrorAs(t, err, &unsupported, "sync primitives are still rejected")
+}
+
+func TestCloneWithRecover(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Clone method panic", func(t *testing.T) {
+		t.Parallel()
+		type holder struct {
+			Name string
+			Item panicCloner
+		}
+
+		cloned, err := CloneWith(holder{Name: "h"}, WithRecover())
+
+		var panicked *PanicError
+		require.ErrorAs(t, err, &panicked)
+		assert.Equal(t, "$.Item", panicked.Path)
+		assert.Equal(t, reflect.TypeFor[panicCloner](), panicked.Type)
+		assert.Equal(t, errPanicCloner, panicked.Value)
+		require.ErrorIs(t, err, errPanicCloner, "an error panic value should unwrap")
+		assert.Zero(t, cloned)
+	})
+
+	t.Run("top-level Cloner", func(t *testing.T) {
+		t.Parallel()
+		_, err := CloneWith(panicCloner{}, WithRecover())
+
+		var panicked *PanicError
+		require.ErrorAs(t, err, &panicked)
+		assert.Equal(t, "$", panicked.Path)
+	})
+
+	t.Run("clone func panic", func(t *testing.T) {
+		t.Parallel()
+		type tagged struct {
+			Tags []string
+		}
+		_, err := CloneWith([]tagged{{Tags: []string{"a"}}}, WithRecover(), WithCloneFunc(func(tagged) (tagged, error) {
+			panic("boom")
+		}))
+
+		var panicked *PanicError
+		require.ErrorAs(t, err, &panicked)
+		assert.Equal(t, "$[0]", panicked.Path)
+		assert.Equal(t, "boom", panicked.Value)
+		assert.NoError(t, errors.Unwrap(err))
+		assert.Equal(t, "deepclone: panic at $[0] (deepclone.tagged): boom", err.Error())
+	})
+
+	t.Run("transform panic", func(t *testing.T) {
+		t.Parallel()
+		RegisterTransform("test.recover.panic", func(reflect.Value) reflect.Value {
+			panic("transform failed")
+		})
+		type user struct {
+			Email string `clone:"transform=test.recover.panic"`
+		}
+
+		_, err := CloneWith(user{Email: "a@example.com"}, WithRecover())
+
+		var panicked *PanicError
+		require.ErrorAs(t, err, &panicked)
+		assert.Equal(t, "$.Email", panicked.Path)
+	})
+
+	t.Run("panics propagate by default", func(t *testing.T) {
+		t.Parallel()
+		assert.PanicsWithError(t, errPanicCloner.Error(), func() {
+			_, _ = CloneWith([]panicCloner{{}})
+		})
+	})
+}
+
+// fakeHandle is an OS handle that is not an *os.File.
+type fakeHandle struct {
+	fd uintptr
+}
+
+func (h *fakeHandle) Fd() uintptr { return h.fd }
+
+func TestCloneWithHandlePolicy(t *testing.T) {
+	t.Parallel()
+	type logger struct {
+		Name   string
+		Out    *os.File
+		Extra  []*fakeHandle
+		Tags   []string
+		output *os.File
+	}
+	file, err := os.CreateTemp(t.TempDir(), "deepclone-*")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, file.Close())
+	})
+	handle := &fakeHandle{fd: 7}
+	newLogger := func() logger {
+		return logger{Name: "app", Out: file, Extra: []*fakeHandle{handle}, Tags: []string{"a"}, output: file}
+	}
+
+	t.Run("share by default", func(t *testing.T) {
+		t.Parallel()
+		original := newLogger()
+
+		cloned, disjoint, err := CloneDisjoint(original)
+
+		require.NoError(t, err)
+		assert.False(t, disjoint, "shared handles should be reported")
+		assert.Same(t, file, cloned.Out)
+		assert.Same(t, handle, cloned.Extra[0])
+		assert.Same(t, file, cloned.output)
+		cloned.Tags[0] = "changed"
+		assert.Equal(t, "a", original.Tags[0])
+	})
+
+	t.Run("zero", func(t *testing.T) {
+		t.Parallel()
+		cloned, err := CloneWith(newLogger(), WithHandlePolicy(ZeroHandles))
+
+		require.NoError(t, err)
+		assert.Nil(t, cloned.Out)
+		assert.Equal(t, []*fakeHandle{nil}, cloned.Extra)
+		assert.Same(t, file, cloned.output, "unexported fields keep the source handle")
+		assert.Equal(t, "app", cloned.Name)
+	})
+
+	t.Run("reject", func(t *testing.T) {
+		t.Parallel()
+		_, err := CloneWith(newLogger(), WithHandlePolicy(RejectHandles))
+
+		var unsupported *UnsupportedError
+		require.ErrorAs(t, err, &unsupported)
+		assert.Equal(t, "$.Out", unsupported.Path)
+		assert.Equal(t, "files cannot be cloned", unsupported.Reason)
+
+		_, err = CloneWith([]*fakeHandle{handle}, WithHandlePolicy(RejectHandles))
+		require.ErrorAs(t, err, &unsupported)
+		assert.Equal(t, "$[0]", unsupported.Path)
+
+		cloned, err := CloneWith(logger{Name: "empty"}, WithHandlePolicy(RejectHandles))
+		require.NoError(t, err, "nil handles are fine")
+		assert.Equal(t, "empty", cloned.Name)
+	})
+
+	t.Run("clone funcs win", func(t *testing.T) {
+		t.Parallel()
+		for _, policy := range []HandlePolicy{ShareHandles, ZeroHandles, RejectHandles} {
+			cloned, err := CloneWith(newLogger(), WithHandlePolicy(policy), WithCloneFunc(func(h *fakeHandle) (*fakeHandle, error) {
+				return &fakeHandle{fd: h.fd + 1}, nil
+			}))
+			if policy == RejectHandles {
+				require.Error(t, err, "the *os.File field is still rejected")
+				continue
+			}
+			require.NoError(t, err)
+			assert.Equal(t, uintptr(8), cloned.Extra[0].fd)
+		}
+	})
+}
+
+func TestCloneWithBufferPolicy(t *testing.T) {
+	t.Parallel()
+	type pipe struct {
+		Name    string
+		In      *bufio.Reader
+		Out     *bufio.Writer
+		Readers []*bufio.Reader
+	}
+
+	t.Run("reject by default", func(t *testing.T) {
+		t.Parallel()
+		_, err := Clone(pipe{In: bufio.NewReader(strings.NewReader("data"))})
+
+		var unsupported *UnsupportedError
+		require.ErrorAs(t, err, &unsupported)
+		assert.Equal(t, "$.In", unsupported.Path)
+	})
+
+	t.Run("rewrap", func(t *testing.T) {
+		t.Parallel()
+		var sink bytes.Buffer
+		in := bufio.NewReaderSize(strings.NewReader("first line\nsecond line\n"), 64)
+		out := bufio.NewWriterSize(&sink, 32)
+		original := pipe{Name: "p", In: in, Out: out, Readers: []*bufio.Reader{in}}
+
+		cloned, err := CloneWith(original, WithBufferPolicy(RewrapBuffers))
+		require.NoError(t, err)
+
+		require.NotSame(t, in, cloned.In)
+		require.NotSame(t, out, cloned.Out)
+		assert.Equal(t, 64, cloned.In.Size())
+		assert.Equal(t, 32, cloned.Out.Size())
+		assert.Zero(t, cloned.In.Buffered(), "the clone starts with an empty buffer")
+		assert.NotSame(t, in, cloned.Readers[0])
+
+		line, err := cloned.In.ReadString('\n')
+		require.NoError(t, err)
+		assert.Equal(t, "first line\n", line)
+		_, err = in.ReadByte()
+		assert.ErrorIs(t, err, io.EOF, "the clone and the source read one stream, which the clone buffered")
+		line, err = cloned.In.ReadString('\n')
+		require.NoError(t, err)
+		assert.Equal(t, "second line\n", line)
+
+		_, err = cloned.Out.WriteString("from clone")
+		require.NoError(t, err)
+		assert.Zero(t, out.Buffered(), "the clone has its own buffer")
+		require.NoError(t, cloned.Out.Flush())
+		require.NoError(t, out.Flush())
+		assert.Equal(t, "from clone", sink.String())
+	})
+
+	t.Run("zero", func(t *testing.T) {
+		t.Parallel()
+		original := pipe{Name: "p", In: bufio.NewReader(strings.NewReader("data")), Out: bufio.NewWriter(io.Discard)}
+		original.Readers = []*bufio.Reader{original.In}
+
+		cloned, err := CloneWith(original, WithBufferPolicy(ZeroBuffers))
+
+		require.NoError(t, err)
+		assert.Equal(t, pipe{Name: "p", Readers: []*bufio.Reader{nil}}, cloned)
+	})
+
+	t.Run("clone funcs win", func(t *testing.T) {
+		t.Parallel()
+		replacement := bufio.NewReader(strings.NewReader("other"))
+		cloned, err := CloneWith(pipe{In: bufio.NewReader(strings.NewReader("data"))},
+			WithCloneFunc(func(*bufio.Reader) (*bufio.Reader, error) { return replacement, nil }),
+			WithBufferPolicy(RewrapBuffers))
+
+		require.NoError(t, err)
+		assert.Same(t, replacement, cloned.In)
+	})
+
+	t.Run("values are rejected", func(t *testing.T) {
+		t.Parallel()
+		_, err := CloneWith(struct{ In bufio.Reader }{}, WithBufferPolicy(RewrapBuffers))
+
+		var unsupported *UnsupportedError
+		require.ErrorAs(t, err, &unsupported)
+		assert.Equal(t, "$.In", unsupported.Path)
+	})
+}
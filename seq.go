@@ -0,0 +1,59 @@
+package deepclone
+
+import (
+	"iter"
+	"reflect"
+)
+
+// CloneMapSeq returns a sequence that yields a deep copy of each entry of m,
+// cloning an entry only when the sequence reaches it, and a function that
+// reports the error that stopped the sequence, if any.
+//
+// Callers streaming a large map into another structure hold one cloned entry
+// at a time rather than a second copy of the whole map. Each entry is cloned
+// on its own, so cycles and shared references within a key and value are
+// preserved, but a reference shared between entries is copied once per entry.
+// Entries are yielded in map iteration order. On error the sequence stops
+// before yielding the failed entry; check the error after ranging over the
+// sequence. Each range over the sequence clones m afresh and resets the error.
+func CloneMapSeq[K comparable, V any](m map[K]V) (iter.Seq2[K, V], func() error) {
+	var err error
+	seq := func(yield func(K, V) bool) {
+		err = nil
+		ctx := newCloneContext(newOptions(nil))
+		plainKeys := ctx.copiesPlain(reflect.TypeFor[K]())
+		plainValues := ctx.copiesPlain(reflect.TypeFor[V]())
+		for key, value := range m {
+			if !plainKeys || !plainValues {
+				clear(ctx.visited)
+				key, value, err = cloneMapSeqEntry(ctx, key, value, plainKeys, plainValues)
+				if err != nil {
+					return
+				}
+			}
+			if !yield(key, value) {
+				return
+			}
+		}
+	}
+	return seq, func() error { return err }
+}
+
+// cloneMapSeqEntry clones one entry for CloneMapSeq, copying plain keys and
+// values by assignment.
+func cloneMapSeqEntry[K comparable, V any](ctx *cloneContext, key K, value V, plainKeys, plainValues bool) (K, V, error) {
+	var clonedKey K
+	var clonedValue V
+	srcKey := reflect.ValueOf(&key).Elem()
+	if plainKeys {
+		clonedKey = key
+	} else if err := ctx.cloneElementInto(srcKey, reflect.ValueOf(&clonedKey).Elem(), mapKeyPath("$", srcKey)); err != nil {
+		return clonedKey, clonedValue, err
+	}
+	if plainValues {
+		clonedValue = value
+	} else if err := ctx.cloneElementInto(reflect.ValueOf(&value).Elem(), reflect.ValueOf(&clonedValue).Elem(), mapValuePath("$", srcKey)); err != nil {
+		return clonedKey, clonedValue, err
+	}
+	return clonedKey, clonedValue, nil
+}
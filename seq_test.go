@@ -0,0 +1,130 @@
+package deepclone
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// seqNode is a map value that can refer to itself.
+type seqNode struct {
+	Name  string
+	Items []int
+	Self  *seqNode
+}
+
+func TestCloneMapSeq(t *testing.T) {
+	t.Parallel()
+
+	t.Run("entries are cloned independently", func(t *testing.T) {
+		t.Parallel()
+		original := map[string]*seqNode{
+			"a": {Name: "a", Items: []int{1}},
+			"b": {Name: "b", Items: []int{2}},
+		}
+		for _, node := range original {
+			node.Self = node
+		}
+
+		seq, errFn := CloneMapSeq(original)
+		cloned := make(map[string]*seqNode)
+		for key, node := range seq {
+			cloned[key] = node
+		}
+
+		require.NoError(t, errFn())
+		require.Len(t, cloned, 2)
+		for key, node := range cloned {
+			assert.NotSame(t, original[key], node)
+			assert.Equal(t, key, node.Name)
+			assert.Same(t, node, node.Self, "a cycle within a value should point at its clone")
+			node.Items[0] = 100
+		}
+		assert.Equal(t, []int{1}, original["a"].Items)
+		assert.Equal(t, []int{2}, original["b"].Items)
+	})
+
+	t.Run("references shared between entries are copied per entry", func(t *testing.T) {
+		t.Parallel()
+		shared := &seqNode{Name: "shared"}
+		original := map[int]*seqNode{1: shared, 2: shared}
+
+		seq, errFn := CloneMapSeq(original)
+		var nodes []*seqNode
+		for _, node := range seq {
+			nodes = append(nodes, node)
+		}
+
+		require.NoError(t, errFn())
+		require.Len(t, nodes, 2)
+		assert.NotSame(t, shared, nodes[0])
+		assert.NotSame(t, nodes[0], nodes[1])
+	})
+
+	t.Run("pointer keys are cloned", func(t *testing.T) {
+		t.Parallel()
+		key := &seqNode{Name: "key"}
+		original := map[*seqNode]*seqNode{key: key}
+
+		seq, errFn := CloneMapSeq(original)
+		for clonedKey, clonedValue := range seq {
+			assert.NotSame(t, key, clonedKey)
+			assert.Same(t, clonedKey, clonedValue, "a key and its value should share a clone graph")
+		}
+		require.NoError(t, errFn())
+	})
+
+	t.Run("break stops cloning", func(t *testing.T) {
+		t.Parallel()
+		original := map[int][]int{1: {1}, 2: {2}, 3: {3}}
+
+		seq, errFn := CloneMapSeq(original)
+		count := 0
+		for range seq {
+			count++
+			break
+		}
+
+		require.NoError(t, errFn())
+		assert.Equal(t, 1, count)
+	})
+
+	t.Run("plain entries", func(t *testing.T) {
+		t.Parallel()
+		original := map[string]int{"a": 1, "b": 2}
+
+		seq, errFn := CloneMapSeq(original)
+		cloned := make(map[string]int)
+		for key, value := range seq {
+			cloned[key] = value
+		}
+
+		require.NoError(t, errFn())
+		assert.Equal(t, original, cloned)
+	})
+
+	t.Run("nil map", func(t *testing.T) {
+		t.Parallel()
+		seq, errFn := CloneMapSeq[string, []int](nil)
+		for range seq {
+			t.Fatal("a nil map should yield nothing")
+		}
+		require.NoError(t, errFn())
+	})
+
+	t.Run("unsupported value stops the sequence", func(t *testing.T) {
+		t.Parallel()
+		original := map[string]any{"ch": make(chan int)}
+
+		seq, errFn := CloneMapSeq(original)
+		for range seq {
+			t.Fatal("the failed entry should not be yielded")
+		}
+
+		var unsupported *UnsupportedError
+		require.True(t, errors.As(errFn(), &unsupported))
+		assert.Equal(t, `$["ch"]`, unsupported.Path)
+	})
+}
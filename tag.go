@@ -0,0 +1,84 @@
+package deepclone
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// tagKey is the struct tag key that controls how a field is cloned.
+const tagKey = "clone"
+
+// transformOption prefixes the tag option that names a field transform.
+const transformOption = "transform="
+
+var (
+	transformsMutex sync.RWMutex
+	transforms      = make(map[string]func(reflect.Value) reflect.Value)
+)
+
+// RegisterTransform registers fn under name for struct fields tagged
+// clone:"transform=name". Once a tagged field has been cloned according to its
+// other options, fn receives the field value of the clone and returns the value
+// to store instead, which suits redacting secrets or normalizing strings.
+//
+// The result must be assignable to the field type. Registering a name again
+// replaces its transform, and a nil fn removes it. Transforms are global, so
+// register them during program initialization.
+func RegisterTransform(name string, fn func(reflect.Value) reflect.Value) {
+	transformsMutex.Lock()
+	defer transformsMutex.Unlock()
+	if fn == nil {
+		delete(transforms, name)
+		return
+	}
+	transforms[name] = fn
+}
+
+func lookupTransform(name string) (func(reflect.Value) reflect.Value, bool) {
+	transformsMutex.RLock()
+	defer transformsMutex.RUnlock()
+	fn, ok := transforms[name]
+	return fn, ok
+}
+
+// fieldTagTransform returns the transform named by a clone struct tag, or ""
+// when the tag names none. The first transform option wins.
+func fieldTagTransform(tag string) string {
+	if tag == "-" {
+		return ""
+	}
+	for option := range strings.SplitSeq(tag, ",") {
+		if name, ok := strings.CutPrefix(strings.TrimSpace(option), transformOption); ok {
+			return name
+		}
+	}
+	return ""
+}
+
+// fieldTagAction returns the action requested by a clone struct tag.
+//
+// A tag is a comma-separated list of options. The whole tag "-" zeroes the
+// field. "zero" also zeroes the field and wins over "shallow", which shares the
+// source value without cloning it. "share" is an alias for "shallow" that reads
+// better on func and channel fields. Transform options are read by
+// fieldTagTransform. Empty and unknown options are ignored so tags stay
+// forward-compatible.
+func fieldTagAction(tag string) (fieldAction, bool) {
+	if tag == "-" {
+		return zeroField, true
+	}
+
+	action, found := copyField, false
+	for option := range strings.SplitSeq(tag, ",") {
+		switch strings.TrimSpace(option) {
+		case "shallow", "share":
+			if !found {
+				action, found = shareField, true
+			}
+		case "zero":
+			action, found = zeroField, true
+		}
+	}
+	return action, found
+}
@@ -0,0 +1,452 @@
+package deepclone
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFieldTagAction(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		tag    string
+		action fieldAction
+		found  bool
+	}{
+		{"empty", "", copyField, false},
+		{"skip", "-", zeroField, true},
+		{"shallow", "shallow", shareField, true},
+		{"zero", "zero", zeroField, true},
+		{"shallow with unknown option", "shallow,omitempty", shareField, true},
+		{"unknown before shallow", "omitempty,shallow", shareField, true},
+		{"share alias", "share", shareField, true},
+		{"zero wins over share", "share,zero", zeroField, true},
+		{"zero wins over shallow", "shallow,zero", zeroField, true},
+		{"zero wins regardless of order", "zero,shallow", zeroField, true},
+		{"spaces around options", " shallow , omitempty ", shareField, true},
+		{"empty options", ",,shallow,,", shareField, true},
+		{"trailing comma", "zero,", zeroField, true},
+		{"only commas", ",,,", copyField, false},
+		{"dash with options", "-,shallow", shareField, true},
+		{"options are case-sensitive", "SHALLOW", copyField, false},
+		{"unknown only", "deep,transform=upper", copyField, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			action, found := fieldTagAction(tt.tag)
+			assert.Equal(t, tt.action, action)
+			assert.Equal(t, tt.found, found)
+		})
+	}
+}
+
+func TestFieldTagTransform(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		tag  string
+		want string
+	}{
+		{"empty", "", ""},
+		{"transform", "transform=upper", "upper"},
+		{"with other options", "shallow, transform=redact ,omitempty", "redact"},
+		{"first wins", "transform=a,transform=b", "a"},
+		{"skip", "-", ""},
+		{"empty name", "transform=", ""},
+		{"other options only", "shallow,zero", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, fieldTagTransform(tt.tag))
+		})
+	}
+}
+
+func TestCloneTransformTags(t *testing.T) {
+	t.Parallel()
+	// Transform names are global, so each test registers its own.
+	RegisterTransform("test.redact", func(reflect.Value) reflect.Value {
+		return reflect.ValueOf("[redacted]")
+	})
+	RegisterTransform("test.upper", func(v reflect.Value) reflect.Value {
+		return reflect.ValueOf(strings.ToUpper(v.String()))
+	})
+	RegisterTransform("test.first", func(v reflect.Value) reflect.Value {
+		return v.Slice(0, min(v.Len(), 1))
+	})
+
+	t.Run("tagged fields are transformed", func(t *testing.T) {
+		t.Parallel()
+		type credentials struct {
+			User     string
+			Password string   `clone:"transform=test.redact"`
+			Region   string   `clone:"transform=test.upper"`
+			Scopes   []string `clone:"transform=test.first"`
+			Tags     []string
+		}
+		original := []credentials{{
+			User:     "alice",
+			Password: "hunter2",
+			Region:   "eu-west",
+			Scopes:   []string{"read", "write"},
+			Tags:     []string{"ops"},
+		}}
+
+		cloned, err := Clone(original)
+
+		require.NoError(t, err)
+		assert.Equal(t, []credentials{{
+			User:     "alice",
+			Password: "[redacted]",
+			Region:   "EU-WEST",
+			Scopes:   []string{"read"},
+			Tags:     []string{"ops"},
+		}}, cloned)
+		assert.Equal(t, "hunter2", original[0].Password)
+		cloned[0].Scopes[0] = "admin"
+		assert.Equal(t, "read", original[0].Scopes[0], "transforms receive the cloned value")
+	})
+
+	t.Run("unregistered transform", func(t *testing.T) {
+		t.Parallel()
+		type secret struct {
+			Value string `clone:"transform=test.missing"`
+		}
+
+		_, err := Clone(&secret{Value: "x"})
+
+		var unsupported *UnsupportedError
+		require.ErrorAs(t, err, &unsupported)
+		assert.Equal(t, "$.Value", unsupported.Path)
+		assert.Equal(t, `no transform registered as "test.missing"`, unsupported.Reason)
+	})
+
+	t.Run("result must fit the field", func(t *testing.T) {
+		t.Parallel()
+		type counter struct {
+			Count int `clone:"transform=test.redact"`
+		}
+
+		_, err := Clone(counter{Count: 1})
+
+		var unsupported *UnsupportedError
+		require.ErrorAs(t, err, &unsupported)
+		assert.Equal(t, "transform result is not assignable to the field type", unsupported.Reason)
+	})
+
+	t.Run("unexported fields are rejected", func(t *testing.T) {
+		t.Parallel()
+		type secret struct {
+			value string `clone:"transform=test.redact"`
+		}
+
+		_, err := Clone(secret{value: "x"})
+
+		var unsupported *UnsupportedError
+		require.ErrorAs(t, err, &unsupported)
+		assert.Equal(t, "$.value", unsupported.Path)
+		assert.Equal(t, "unexported fields cannot be transformed", unsupported.Reason)
+	})
+}
+
+func TestCloneStructTags(t *testing.T) {
+	t.Parallel()
+
+	t.Run("shallow shares references", func(t *testing.T) {
+		t.Parallel()
+		type config struct {
+			Lookup []string          `clone:"shallow"`
+			Labels map[string]string `clone:"shallow,omitempty"`
+			Tags   []string
+		}
+
+		original := config{
+			Lookup: []string{"a", "b"},
+			Labels: map[string]string{"env": "prod"},
+			Tags:   []string{"x"},
+		}
+		cloned := MustClone(original)
+
+		assert.Same(t, &original.Lookup[0], &cloned.Lookup[0])
+		cloned.Labels["env"] = "dev"
+		assert.Equal(t, "dev", original.Labels["env"])
+		assert.NotSame(t, &original.Tags[0], &cloned.Tags[0])
+	})
+
+	t.Run("shallow allows shared runtime references", func(t *testing.T) {
+		t.Parallel()
+		type worker struct {
+			Name   string
+			Events chan int    `clone:"shallow"`
+			Mu     *sync.Mutex `clone:"shallow"`
+		}
+
+		original := worker{Name: "main", Events: make(chan int), Mu: &sync.Mutex{}}
+		cloned := MustClone(original)
+
+		assert.Equal(t, original.Events, cloned.Events)
+		assert.Same(t, original.Mu, cloned.Mu)
+	})
+
+	t.Run("share marks func fields as deliberately shared", func(t *testing.T) {
+		t.Parallel()
+		type tagged struct {
+			Name     string
+			OnChange func(string) `clone:"share"`
+		}
+		type untagged struct {
+			Name     string
+			OnChange func(string)
+		}
+		var got string
+		onChange := func(name string) { got = name }
+
+		cloned, err := Clone(tagged{Name: "a", OnChange: onChange})
+		require.NoError(t, err)
+		cloned.OnChange("called")
+		assert.Equal(t, "called", got)
+
+		_, err = Clone(untagged{Name: "a", OnChange: onChange})
+		var unsupported *UnsupportedError
+		require.ErrorAs(t, err, &unsupported)
+		assert.Equal(t, "$.OnChange", unsupported.Path)
+	})
+
+	t.Run("shallow rejects sync primitives held by value", func(t *testing.T) {
+		t.Parallel()
+		type guarded struct {
+			Mu sync.Mutex `clone:"shallow"`
+		}
+
+		_, err := Clone(guarded{})
+
+		var unsupported *UnsupportedError
+		require.ErrorAs(t, err, &unsupported)
+		assert.Equal(t, "$.Mu", unsupported.Path)
+		assert.Equal(t, "sync primitives cannot be cloned", unsupported.Reason)
+	})
+
+	t.Run("shallow allows unexported references", func(t *testing.T) {
+		t.Parallel()
+		type cache struct {
+			Name    string
+			entries map[string]int `clone:"shallow"`
+		}
+
+		original := cache{Name: "c", entries: map[string]int{"a": 1}}
+		cloned := MustClone(original)
+
+		assert.Equal(t, "c", cloned.Name)
+		assert.Equal(t, 1, cloned.entries["a"])
+		cloned.entries["b"] = 2
+		assert.Equal(t, 2, original.entries["b"])
+	})
+
+	t.Run("skip and zero clear fields", func(t *testing.T) {
+		t.Parallel()
+		type session struct {
+			User     string
+			Token    []byte         `clone:"-"`
+			Secret   string         `clone:"zero"`
+			Callback func()         `clone:"zero,shallow"`
+			Scratch  map[string]int `clone:"-"`
+		}
+
+		original := &session{
+			User:     "alice",
+			Token:    []byte("token"),
+			Secret:   "secret",
+			Callback: func() {},
+			Scratch:  map[string]int{"a": 1},
+		}
+		cloned := MustClone(original)
+
+		assert.Equal(t, "alice", cloned.User)
+		assert.Nil(t, cloned.Token)
+		assert.Empty(t, cloned.Secret)
+		assert.Nil(t, cloned.Callback)
+		assert.Nil(t, cloned.Scratch)
+		assert.Equal(t, "secret", original.Secret)
+	})
+
+	t.Run("zero resets sync primitives", func(t *testing.T) {
+		t.Parallel()
+		type pooled struct {
+			Name string
+			Pool sync.Pool  `clone:"zero"`
+			Mu   sync.Mutex `clone:"zero"`
+		}
+
+		original := &pooled{Name: "buffers"}
+		original.Pool.New = func() any { return "fresh" }
+		original.Pool.Put("cached")
+		original.Mu.Lock()
+		defer original.Mu.Unlock()
+
+		cloned := MustClone(original)
+
+		assert.Equal(t, "buffers", cloned.Name)
+		assert.Nil(t, cloned.Pool.New)
+		assert.Nil(t, cloned.Pool.Get(), "cloned pool should start empty")
+		cloned.Pool.Put("reused")
+		assert.True(t, cloned.Mu.TryLock(), "cloned mutex should start unlocked")
+		cloned.Mu.Unlock()
+	})
+
+	t.Run("sync primitives without a tag are rejected", func(t *testing.T) {
+		t.Parallel()
+		type guarded struct {
+			Mu sync.Mutex
+		}
+
+		_, err := Clone(&guarded{})
+
+		var unsupported *UnsupportedError
+		require.ErrorAs(t, err, &unsupported)
+		assert.Equal(t, "$.Mu", unsupported.Path)
+		assert.Equal(t, "sync primitives cannot be cloned", unsupported.Reason)
+	})
+
+	t.Run("pools without a tag start empty", func(t *testing.T) {
+		t.Parallel()
+		type pooled struct {
+			Name  string
+			Pool  sync.Pool
+			Pools []sync.Pool
+		}
+
+		original := &pooled{Name: "buffers", Pools: make([]sync.Pool, 1)}
+		original.Pool.New = func() any { return "fresh" }
+		original.Pool.Put("cached")
+		original.Pools[0].Put("cached")
+
+		cloned, err := Clone(original)
+		require.NoError(t, err)
+
+		assert.Equal(t, "buffers", cloned.Name)
+		assert.Equal(t, "fresh", cloned.Pool.Get(), "cloned pool should keep New and drop cached items")
+		require.Len(t, cloned.Pools, 1)
+		assert.Nil(t, cloned.Pools[0].Get(), "cloned pool should start empty")
+		cloned.Pool.Put("reused")
+	})
+
+	t.Run("unexported pools are rejected", func(t *testing.T) {
+		t.Parallel()
+		type pooled struct {
+			pool sync.Pool
+		}
+
+		_, err := Clone(&pooled{})
+
+		var unsupported *UnsupportedError
+		require.ErrorAs(t, err, &unsupported)
+		assert.Equal(t, "$.pool", unsupported.Path)
+	})
+
+	t.Run("zero rejects unexported fields", func(t *testing.T) {
+		t.Parallel()
+		type hidden struct {
+			secret string `clone:"zero"`
+		}
+
+		_, err := Clone(hidden{secret: "x"})
+
+		var unsupported *UnsupportedError
+		require.ErrorAs(t, err, &unsupported)
+		assert.Equal(t, "$.secret", unsupported.Path)
+		assert.Equal(t, "unexported fields cannot be zeroed", unsupported.Reason)
+	})
+
+	t.Run("unknown tags are ignored", func(t *testing.T) {
+		t.Parallel()
+		type tagged struct {
+			Unknown []int `clone:"deep"`
+			Other   []int `json:"other,shallow"`
+		}
+
+		original := tagged{Unknown: []int{2}, Other: []int{3}}
+		cloned := MustClone(original)
+
+		assert.Equal(t, original, cloned)
+		assert.NotSame(t, &original.Unknown[0], &cloned.Unknown[0])
+		assert.NotSame(t, &original.Other[0], &cloned.Other[0])
+	})
+}
+
+// guardedStore is the common pattern of a mutex embedded next to the data it
+// guards; the mutex is tagged so the clone starts unlocked.
+type guardedStore struct {
+	sync.RWMutex `clone:"zero"`
+
+	Data map[string]int
+}
+
+func TestCloneGuardedStore(t *testing.T) {
+	t.Parallel()
+
+	t.Run("struct info resets the mutex and clones the map", func(t *testing.T) {
+		t.Parallel()
+		info := structInfo(reflect.TypeFor[guardedStore]())
+
+		require.Len(t, info.walked, 2)
+		assert.Equal(t, "RWMutex", info.walked[0].name)
+		assert.Equal(t, zeroField, info.walked[0].action)
+		assert.Equal(t, "Data", info.walked[1].name)
+		assert.Equal(t, cloneField, info.walked[1].action)
+	})
+
+	t.Run("locked store", func(t *testing.T) {
+		t.Parallel()
+		original := &guardedStore{Data: map[string]int{"a": 1, "b": 2}}
+		original.Lock()
+		defer original.Unlock()
+
+		cloned := MustClone(original)
+
+		assert.NotSame(t, original, cloned)
+		assert.Equal(t, map[string]int{"a": 1, "b": 2}, cloned.Data)
+		require.True(t, cloned.TryLock(), "cloned mutex should start unlocked")
+		cloned.Data["c"] = 3
+		cloned.Unlock()
+		assert.NotContains(t, original.Data, "c")
+	})
+
+	t.Run("read-locked store by value", func(t *testing.T) {
+		t.Parallel()
+		original := &guardedStore{Data: map[string]int{"a": 1}}
+		original.RLock()
+		defer original.RUnlock()
+
+		cloned := MustClone(map[string]*guardedStore{"primary": original, "replica": original})
+
+		assert.Same(t, cloned["primary"], cloned["replica"])
+		require.True(t, cloned["primary"].TryLock(), "cloned mutex should start unlocked")
+		cloned["primary"].Unlock()
+	})
+
+	t.Run("unexported fields are rejected", func(t *testing.T) {
+		t.Parallel()
+		// Reflection cannot reset or replace unexported fields, so such a
+		// store needs a Clone method.
+		type store struct {
+			mu   sync.RWMutex
+			data map[string]int
+		}
+
+		_, err := Clone(&store{data: map[string]int{"a": 1}})
+
+		var unsupported *UnsupportedError
+		require.ErrorAs(t, err, &unsupported)
+		assert.Equal(t, "$.mu", unsupported.Path)
+		assert.Equal(t, "sync primitives cannot be cloned", unsupported.Reason)
+	})
+}
@@ -0,0 +1,60 @@
+package deepclone
+
+import (
+	"reflect"
+	"time"
+)
+
+// Stats summarizes the work done by one clone operation.
+type Stats struct {
+	// Pointers counts cloned pointer targets.
+	Pointers int
+	// Slices counts cloned slices.
+	Slices int
+	// Maps counts cloned maps.
+	Maps int
+	// Reused counts references resolved to a value that was already cloned,
+	// from shared references and cycles.
+	Reused int
+}
+
+// WithBeforeClone calls hook once before the clone starts with the static type
+// of the value being cloned. Together with WithCloneDone it lets callers open
+// and close a tracing span around each clone. A nil hook is ignored.
+func WithBeforeClone(hook func(t reflect.Type)) Option {
+	return func(o *options) {
+		o.beforeClone = hook
+	}
+}
+
+// WithCloneDone calls hook once after the clone finishes, successfully or not,
+// with the static type of the value, the elapsed time, and the Stats of the
+// clone. Collecting stats bypasses the typed fast paths, so the reported work
+// matches what the reflection engine did. A nil hook is ignored.
+func WithCloneDone(hook func(t reflect.Type, elapsed time.Duration, stats Stats)) Option {
+	return func(o *options) {
+		o.cloneDone = hook
+	}
+}
+
+// cloneTraced runs cloneWith between the before and done hooks of opts.
+func cloneTraced[T any](src T, opts options) (T, error) {
+	typ := reflect.TypeFor[T]()
+	before, done := opts.beforeClone, opts.cloneDone
+	opts.beforeClone, opts.cloneDone = nil, nil
+
+	var stats Stats
+	if done != nil {
+		opts.stats = &stats
+	}
+	if before != nil {
+		before(typ)
+	}
+
+	start := time.Now()
+	cloned, err := cloneWith(src, opts)
+	if done != nil {
+		done(typ, time.Since(start), stats)
+	}
+	return cloned, err
+}
@@ -0,0 +1,78 @@
+package deepclone
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCloneTraceHooks(t *testing.T) {
+	t.Parallel()
+
+	t.Run("fire once around the clone", func(t *testing.T) {
+		t.Parallel()
+		original := newDiamond()
+		original.Left.Next.Next = original
+
+		var events []string
+		var gotType reflect.Type
+		var gotElapsed time.Duration
+		var gotStats Stats
+		start := time.Now()
+
+		cloned, err := CloneWith(original,
+			WithBeforeClone(func(typ reflect.Type) {
+				events = append(events, "before")
+				gotType = typ
+			}),
+			WithCloneDone(func(typ reflect.Type, elapsed time.Duration, stats Stats) {
+				events = append(events, "done")
+				assert.Equal(t, gotType, typ)
+				gotElapsed, gotStats = elapsed, stats
+			}),
+		)
+
+		require.NoError(t, err)
+		assert.Equal(t, original, cloned)
+		assert.Equal(t, []string{"before", "done"}, events)
+		assert.Equal(t, reflect.TypeFor[*diamondNode](), gotType)
+		assert.Positive(t, gotElapsed)
+		assert.LessOrEqual(t, gotElapsed, time.Since(start))
+		assert.Equal(t, Stats{Pointers: 4, Reused: 2}, gotStats, "four nodes, one shared bottom and one back edge")
+	})
+
+	t.Run("collections bypass fast paths", func(t *testing.T) {
+		t.Parallel()
+		var got Stats
+
+		_, err := CloneWith(map[string][]int{"a": {1}, "b": {2}}, WithCloneDone(func(_ reflect.Type, _ time.Duration, stats Stats) {
+			got = stats
+		}))
+
+		require.NoError(t, err)
+		assert.Equal(t, Stats{Slices: 2, Maps: 1}, got)
+	})
+
+	t.Run("done fires on error", func(t *testing.T) {
+		t.Parallel()
+		var called bool
+
+		_, err := CloneWith(struct{ Ch chan int }{Ch: make(chan int)}, WithCloneDone(func(reflect.Type, time.Duration, Stats) {
+			called = true
+		}))
+
+		require.Error(t, err)
+		assert.True(t, called)
+	})
+
+	t.Run("nil hooks", func(t *testing.T) {
+		t.Parallel()
+		cloned, err := CloneWith([]int{1}, WithBeforeClone(nil), WithCloneDone(nil))
+
+		require.NoError(t, err)
+		assert.Equal(t, []int{1}, cloned)
+	})
+}